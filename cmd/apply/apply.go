@@ -0,0 +1,116 @@
+package apply
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/internal/state"
+	"github.com/vinisman/bbctl/utils"
+)
+
+func NewApplyCmd() *cobra.Command {
+	var (
+		file         string
+		planFile     string
+		output       string
+		userPassword string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Apply a desired-state file to Bitbucket",
+		Long: `Reconcile Bitbucket with a single desired-state YAML/JSON file holding
+projects, repositories (with their settings), users and groups.
+
+Live state is fetched for everything declared in the file, a plan is computed
+and then applied in dependency order:
+  users -> groups -> projects -> repositories -> webhooks -> required builds
+  -> branch permissions -> reviewer groups -> workzone
+
+Rules:
+ - Projects, repositories, users and groups are created or updated, never deleted.
+ - Fields not set in the file are left untouched.
+ - A repository setting kind is managed only if its key is present: a repository
+   without "webhooks" keeps its webhooks, while "webhooks: []" removes all of them.
+ - Settings without ids are matched to live items by natural key (webhook name,
+   reviewer group name, restriction type + branch, required build branch), so
+   exported files and hand-written files both work.
+
+Saved plans:
+ - "bbctl plan --out FILE" saves a plan; "bbctl apply --plan FILE" executes exactly
+   that plan. The live state is fetched again first and apply refuses to run if it
+   no longer matches the fingerprint stored in the plan file.
+
+Example file content:
+  projects:
+    - key: PRJ
+      name: Project
+  repositories:
+    - projectKey: PRJ
+      repositorySlug: repo1
+      webhooks:
+        - name: ci
+          url: https://ci.example.com/hook
+          events: [repo:refs_changed]
+  groups:
+    - name: developers`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (file == "") == (planFile == "") {
+				return fmt.Errorf("either --file or --plan must be specified, but not both")
+			}
+			if output != "yaml" && output != "json" {
+				return fmt.Errorf("invalid output format: %s, allowed values: yaml, json", output)
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			var plan *models.StatePlan
+			if planFile != "" {
+				pf, err := utils.ReadPlanFile(planFile)
+				if err != nil {
+					return fmt.Errorf("failed to read plan file: %w", err)
+				}
+				_, fingerprint, err := state.BuildPlan(cmd.Context(), client, pf.Desired)
+				if err != nil {
+					return fmt.Errorf("failed to fetch live state: %w", err)
+				}
+				if fingerprint != pf.Fingerprint {
+					return fmt.Errorf("live state changed since the plan was created at %s, run 'bbctl plan' again", pf.CreatedAt)
+				}
+				plan = &pf.Plan
+			} else {
+				var desired models.StateYaml
+				if err := utils.ParseFile(file, &desired); err != nil {
+					return fmt.Errorf("failed to parse file %s: %w", file, err)
+				}
+				plan, _, err = state.BuildPlan(cmd.Context(), client, desired)
+				if err != nil {
+					return fmt.Errorf("failed to build plan: %w", err)
+				}
+			}
+
+			if state.CountChanges(plan) == 0 {
+				client.Logger.Info("No changes, live state matches the desired state")
+				return nil
+			}
+
+			if err := state.Apply(cmd.Context(), client, plan, userPassword); err != nil {
+				return err
+			}
+
+			return utils.PrintStructured("apply", plan, output, "")
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", `Path to desired-state YAML or JSON file, or "-" to read from stdin`)
+	cmd.Flags().StringVar(&planFile, "plan", "", "Execute a plan file saved by 'bbctl plan --out' instead of computing a new plan")
+	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Output format for the applied plan: yaml or json")
+	cmd.Flags().StringVar(&userPassword, "user-password", "", "Initial password for users that have to be created")
+
+	return cmd
+}
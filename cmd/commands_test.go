@@ -0,0 +1,266 @@
+package cmd_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/vinisman/bbctl/cmd"
+	"github.com/vinisman/bbctl/internal/fakebitbucket"
+	"github.com/vinisman/bbctl/internal/models"
+)
+
+// newFake starts a fake Bitbucket server and points the commands at it
+func newFake(t *testing.T) *fakebitbucket.Server {
+	t.Helper()
+	fake := fakebitbucket.New()
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	t.Setenv("BBCTL_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	t.Setenv("BBCTL_CONTEXT", "")
+	t.Setenv("BITBUCKET_BASE_URL", srv.URL)
+	t.Setenv("BITBUCKET_TOKEN", "fake")
+	t.Setenv("BITBUCKET_PROTECTED", "")
+	return fake
+}
+
+// run executes bbctl with args and returns what it printed to stdout
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	root := cmd.NewRootCmd()
+	root.SetArgs(args)
+	root.SetOut(io.Discard)
+	root.SetErr(io.Discard)
+	err = root.ExecuteContext(context.Background())
+
+	w.Close()
+	os.Stdout = stdout
+	return <-out, err
+}
+
+// mustRun is run for commands that have to succeed
+func mustRun(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := run(t, args...)
+	if err != nil {
+		t.Fatalf("bbctl %s: %v", strings.Join(args, " "), err)
+	}
+	return out
+}
+
+// writeFile writes content to a file in a temporary directory and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func decode(t *testing.T, out string, v any) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(out), v); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", out, err)
+	}
+}
+
+// newRepo creates the project PRJ with the repository "Repo One"
+func newRepo(t *testing.T) {
+	t.Helper()
+	mustRun(t, "project", "create", "-k", "PRJ", "--name", "Project")
+	mustRun(t, "repo", "create", "-k", "PRJ", "--name", "Repo One")
+}
+
+func TestRepoGet(t *testing.T) {
+	newFake(t)
+	newRepo(t)
+
+	var got models.RepositoryYaml
+	decode(t, mustRun(t, "repo", "get", "-k", "PRJ", "-o", "json", "--show-details", "repository,defaultBranch"), &got)
+	if len(got.Repositories) != 1 {
+		t.Fatalf("got %d repositories, want 1", len(got.Repositories))
+	}
+	repo := got.Repositories[0]
+	if repo.RepositorySlug != "repo-one" || repo.DefaultBranch != "main" {
+		t.Errorf("repository = %s with default branch %q, want repo-one with main", repo.RepositorySlug, repo.DefaultBranch)
+	}
+
+	out := mustRun(t, "repo", "get", "-s", "PRJ/repo-one", "-o", "csv", "--columns", "slug,name")
+	if want := "slug,name\nrepo-one,Repo One\n"; out != want {
+		t.Errorf("csv output = %q, want %q", out, want)
+	}
+
+	if _, err := run(t, "repo", "get", "-s", "PRJ/missing", "-o", "json"); err == nil {
+		t.Error("getting a missing repository succeeded")
+	}
+}
+
+func TestWebhookDiffApply(t *testing.T) {
+	newFake(t)
+	newRepo(t)
+
+	current := writeFile(t, "current.json", mustRun(t, "repo", "webhook", "get", "-s", "PRJ/repo-one", "-o", "json"))
+	target := writeFile(t, "target.yaml", `repositories:
+  - projectKey: PRJ
+    repositorySlug: repo-one
+    webhooks:
+      - name: ci
+        url: https://ci.example.com/hook
+        events: [repo:refs_changed]
+`)
+
+	var diff struct {
+		Diff models.RepoDiff `json:"diff"`
+	}
+	decode(t, mustRun(t, "repo", "webhook", "diff", "-s", current, "-t", target), &diff)
+	if len(diff.Diff.Create) != 1 || len(diff.Diff.Update) != 0 || len(diff.Diff.Delete) != 0 {
+		t.Fatalf("diff = %+v, want one repository to create webhooks in", diff.Diff)
+	}
+
+	mustRun(t, "repo", "webhook", "diff", "-s", current, "-t", target, "--apply")
+
+	var got models.RepositoryYaml
+	live := mustRun(t, "repo", "webhook", "get", "-s", "PRJ/repo-one", "-o", "json")
+	decode(t, live, &got)
+	if len(got.Repositories) != 1 || got.Repositories[0].Webhooks == nil || len(*got.Repositories[0].Webhooks) != 1 {
+		t.Fatalf("webhooks after apply = %s", live)
+	}
+	hook := (*got.Repositories[0].Webhooks)[0]
+	if hook.Id == nil || hook.GetName() != "ci" || hook.GetUrl() != "https://ci.example.com/hook" {
+		t.Errorf("webhook = %+v, want ci with an id", hook)
+	}
+
+	// the live state is the target now, nothing is left to change
+	current = writeFile(t, "current.json", live)
+	decode(t, mustRun(t, "repo", "webhook", "diff", "-s", current, "-t", current), &diff)
+	if len(diff.Diff.Create)+len(diff.Diff.Update)+len(diff.Diff.Delete) != 0 {
+		t.Errorf("diff after apply = %+v, want no changes", diff.Diff)
+	}
+}
+
+func TestUserSync(t *testing.T) {
+	fake := newFake(t)
+	for _, name := range []string{"alice", "bob", "carol"} {
+		fake.AddUser(name, strings.ToUpper(name), name+"@example.com")
+	}
+	input := writeFile(t, "users.yaml", `users:
+  - name: alice
+    displayName: Alice
+    emailAddress: alice@example.com
+  - name: dave
+    displayName: Dave
+    emailAddress: dave@example.com
+`)
+	// a small page size makes the sync read the live users from several pages
+	sync := []string{"--page-size", "2", "user", "sync", "-i", input, "--user-password", "secret", "-o", "json"}
+
+	t.Setenv("BITBUCKET_PROTECTED", "user:bob")
+	if _, err := run(t, append(sync, "--prune")...); err == nil || !strings.Contains(err.Error(), "protected") {
+		t.Fatalf("sync --prune of a protected user: err = %v, want refusal", err)
+	}
+	t.Setenv("BITBUCKET_PROTECTED", "")
+
+	var changes struct {
+		Changes []struct {
+			Action string `json:"action"`
+			Name   string `json:"name"`
+			Result string `json:"result"`
+		} `json:"changes"`
+	}
+	decode(t, mustRun(t, append(sync, "--prune", "--yes")...), &changes)
+	var got []string
+	for _, c := range changes.Changes {
+		if c.Result != "done" {
+			t.Errorf("%s %s: %s", c.Action, c.Name, c.Result)
+		}
+		got = append(got, c.Action+" "+c.Name)
+	}
+	sort.Strings(got)
+	want := []string{"create dave", "delete bob", "delete carol", "update alice"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+
+	if out := mustRun(t, append(sync, "--prune")...); out != "" {
+		t.Errorf("second sync printed changes %q, want none", out)
+	}
+}
+
+func TestProjectDiffIgnoresUnsetFields(t *testing.T) {
+	newFake(t)
+	source := writeFile(t, "source.yaml", `projects:
+  - key: PRJ
+    name: Project
+    description: Some text
+    public: true
+`)
+	target := writeFile(t, "target.yaml", `projects:
+  - key: PRJ
+    name: Project
+  - key: NEW
+    name: New
+`)
+
+	var diff struct {
+		Diff models.ProjectDiff `json:"diff"`
+	}
+	decode(t, mustRun(t, "project", "diff", "-s", source, "-t", target, "-o", "json"), &diff)
+	if len(diff.Diff.Create) != 1 || len(diff.Diff.Update) != 0 || len(diff.Diff.Delete) != 0 {
+		t.Errorf("diff = %+v, want only NEW to create", diff.Diff)
+	}
+
+	target = writeFile(t, "target.yaml", `projects:
+  - key: PRJ
+    name: Project
+    public: false
+`)
+	decode(t, mustRun(t, "project", "diff", "-s", source, "-t", target, "-o", "json"), &diff)
+	if len(diff.Diff.Update) != 1 {
+		t.Errorf("diff = %+v, want PRJ to update", diff.Diff)
+	}
+}
+
+func TestGroupMembersSetConfirmsRemovals(t *testing.T) {
+	fake := newFake(t)
+	for _, name := range []string{"alice", "bob", "carol"} {
+		fake.AddUser(name, name, name+"@example.com")
+	}
+	mustRun(t, "group", "create", "--name", "devs")
+	mustRun(t, "group", "members", "add", "--name", "devs", "--users", "alice,bob,carol")
+
+	t.Setenv("BITBUCKET_PROTECTED", "user:bob")
+	if _, err := run(t, "--yes", "group", "members", "set", "--name", "devs", "--users", "alice"); err == nil || !strings.Contains(err.Error(), "protected") {
+		t.Fatalf("set removing a protected user: err = %v, want refusal", err)
+	}
+	t.Setenv("BITBUCKET_PROTECTED", "")
+	if _, err := run(t, "--yes", "--max-deletes", "1", "group", "members", "set", "--name", "devs", "--users", "alice"); err == nil || !strings.Contains(err.Error(), "max-deletes") {
+		t.Fatalf("set removing 2 members with --max-deletes 1: err = %v, want refusal", err)
+	}
+
+	mustRun(t, "--yes", "group", "members", "set", "--name", "devs", "--users", "alice")
+	var got models.GroupYaml
+	decode(t, mustRun(t, "group", "members", "get", "--name", "devs", "-o", "json"), &got)
+	if len(got.Groups) != 1 || strings.Join(got.Groups[0].Members, ",") != "alice" {
+		t.Errorf("members after set = %+v, want alice only", got.Groups)
+	}
+}
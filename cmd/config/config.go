@@ -0,0 +1,57 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+	bbconfig "github.com/vinisman/bbctl/internal/config"
+)
+
+func NewConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage connection contexts",
+		Long: `Manage named connection contexts stored in ~/.config/bbctl/config.yaml
+($XDG_CONFIG_HOME/bbctl/config.yaml if set, or the file in BBCTL_CONFIG).
+
+A context holds the base URL, auth method, page size, max workers, insecure and
+CA file settings of a Bitbucket instance. Credentials are not stored, they still
+come from BITBUCKET_TOKEN, BITBUCKET_PASSWORD or the global flags.
+
+The context is selected with --context, then BBCTL_CONTEXT, then the current
+context of the file. Environment variables and global flags override its values.
+
+Example file:
+  currentContext: prod
+  contexts:
+    - name: prod
+      baseUrl: https://bitbucket.example.com
+      auth: token
+      pageSize: 100
+      maxWorkers: 10
+    - name: staging
+      baseUrl: https://bitbucket-staging.example.com
+      auth: basic
+      username: admin
+      caFile: /etc/ssl/certs/internal-ca.pem`,
+	}
+
+	cmd.AddCommand(
+		NewGetContextsCmd(),
+		NewUseContextCmd(),
+		NewSetContextCmd(),
+	)
+
+	return cmd
+}
+
+// loadFile reads the config file and returns it with its path
+func loadFile() (*bbconfig.File, string, error) {
+	path, err := bbconfig.ConfigFilePath()
+	if err != nil {
+		return nil, "", err
+	}
+	f, err := bbconfig.LoadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return f, path, nil
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	bbconfig "github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/utils"
+)
+
+// contextRow is one line of get-contexts output
+type contextRow struct {
+	Current string `json:"current" yaml:"current"`
+	bbconfig.Context
+}
+
+func NewGetContextsCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "get-contexts",
+		Short: "List contexts from the config file",
+		Long:  `List contexts from the config file. The current context is marked with "*".`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, path, err := loadFile()
+			if err != nil {
+				return err
+			}
+			if len(f.Contexts) == 0 {
+				fmt.Fprintf(os.Stderr, "No contexts found in %s\n", path)
+				return nil
+			}
+
+			if output != "plain" {
+				return utils.PrintStructured("config", f, output, "")
+			}
+			rows := make([]contextRow, len(f.Contexts))
+			for i, c := range f.Contexts {
+				rows[i] = contextRow{Context: c}
+				if c.Name == f.CurrentContext {
+					rows[i].Current = "*"
+				}
+			}
+			return utils.PrintStructured("contexts", rows, output, "current,name,baseUrl,auth,username,pageSize,maxWorkers,insecure,caFile")
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "plain", "Output format: plain|yaml|json")
+
+	return cmd
+}
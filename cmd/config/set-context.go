@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	bbconfig "github.com/vinisman/bbctl/internal/config"
+)
+
+func NewSetContextCmd() *cobra.Command {
+	var (
+		auth      string
+		use       bool
+		protected string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set-context NAME",
+		Short: "Create or update a context",
+		Long: `Create a context or update the given settings of an existing one.
+Settings are taken from the global flags --url, --username, --page-size,
+--max-workers, --insecure, --ca-file, --client-cert, --client-key, --proxy,
+--no-proxy, --retries, --retry-max-wait, --rate-limit, --rate-burst, --token-file
+and --credential-process, and from --auth and --protected.
+Settings that are not given keep their current value.
+
+Examples:
+  bbctl config set-context prod --url https://bitbucket.example.com --auth token --page-size 100
+  bbctl config set-context staging --url https://bitbucket-staging.example.com --insecure --use
+  bbctl config set-context dr --credential-process "vault kv get -field=token secret/bitbucket-dr"
+  bbctl config set-context prod --protected "CORE,INFRA/terraform-*,group:admins"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := bbconfig.ValidateAuth(auth); err != nil {
+				return err
+			}
+
+			f, path, err := loadFile()
+			if err != nil {
+				return err
+			}
+
+			ctx := bbconfig.Context{Name: args[0]}
+			if existing, ok := f.GetContext(args[0]); ok {
+				ctx = *existing
+			}
+
+			flags := cmd.Flags()
+			if flags.Changed("url") {
+				ctx.BaseURL, _ = flags.GetString("url")
+			}
+			if flags.Changed("username") {
+				ctx.Username, _ = flags.GetString("username")
+			}
+			if flags.Changed("page-size") {
+				ctx.PageSize, _ = flags.GetInt("page-size")
+			}
+			if flags.Changed("max-workers") {
+				ctx.MaxWorkers, _ = flags.GetInt("max-workers")
+			}
+			if flags.Changed("insecure") {
+				ctx.Insecure, _ = flags.GetBool("insecure")
+			}
+			if flags.Changed("retries") {
+				retries, _ := flags.GetInt("retries")
+				ctx.Retries = &retries
+			}
+			if flags.Changed("retry-max-wait") {
+				wait, _ := flags.GetDuration("retry-max-wait")
+				ctx.RetryMaxWait = wait.String()
+			}
+			if flags.Changed("rate-limit") {
+				rate, _ := flags.GetString("rate-limit")
+				if _, err := bbconfig.ParseRateLimit(rate); err != nil {
+					return err
+				}
+				ctx.RateLimit = rate
+			}
+			if flags.Changed("rate-burst") {
+				ctx.RateBurst, _ = flags.GetInt("rate-burst")
+			}
+			if flags.Changed("token-file") {
+				ctx.TokenFile, _ = flags.GetString("token-file")
+			}
+			if flags.Changed("credential-process") {
+				ctx.CredentialProcess, _ = flags.GetString("credential-process")
+			}
+			if flags.Changed("auth") {
+				ctx.Auth = auth
+			}
+			if flags.Changed("protected") {
+				ctx.Protected = bbconfig.SplitList(protected)
+			}
+			for flag, field := range map[string]*string{
+				"ca-file":     &ctx.CAFile,
+				"client-cert": &ctx.ClientCert,
+				"client-key":  &ctx.ClientKey,
+				"proxy":       &ctx.Proxy,
+				"no-proxy":    &ctx.NoProxy,
+			} {
+				if flags.Changed(flag) {
+					*field, _ = flags.GetString(flag)
+				}
+			}
+
+			f.SetContext(ctx)
+			if use || f.CurrentContext == "" {
+				f.CurrentContext = ctx.Name
+			}
+			if err := f.Save(path); err != nil {
+				return err
+			}
+			fmt.Printf("Context %q saved to %s\n", ctx.Name, path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&auth, "auth", "", "Auth method: token|basic (empty detects it from the given credentials)")
+	cmd.Flags().BoolVar(&use, "use", false, "Also make it the current context")
+	cmd.Flags().StringVar(&protected, "protected", "", `Comma-separated resources that delete commands refuse to delete without --force-protected,
+replacing the current list ("" clears it): project keys (also protect their repositories),
+<projectKey>/<slug> repositories, user:<name> and group:<name>; * and ? are wildcards`)
+
+	return cmd
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func NewUseContextCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "use-context NAME",
+		Short: "Set the current context",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, path, err := loadFile()
+			if err != nil {
+				return err
+			}
+			if _, ok := f.GetContext(args[0]); !ok {
+				return fmt.Errorf("context %q not found in %s", args[0], path)
+			}
+			f.CurrentContext = args[0]
+			if err := f.Save(path); err != nil {
+				return err
+			}
+			fmt.Printf("Switched to context %q\n", args[0])
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,16 @@
+package dev
+
+import "github.com/spf13/cobra"
+
+func NewDevCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Tools for developing and testing with bbctl",
+	}
+
+	cmd.AddCommand(
+		NewFakeServerCmd(),
+	)
+
+	return cmd
+}
@@ -0,0 +1,69 @@
+package dev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/fakebitbucket"
+)
+
+func NewFakeServerCmd() *cobra.Command {
+	var (
+		host string
+		port int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "fake-server",
+		Short: "Run an in-memory Bitbucket server for tests and offline development",
+		Long: `Serve the REST endpoints used by bbctl from memory until interrupted: projects,
+repositories, raw files, webhooks, required builds, branch permissions, reviewer
+groups, permissions, users, groups and Workzone settings. The server starts empty
+and accepts any credentials; state is lost when it stops.
+
+Examples:
+  bbctl dev fake-server --port 7990 &
+  export BITBUCKET_BASE_URL=http://127.0.0.1:7990 BITBUCKET_TOKEN=fake
+  bbctl project create --input projects.yaml
+  bbctl apply -f state.yaml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if port < 0 || port > 65535 {
+				return fmt.Errorf("invalid port: %d", port)
+			}
+
+			fake := fakebitbucket.New()
+			fake.Logger = config.GlobalLogger
+			srv := &http.Server{Handler: fake, ReadHeaderTimeout: 10 * time.Second}
+
+			ln, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+			if err != nil {
+				return err
+			}
+			config.GlobalLogger.Info("Fake Bitbucket server listening", "url", "http://"+ln.Addr().String())
+
+			go func() {
+				<-cmd.Context().Done()
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = srv.Shutdown(ctx)
+			}()
+			if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			config.GlobalLogger.Info("Fake Bitbucket server stopped")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&host, "host", "127.0.0.1", "Address to listen on")
+	cmd.Flags().IntVar(&port, "port", 7990, "Port to listen on, 0 for a random free port")
+
+	return cmd
+}
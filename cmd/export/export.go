@@ -0,0 +1,60 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/state"
+)
+
+func NewExportCmd() *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the whole instance to a directory tree",
+		Long: `Write a snapshot of all projects, repositories, users and groups to a directory:
+
+  projects/KEY.yaml      project
+  repos/KEY/slug.yaml    repository metadata, default branch, webhooks, required builds,
+                         branch permissions, reviewer groups and Workzone sections
+  users.yaml             users
+  groups.yaml            groups with their members
+
+Files use the input formats of the other commands, e.g. a repository file can be
+passed to "bbctl restore --from" or "bbctl apply -f". Objects and their settings are
+sorted and webhook statistics are left out, so exporting an unchanged instance again
+produces identical files and snapshots can be committed to git and diffed.
+Files of projects and repositories that no longer exist are removed from the directory.
+
+Examples:
+  bbctl export --dir ./snapshot
+  bbctl --context prod export --dir ./snapshots/prod && git -C ./snapshots diff --stat`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dir == "" {
+				return fmt.Errorf("--dir must be specified")
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			summary, err := state.Export(cmd.Context(), client, dir)
+			if err != nil {
+				return err
+			}
+			client.Logger.Info("Export completed", "dir", dir,
+				"projects", summary.Projects,
+				"repositories", summary.Repositories,
+				"users", summary.Users,
+				"groups", summary.Groups)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", "", "Directory to write the snapshot to, created if missing")
+
+	return cmd
+}
@@ -1,7 +1,6 @@
 package group
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -31,7 +30,7 @@ This is required for older Bitbucket versions that do not support token-based op
 				return fmt.Errorf("either --name or --input must be specified, but not both")
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -69,7 +68,7 @@ This is required for older Bitbucket versions that do not support token-based op
 				}
 			}
 
-			createdGroups, err := client.CreateGroups(groups)
+			createdGroups, err := client.CreateGroups(cmd.Context(), groups)
 			if err != nil {
 				client.Logger.Error(err.Error())
 				return nil
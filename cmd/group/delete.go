@@ -1,12 +1,12 @@
 package group
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/config"
 	"github.com/vinisman/bbctl/internal/models"
 	"github.com/vinisman/bbctl/utils"
 )
@@ -31,7 +31,7 @@ This is required for older Bitbucket versions that do not support token-based op
 				return fmt.Errorf("either --name or --input must be specified, but not both")
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -69,8 +69,12 @@ This is required for older Bitbucket versions that do not support token-based op
 				}
 			}
 
+			if err := utils.ConfirmDelete(config.KindGroup, groupNames); err != nil {
+				return err
+			}
+
 			// Delete groups
-			deletedGroups, err := client.DeleteGroups(groupNames)
+			deletedGroups, err := client.DeleteGroups(cmd.Context(), groupNames)
 			if err != nil {
 				client.Logger.Error(err.Error())
 				return nil
@@ -1,7 +1,6 @@
 package group
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -13,10 +12,12 @@ import (
 
 func GetCmd() *cobra.Command {
 	var (
-		name   string
-		all    bool
-		output string
-		input  string
+		name    string
+		all     bool
+		output  string
+		input   string
+		members bool
+		columns string
 	)
 
 	cmd := &cobra.Command{
@@ -43,7 +44,7 @@ You must specify exactly one of the following options:
 				return fmt.Errorf("please specify exactly one of -n/--name, --all, or --input")
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -52,13 +53,13 @@ You must specify exactly one of the following options:
 
 			switch {
 			case all:
-				groups, err = client.GetAllGroups()
+				groups, err = client.GetAllGroups(cmd.Context())
 				if err != nil {
 					client.Logger.Error(err.Error())
 					return nil
 				}
 			case name != "":
-				groups, err = client.GetGroups(utils.ParseColumnsToLower(name))
+				groups, err = client.GetGroups(cmd.Context(), utils.ParseColumnsToLower(name))
 				if err != nil {
 					client.Logger.Error(err.Error())
 					return nil
@@ -84,14 +85,35 @@ You must specify exactly one of the following options:
 					return fmt.Errorf("no group names found in file %s", input)
 				}
 
-				groups, err = client.GetGroups(groupNames)
+				groups, err = client.GetGroups(cmd.Context(), groupNames)
 				if err != nil {
 					client.Logger.Error(err.Error())
 					return nil
 				}
 			}
 
-			if err := utils.PrintStructured("groups", groups, output, "name"); err != nil {
+			if members {
+				names := make([]string, 0, len(groups))
+				for _, g := range groups {
+					names = append(names, utils.SafeValue(g.Name))
+				}
+				withMembers, err := client.GetGroupMembers(cmd.Context(), names)
+				if err != nil {
+					client.Logger.Error(err.Error())
+				}
+				if columns == "" {
+					columns = "name,members"
+				}
+				if err := utils.PrintStructured("groups", withMembers, output, columns); err != nil {
+					return fmt.Errorf("failed to print output: %w", err)
+				}
+				return nil
+			}
+
+			if columns == "" {
+				columns = "name"
+			}
+			if err := utils.PrintStructured("groups", groups, output, columns); err != nil {
 				return fmt.Errorf("failed to print output: %w", err)
 			}
 			return nil
@@ -100,14 +122,16 @@ You must specify exactly one of the following options:
 
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Comma-separated group names")
 	cmd.Flags().BoolVar(&all, "all", false, "Fetch all groups")
+	cmd.Flags().BoolVar(&members, "members", false, `Include group members; the yaml/json output can be used as input for "group members set"`)
 	cmd.Flags().StringVarP(
 		&output,
 		"output",
 		"o",
 		"plain",
-		`Output format: plain|yaml|json.
+		`Output format: plain|yaml|json|csv|tsv|go-template=...|go-template-file=...|jsonpath=...
 The "yaml" and "json" formats print the full available structure with all fields.`,
 	)
+	cmd.Flags().StringVar(&columns, "columns", "", "Comma-separated fields to display for plain, csv and tsv output (default name, and members with --members)")
 	cmd.Flags().StringVarP(&input, "input", "i", "", `Path to YAML or JSON file with groups to get, or "-" to read from stdin.
 Example file content:
   groups:
@@ -2,6 +2,7 @@ package group
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/cmd/group/members"
 )
 
 func GroupCmd() *cobra.Command {
@@ -15,6 +16,8 @@ func GroupCmd() *cobra.Command {
 		GetCmd(),
 		CreateCmd(),
 		DeleteCmd(),
+		SyncCmd(),
+		members.MembersCmd(),
 	)
 
 	return cmd
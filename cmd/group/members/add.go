@@ -0,0 +1,53 @@
+package members
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/utils"
+)
+
+func AddCmd() *cobra.Command {
+	var (
+		name   string
+		users  string
+		input  string
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add users to groups",
+		Long: `Add users to one or more groups either from CLI flags or from a YAML or JSON file.
+Users that are already members are left as they are.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if name != "" && users == "" {
+				return fmt.Errorf("--users is required when using --name")
+			}
+			groups, err := parseGroups(name, users, input)
+			if err != nil {
+				return err
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			added, err := client.AddGroupMembers(cmd.Context(), groups)
+			if err != nil {
+				client.Logger.Error(err.Error())
+			}
+
+			return utils.PrintStructured("groups", added, output, "name,members")
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Comma-separated group names")
+	cmd.Flags().StringVar(&users, "users", "", "Comma-separated usernames to add")
+	cmd.Flags().StringVarP(&input, "input", "i", "", inputHelp)
+	cmd.Flags().StringVarP(&output, "output", "o", "plain", "Output format: plain|yaml|json")
+
+	return cmd
+}
@@ -0,0 +1,79 @@
+package members
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/utils"
+)
+
+func GetCmd() *cobra.Command {
+	var (
+		name   string
+		all    bool
+		input  string
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Get members of groups",
+		Long: `Get members of one or more groups.
+You must specify exactly one of the following options:
+  -n/--name (comma-separated group names),
+  --all (all groups),
+  --input (YAML or JSON file with a list of groups, or '-' to read from stdin).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			count := 0
+			if name != "" {
+				count++
+			}
+			if all {
+				count++
+			}
+			if input != "" {
+				count++
+			}
+			if count != 1 {
+				return fmt.Errorf("please specify exactly one of -n/--name, --all, or --input")
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			var names []string
+			if all {
+				groups, err := client.GetAllGroups(cmd.Context())
+				if err != nil {
+					return fmt.Errorf("failed to fetch groups: %w", err)
+				}
+				for _, g := range groups {
+					names = append(names, utils.SafeValue(g.Name))
+				}
+			} else {
+				groups, err := parseGroups(name, "", input)
+				if err != nil {
+					return err
+				}
+				names = groupNames(groups)
+			}
+
+			groups, err := client.GetGroupMembers(cmd.Context(), names)
+			if err != nil {
+				client.Logger.Error(err.Error())
+			}
+
+			return utils.PrintStructured("groups", groups, output, "name,members")
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Comma-separated group names")
+	cmd.Flags().BoolVar(&all, "all", false, "Get members of all groups")
+	cmd.Flags().StringVarP(&input, "input", "i", "", inputHelp)
+	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Output format: plain|yaml|json|csv|tsv|go-template=...|go-template-file=...|jsonpath=...")
+
+	return cmd
+}
@@ -0,0 +1,96 @@
+package members
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
+)
+
+func MembersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "members",
+		Short: "Manage group membership",
+		Long: `Manage which users belong to Bitbucket groups.
+
+Groups and users can be given with --name and --users, or with a YAML or JSON file
+mapping groups to members. The output of "group get --members -o yaml" and
+"group members get -o yaml" can be used as input file.`,
+	}
+
+	cmd.AddCommand(
+		GetCmd(),
+		AddCmd(),
+		RemoveCmd(),
+		SetCmd(),
+	)
+
+	return cmd
+}
+
+const inputHelp = `Path to YAML or JSON file mapping groups to members, or "-" to read from stdin.
+Example file content:
+  groups:
+    - name: developers
+      members:
+        - user1
+        - user2`
+
+// parseGroups builds the group list from --name/--users or from an input file
+func parseGroups(name, users, input string) ([]models.Group, error) {
+	if (name != "" && input != "") || (name == "" && input == "") {
+		return nil, fmt.Errorf("either --name or --input must be specified, but not both")
+	}
+
+	if name != "" {
+		var members []string
+		for _, u := range strings.Split(users, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				members = append(members, u)
+			}
+		}
+		var groups []models.Group
+		for _, g := range strings.Split(name, ",") {
+			if g = strings.TrimSpace(g); g != "" {
+				groups = append(groups, models.Group{Name: g, Members: members})
+			}
+		}
+		return groups, nil
+	}
+
+	var parsed models.GroupYaml
+	if err := utils.ParseFile(input, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse file %s: %w", input, err)
+	}
+	if len(parsed.Groups) == 0 {
+		return nil, fmt.Errorf("no groups found in file %s", input)
+	}
+	for i, g := range parsed.Groups {
+		if g.Name == "" {
+			return nil, fmt.Errorf("group at index %d is missing required field 'name'", i)
+		}
+	}
+	return parsed.Groups, nil
+}
+
+// groupNames returns the names of groups
+func groupNames(groups []models.Group) []string {
+	names := make([]string, len(groups))
+	for i, g := range groups {
+		names[i] = g.Name
+	}
+	return names
+}
+
+// memberTargets lists the members to remove for ConfirmDelete as "<group> user:<name>"
+func memberTargets(groups []models.Group) []string {
+	var targets []string
+	for _, g := range groups {
+		for _, u := range g.Members {
+			targets = append(targets, g.Name+" user:"+u)
+		}
+	}
+	return targets
+}
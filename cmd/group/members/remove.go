@@ -0,0 +1,57 @@
+package members
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/utils"
+)
+
+func RemoveCmd() *cobra.Command {
+	var (
+		name   string
+		users  string
+		input  string
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "remove",
+		Short: "Remove users from groups",
+		Long:  `Remove users from one or more groups either from CLI flags or from a YAML or JSON file.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if name != "" && users == "" {
+				return fmt.Errorf("--users is required when using --name")
+			}
+			groups, err := parseGroups(name, users, input)
+			if err != nil {
+				return err
+			}
+
+			if err := utils.ConfirmDelete(config.KindMember, memberTargets(groups)); err != nil {
+				return err
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			removed, err := client.RemoveGroupMembers(cmd.Context(), groups)
+			if err != nil {
+				client.Logger.Error(err.Error())
+			}
+
+			return utils.PrintStructured("groups", removed, output, "name,members")
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Comma-separated group names")
+	cmd.Flags().StringVar(&users, "users", "", "Comma-separated usernames to remove")
+	cmd.Flags().StringVarP(&input, "input", "i", "", inputHelp)
+	cmd.Flags().StringVarP(&output, "output", "o", "plain", "Output format: plain|yaml|json")
+
+	return cmd
+}
@@ -0,0 +1,128 @@
+package members
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
+)
+
+// membershipChange describes what set changed in a group
+type membershipChange struct {
+	Name    string   `json:"name" yaml:"name"`
+	Added   []string `json:"added" yaml:"added"`
+	Removed []string `json:"removed" yaml:"removed"`
+}
+
+func SetCmd() *cobra.Command {
+	var (
+		name   string
+		users  string
+		input  string
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set the exact members of groups",
+		Long: `Set the exact list of members of one or more groups: missing users are added and
+users not in the list are removed. Usernames are compared case-insensitively.
+
+Note: a group listed in the input file without members will have all members removed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if name != "" && users == "" {
+				return fmt.Errorf("--users is required when using --name")
+			}
+			desired, err := parseGroups(name, users, input)
+			if err != nil {
+				return err
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			current, err := client.GetGroupMembers(cmd.Context(), groupNames(desired))
+			if err != nil {
+				return fmt.Errorf("failed to get current members: %w", err)
+			}
+			currentByName := make(map[string][]string, len(current))
+			for _, g := range current {
+				currentByName[g.Name] = g.Members
+			}
+
+			var toAdd, toRemove []models.Group
+			changes := make([]membershipChange, 0, len(desired))
+			for _, g := range desired {
+				add, remove := diffMembers(currentByName[g.Name], g.Members)
+				if len(add) > 0 {
+					toAdd = append(toAdd, models.Group{Name: g.Name, Members: add})
+				}
+				if len(remove) > 0 {
+					toRemove = append(toRemove, models.Group{Name: g.Name, Members: remove})
+				}
+				changes = append(changes, membershipChange{Name: g.Name, Added: add, Removed: remove})
+			}
+
+			if err := utils.ConfirmDelete(config.KindMember, memberTargets(toRemove)); err != nil {
+				return err
+			}
+
+			var failed bool
+			if _, err := client.AddGroupMembers(cmd.Context(), toAdd); err != nil {
+				client.Logger.Error(err.Error())
+				failed = true
+			}
+			if _, err := client.RemoveGroupMembers(cmd.Context(), toRemove); err != nil {
+				client.Logger.Error(err.Error())
+				failed = true
+			}
+
+			if err := utils.PrintStructured("groups", changes, output, "name,added,removed"); err != nil {
+				return fmt.Errorf("failed to print output: %w", err)
+			}
+			if failed {
+				return fmt.Errorf("some membership changes failed, see log")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Comma-separated group names")
+	cmd.Flags().StringVar(&users, "users", "", "Comma-separated usernames that should be the only members")
+	cmd.Flags().StringVarP(&input, "input", "i", "", inputHelp)
+	cmd.Flags().StringVarP(&output, "output", "o", "plain", "Output format: plain|yaml|json")
+
+	return cmd
+}
+
+// diffMembers returns desired users missing from current and current users missing from desired
+func diffMembers(current, desired []string) (add, remove []string) {
+	add, remove = []string{}, []string{}
+	cur := make(map[string]bool, len(current))
+	for _, u := range current {
+		cur[strings.ToLower(u)] = true
+	}
+	want := make(map[string]bool, len(desired))
+	for _, u := range desired {
+		k := strings.ToLower(u)
+		if want[k] {
+			continue
+		}
+		want[k] = true
+		if !cur[k] {
+			add = append(add, u)
+		}
+	}
+	for _, u := range current {
+		if !want[strings.ToLower(u)] {
+			remove = append(remove, u)
+		}
+	}
+	return add, remove
+}
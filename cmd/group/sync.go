@@ -0,0 +1,167 @@
+package group
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/internal/state"
+	"github.com/vinisman/bbctl/utils"
+	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
+)
+
+// groupSyncRow is one line of the sync summary
+type groupSyncRow struct {
+	Action string `json:"action" yaml:"action"`
+	Name   string `json:"name" yaml:"name"`
+	Result string `json:"result" yaml:"result"`
+}
+
+func SyncCmd() *cobra.Command {
+	var (
+		input  string
+		prune  bool
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Reconcile groups with a YAML or JSON file",
+		Long: `Reconcile Bitbucket groups with a YAML or JSON file that is the source of truth
+(for example a directory export).
+
+Groups are matched by name (case-insensitive):
+ - create: groups in the file that do not exist in Bitbucket
+ - delete: groups in Bitbucket missing from the file; only deleted with --prune,
+   otherwise they are reported as "extra"
+
+A summary table of all changes is printed at the end. Use --dry-run to only print it.
+
+Note: If you encounter a 401 Unauthorized error, please use your username and password for authentication instead of a token.
+This is required for older Bitbucket versions that do not support token-based operations.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun := config.GlobalCfg.DryRun
+			if input == "" {
+				return fmt.Errorf("--input is required")
+			}
+
+			var parsed models.GroupYaml
+			if err := utils.ParseFile(input, &parsed); err != nil {
+				return fmt.Errorf("failed to parse file %s: %w", input, err)
+			}
+			if len(parsed.Groups) == 0 {
+				return fmt.Errorf("no groups found in file %s", input)
+			}
+			for i, g := range parsed.Groups {
+				if g.Name == "" {
+					return fmt.Errorf("group at index %d is missing required field 'name'", i)
+				}
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			live, err := client.GetAllGroups(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to fetch groups: %w", err)
+			}
+			diff := state.DiffGroups(live, parsed.Groups)
+
+			var pruned []string
+			if prune {
+				for _, g := range diff.Delete {
+					pruned = append(pruned, g.Name)
+				}
+				if err := utils.ConfirmDelete(config.KindGroup, pruned); err != nil {
+					return err
+				}
+			}
+
+			var rows []groupSyncRow
+			var failed int
+			result := func(done map[string]bool, name string) string {
+				switch {
+				case dryRun:
+					return "planned"
+				case !done[strings.ToLower(name)]:
+					failed++
+					return "failed (see log)"
+				default:
+					return "done"
+				}
+			}
+			names := func(groups []openapi.RestDetailedGroup) map[string]bool {
+				m := make(map[string]bool, len(groups))
+				for _, g := range groups {
+					m[strings.ToLower(utils.SafeValue(g.Name))] = true
+				}
+				return m
+			}
+
+			// Create
+			var created []openapi.RestDetailedGroup
+			if len(diff.Create) > 0 && !dryRun {
+				groups := make([]openapi.RestDetailedGroup, len(diff.Create))
+				for i, g := range diff.Create {
+					groups[i] = openapi.RestDetailedGroup{Name: &g.Name}
+				}
+				// errors are logged per group; results hold only created groups
+				created, _ = client.CreateGroups(cmd.Context(), groups)
+			}
+			createdNames := names(created)
+			for _, g := range diff.Create {
+				rows = append(rows, groupSyncRow{Action: "create", Name: g.Name, Result: result(createdNames, g.Name)})
+			}
+
+			// Delete or report extras
+			if !prune {
+				for _, g := range diff.Delete {
+					rows = append(rows, groupSyncRow{Action: "extra", Name: g.Name, Result: "kept (use --prune to delete)"})
+				}
+			} else {
+				var deleted []openapi.RestDetailedGroup
+				if len(pruned) > 0 && !dryRun {
+					deleted, _ = client.DeleteGroups(cmd.Context(), pruned)
+				}
+				deletedNames := names(deleted)
+				for _, g := range diff.Delete {
+					rows = append(rows, groupSyncRow{Action: "delete", Name: g.Name, Result: result(deletedNames, g.Name)})
+				}
+			}
+
+			client.Logger.Info("Group sync summary", "create", len(diff.Create), "extra", len(diff.Delete), "prune", prune, "dryRun", dryRun)
+			if len(rows) == 0 {
+				client.Logger.Info("No changes, groups are in sync")
+				return nil
+			}
+			if err := utils.PrintStructured("changes", rows, output, "action,name,result"); err != nil {
+				return fmt.Errorf("failed to print output: %w", err)
+			}
+			if failed > 0 {
+				return fmt.Errorf("group sync finished with %d failed changes", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&input, "input", "i", "", `Path to YAML or JSON file with all groups, or "-" to read from stdin.
+Example file content:
+  groups:
+    - name: developers
+    - name: testers`)
+	cmd.Flags().BoolVar(&prune, "prune", false, "Delete groups that exist in Bitbucket but are missing from the file")
+	cmd.Flags().StringVarP(
+		&output,
+		"output",
+		"o",
+		"plain",
+		`Output format of the summary: plain|yaml|json.`,
+	)
+
+	return cmd
+}
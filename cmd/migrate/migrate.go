@@ -0,0 +1,134 @@
+package migrate
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/internal/state"
+	"github.com/vinisman/bbctl/utils"
+)
+
+func NewMigrateCmd() *cobra.Command {
+	var (
+		fromContext string
+		toContext   string
+		projects    string
+		mappingFile string
+		output      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Copy repository settings from one Bitbucket instance to another",
+		Long: `Recreate the webhooks, required builds, branch permissions, reviewer groups and
+Workzone sections of the repositories of the given projects on another instance.
+Both instances are given as contexts of the config file (see "bbctl config
+set-context"), which provide the URL and credentials of each side.
+
+Repositories are not created, migrate them with their content first; repositories
+missing on the target are skipped. Settings are applied like "bbctl restore":
+server-assigned ids are dropped, items are matched to target items by natural key,
+missing ones are created and changed ones updated, nothing is deleted.
+Access key exemptions of branch permissions are not copied.
+
+Users and groups are renamed with the mapping file, if given:
+
+  users:
+    jdoe: john.doe
+  groups:
+    old-developers: developers
+
+Users and groups that do not exist on the target after mapping are left out of
+the settings and listed under "unmapped" in the output.
+
+Examples:
+  bbctl migrate --from-context old --to-context new --projects A,B
+  bbctl --dry-run migrate --from-context old --to-context new --projects A --mapping mapping.yaml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if fromContext == "" || toContext == "" {
+				return fmt.Errorf("--from-context and --to-context must be specified")
+			}
+			if projects == "" {
+				return fmt.Errorf("--projects must be specified")
+			}
+			if output != "yaml" && output != "json" {
+				return fmt.Errorf("invalid output format: %s, allowed values: yaml, json", output)
+			}
+
+			var mapping models.MigrationMapping
+			if mappingFile != "" {
+				if err := utils.ParseFile(mappingFile, &mapping); err != nil {
+					return fmt.Errorf("failed to parse file %s: %w", mappingFile, err)
+				}
+			}
+
+			source, err := newContextClient(fromContext)
+			if err != nil {
+				return err
+			}
+			target, err := newContextClient(toContext)
+			if err != nil {
+				return err
+			}
+
+			repos, err := state.MigrationSource(cmd.Context(), source, config.SplitList(projects))
+			if err != nil {
+				return err
+			}
+			if len(repos) == 0 {
+				return fmt.Errorf("no repositories found in projects %s", projects)
+			}
+
+			result, err := state.MigratePlan(cmd.Context(), target, repos, mapping)
+			if err != nil {
+				return fmt.Errorf("failed to build plan: %w", err)
+			}
+			if len(result.Unmapped) > 0 {
+				target.Logger.Warn("Users or groups not found on the target were left out of the settings, see unmapped", "count", len(result.Unmapped))
+			}
+
+			switch {
+			case state.CountChanges(result.Plan) == 0:
+				target.Logger.Info("Nothing to migrate, target settings match the source")
+			case target.DryRun():
+				target.Logger.Info("Dry run, migration plan not applied", "changes", state.CountChanges(result.Plan))
+			default:
+				if err := state.Apply(cmd.Context(), target, result.Plan, ""); err != nil {
+					return err
+				}
+			}
+
+			return utils.PrintStructured("migrate", result, output, "")
+		},
+	}
+
+	cmd.Flags().StringVar(&fromContext, "from-context", "", "Context of the instance to copy the settings from")
+	cmd.Flags().StringVar(&toContext, "to-context", "", "Context of the instance to create the settings on")
+	cmd.Flags().StringVar(&projects, "projects", "", "Comma-separated keys of the projects whose repositories are migrated")
+	cmd.Flags().StringVar(&mappingFile, "mapping", "", "YAML or JSON file renaming users and groups of the source, see above")
+	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Output format for the migrated changes and unmapped users: yaml or json")
+
+	return cmd
+}
+
+// newContextClient returns a client for a context of the config file, with
+// the dry-run mode of the command line
+func newContextClient(name string) (*bitbucket.Client, error) {
+	cfg, err := config.LoadContext(name)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DryRun = config.GlobalCfg.DryRun
+	// each instance is recorded in a directory of its own, the same paths exist on both
+	if config.GlobalCfg.RecordDir != "" {
+		cfg.RecordDir = filepath.Join(config.GlobalCfg.RecordDir, name)
+	}
+	if config.GlobalCfg.ReplayDir != "" {
+		cfg.ReplayDir = filepath.Join(config.GlobalCfg.ReplayDir, name)
+	}
+	return bitbucket.NewClientFor(cfg)
+}
@@ -0,0 +1,82 @@
+package plan
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/internal/state"
+	"github.com/vinisman/bbctl/utils"
+)
+
+func NewPlanCmd() *cobra.Command {
+	var (
+		file   string
+		out    string
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Compute the changes needed to reach a desired-state file",
+		Long: `Compute the plan that "bbctl apply" would execute for a desired-state file,
+without changing anything in Bitbucket.
+
+With --out the plan is saved to a versioned, checksummed plan file that also
+records a fingerprint of the live state it was computed against. The saved
+plan can be reviewed (e.g. in a merge request) and executed later with
+"bbctl apply --plan FILE", which refuses to run if the file was modified or
+the live state changed in the meantime.
+
+Examples:
+  bbctl plan -f state.yaml
+  bbctl plan -f state.yaml --out plan.json
+  bbctl apply --plan plan.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if file == "" {
+				return fmt.Errorf("--file is required")
+			}
+			if output != "yaml" && output != "json" {
+				return fmt.Errorf("invalid output format: %s, allowed values: yaml, json", output)
+			}
+
+			var desired models.StateYaml
+			if err := utils.ParseFile(file, &desired); err != nil {
+				return fmt.Errorf("failed to parse file %s: %w", file, err)
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			p, fingerprint, err := state.BuildPlan(cmd.Context(), client, desired)
+			if err != nil {
+				return fmt.Errorf("failed to build plan: %w", err)
+			}
+
+			if out != "" {
+				pf := &models.PlanFile{
+					CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+					Fingerprint: fingerprint,
+					Desired:     desired,
+					Plan:        *p,
+				}
+				if err := utils.WritePlanFile(out, pf); err != nil {
+					return fmt.Errorf("failed to write plan file: %w", err)
+				}
+				client.Logger.Info("Plan saved", "file", out, "changes", state.CountChanges(p))
+			}
+
+			return utils.PrintStructured("plan", p, output, "")
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", `Path to desired-state YAML or JSON file, or "-" to read from stdin`)
+	cmd.Flags().StringVar(&out, "out", "", "Save the plan to FILE (JSON) for a later 'bbctl apply --plan'")
+	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Output format: yaml or json")
+
+	return cmd
+}
@@ -1,7 +1,6 @@
 package project
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -33,7 +32,7 @@ This is required for older Bitbucket versions that do not support token-based op
 				return fmt.Errorf("either --key and --name or --input must be specified, but not both")
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -68,7 +67,7 @@ This is required for older Bitbucket versions that do not support token-based op
 			}
 
 			if len(projects) > 0 {
-				createdProjects, err := client.CreateProjects(projects)
+				createdProjects, err := client.CreateProjects(cmd.Context(), projects)
 				if err != nil {
 					client.Logger.Error(err.Error())
 					return nil
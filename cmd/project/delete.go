@@ -1,12 +1,12 @@
 package project
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/config"
 	"github.com/vinisman/bbctl/internal/models"
 	"github.com/vinisman/bbctl/utils"
 )
@@ -27,7 +27,7 @@ func NewDeleteCmd() *cobra.Command {
 				return fmt.Errorf("either --key or --input must be specified, but not both")
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -64,8 +64,12 @@ func NewDeleteCmd() *cobra.Command {
 				}
 			}
 
+			if err := utils.ConfirmDelete(config.KindProject, keys); err != nil {
+				return err
+			}
+
 			// Run deletion
-			if err := client.DeleteProjects(keys); err != nil {
+			if err := client.DeleteProjects(cmd.Context(), keys); err != nil {
 				client.Logger.Error("Failed to delete projects", "error", err)
 				//return err
 			}
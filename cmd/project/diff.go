@@ -0,0 +1,169 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
+	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
+)
+
+func NewDiffCmd() *cobra.Command {
+	var (
+		source string
+		target string
+		output string
+		apply  bool
+		prune  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare projects between two files and generate/apply diff",
+		Long: `Compare projects between two YAML/JSON files and generate a diff with three sections:
+ - create: projects with keys present in TARGET but not in SOURCE
+ - update: projects present in both files whose name, description or public flag set in the target differs
+ - delete: projects with keys present in SOURCE but not in TARGET
+
+Notes:
+ - SOURCE is the current state, e.g. the output of "bbctl project get -o yaml"; TARGET is the desired state.
+ - Projects are matched by key (case-insensitive).
+ - Project deletion is destructive: the delete section is only applied with --prune.
+
+Options:
+ - --apply: execute the diff against Bitbucket (delete, then update, then create)
+ - --prune: together with --apply, also delete projects missing from TARGET`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if source == "" || target == "" {
+				return fmt.Errorf("both --source and --target are required")
+			}
+			if prune && !apply {
+				return fmt.Errorf("--prune can only be used together with --apply")
+			}
+			if output != "yaml" && output != "json" {
+				return fmt.Errorf("invalid output format: %s, allowed values: yaml, json", output)
+			}
+
+			var parsedSource models.ProjectYaml
+			if err := utils.ParseFile(source, &parsedSource); err != nil {
+				return fmt.Errorf("failed to parse source file: %w", err)
+			}
+			var parsedTarget models.ProjectYaml
+			if err := utils.ParseFile(target, &parsedTarget); err != nil {
+				return fmt.Errorf("failed to parse target file: %w", err)
+			}
+
+			diff, err := generateProjectDiff(parsedSource.Projects, parsedTarget.Projects)
+			if err != nil {
+				return fmt.Errorf("failed to generate diff: %w", err)
+			}
+
+			if !apply {
+				return utils.PrintStructured("diff", diff, output, "")
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			deleted := []openapi.RestProject{}
+			if len(diff.Delete) > 0 {
+				if prune {
+					keys := make([]string, 0, len(diff.Delete))
+					for _, p := range diff.Delete {
+						keys = append(keys, *p.Key)
+					}
+					if err := utils.ConfirmDelete(config.KindProject, keys); err != nil {
+						return err
+					}
+					if err := client.DeleteProjects(cmd.Context(), keys); err != nil {
+						return fmt.Errorf("apply delete failed: %w", err)
+					}
+					deleted = diff.Delete
+				} else {
+					client.Logger.Warn("Skipping deletion of projects missing from target, use --prune to delete them", "count", len(diff.Delete))
+				}
+			}
+
+			updated := []openapi.RestProject{}
+			if len(diff.Update) > 0 {
+				updated, err = client.UpdateProjects(cmd.Context(), diff.Update)
+				if err != nil {
+					return fmt.Errorf("apply update failed: %w", err)
+				}
+			}
+
+			created := []openapi.RestProject{}
+			if len(diff.Create) > 0 {
+				created, err = client.CreateProjects(cmd.Context(), diff.Create)
+				if err != nil {
+					return fmt.Errorf("apply create failed: %w", err)
+				}
+			}
+
+			applyResult := map[string]interface{}{
+				"updated": updated,
+				"created": created,
+				"deleted": deleted,
+			}
+			return utils.PrintStructured("apply", applyResult, output, "")
+		},
+	}
+
+	cmd.Flags().StringVarP(&source, "source", "s", "", "Source YAML or JSON file (current state)")
+	cmd.Flags().StringVarP(&target, "target", "t", "", "Target YAML or JSON file (desired state)")
+	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Output format: yaml or json")
+	cmd.Flags().BoolVarP(&apply, "apply", "a", false, "Apply the diff to Bitbucket: delete (with --prune), then update, then create")
+	cmd.Flags().BoolVar(&prune, "prune", false, "With --apply, delete projects present in SOURCE but missing from TARGET")
+
+	return cmd
+}
+
+// generateProjectDiff classifies projects into create/update/delete by key
+func generateProjectDiff(src, tgt []openapi.RestProject) (*models.ProjectDiff, error) {
+	diff := &models.ProjectDiff{
+		Create: []openapi.RestProject{},
+		Update: []openapi.RestProject{},
+		Delete: []openapi.RestProject{},
+	}
+
+	srcByKey := make(map[string]openapi.RestProject, len(src))
+	for _, p := range src {
+		if p.Key == nil || *p.Key == "" {
+			return nil, fmt.Errorf("source contains a project without key")
+		}
+		srcByKey[strings.ToUpper(*p.Key)] = p
+	}
+
+	tgtKeys := make(map[string]bool, len(tgt))
+	for _, p := range tgt {
+		if p.Key == nil || *p.Key == "" {
+			return nil, fmt.Errorf("target contains a project without key")
+		}
+		k := strings.ToUpper(*p.Key)
+		if tgtKeys[k] {
+			return nil, fmt.Errorf("target contains duplicate project key %s", *p.Key)
+		}
+		tgtKeys[k] = true
+
+		cur, ok := srcByKey[k]
+		switch {
+		case !ok:
+			diff.Create = append(diff.Create, p)
+		case !bitbucket.AreProjectsEqual(cur, p):
+			diff.Update = append(diff.Update, p)
+		}
+	}
+
+	for _, p := range src {
+		if !tgtKeys[strings.ToUpper(*p.Key)] {
+			diff.Delete = append(diff.Delete, p)
+		}
+	}
+	return diff, nil
+}
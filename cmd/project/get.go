@@ -1,7 +1,6 @@
 package project
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -13,10 +12,11 @@ import (
 
 func NewGetCmd() *cobra.Command {
 	var (
-		key    string
-		all    bool
-		output string
-		input  string
+		key     string
+		all     bool
+		output  string
+		columns string
+		input   string
 	)
 
 	cmd := &cobra.Command{
@@ -43,7 +43,7 @@ You must specify exactly one of the following options:
 				return fmt.Errorf("please specify exactly one of --key, --all, or --input")
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -52,13 +52,13 @@ You must specify exactly one of the following options:
 
 			switch {
 			case all:
-				projects, err = bitbucket.GetAllProjects(client)
+				projects, err = bitbucket.GetAllProjects(cmd.Context(), client)
 				if err != nil {
 					client.Logger.Error(err.Error())
 					return nil
 				}
 			case key != "":
-				projects, err = client.GetProjects(utils.ParseColumnsToLower(key))
+				projects, err = client.GetProjects(cmd.Context(), utils.ParseColumnsToLower(key))
 				if err != nil {
 					client.Logger.Error(err.Error())
 					return nil
@@ -84,14 +84,14 @@ You must specify exactly one of the following options:
 					return fmt.Errorf("no project keys found in file %s", input)
 				}
 
-				projects, err = client.GetProjects(projectKeys)
+				projects, err = client.GetProjects(cmd.Context(), projectKeys)
 				if err != nil {
 					client.Logger.Error(err.Error())
 					return nil
 				}
 			}
 
-			if err := utils.PrintStructured("projects", projects, output, "id,name,key,description"); err != nil {
+			if err := utils.PrintStructured("projects", projects, output, columns); err != nil {
 				return fmt.Errorf("failed to print output: %w", err)
 			}
 			return nil
@@ -105,9 +105,10 @@ You must specify exactly one of the following options:
 		"output",
 		"o",
 		"plain",
-		`Output format: plain|yaml|json.
+		`Output format: plain|yaml|json|csv|tsv|go-template=...|go-template-file=...|jsonpath=...
 The "yaml" and "json" formats print the full available structure with all fields.`,
 	)
+	cmd.Flags().StringVar(&columns, "columns", "id,name,key,description", "Comma-separated fields to display for plain, csv and tsv output")
 	cmd.Flags().StringVarP(&input, "input", "i", "", `Path to YAML or JSON file with projects to get, or "-" to read from stdin.
 Example file content:
   projects:
@@ -0,0 +1,57 @@
+package permission
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+)
+
+func DiffPermissionCmd() *cobra.Command {
+	var (
+		key        string
+		users      string
+		groups     string
+		permission string
+		input      string
+		output     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show permission drift of projects",
+		Long: `Show the difference between the desired permissions and the permissions in Bitbucket.
+Nothing is changed; "project permission set" with the same arguments applies the changes.
+
+` + setHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if key != "" && users == "" && groups == "" {
+				return fmt.Errorf("--user or --group is required when using --key")
+			}
+			projects, err := parseProjects(key, users, groups, permission, input, true)
+			if err != nil {
+				return err
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			_, _, drift, err := projectDrift(cmd.Context(), client, projects)
+			if err != nil {
+				return err
+			}
+			if len(drift) == 0 {
+				client.Logger.Info("No permission drift")
+				return nil
+			}
+			return printDrift(drift, output)
+		},
+	}
+
+	addFlags(cmd, &key, &users, &groups, &permission, &input)
+	cmd.Flags().StringVarP(&output, "output", "o", "plain", "Output format: plain|yaml|json")
+
+	return cmd
+}
@@ -0,0 +1,57 @@
+package permission
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+)
+
+func GetPermissionCmd() *cobra.Command {
+	var (
+		key    string
+		input  string
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Get user and group permissions of projects",
+		Long: `Get explicit user and group permissions of one or more projects.
+Specify projects with --key or with --input (permissions in the file are ignored).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projects, err := parseProjects(key, "", "", "", input, false)
+			if err != nil {
+				return err
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			values, err := client.GetProjectPermissions(cmd.Context(), projects)
+			if err != nil {
+				client.Logger.Error(err.Error())
+			}
+
+			if err := printProjects(values, output); err != nil {
+				return fmt.Errorf("failed to print output: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&key, "key", "k", "", "Comma-separated project keys")
+	cmd.Flags().StringVarP(&input, "input", "i", "", inputHelp)
+	cmd.Flags().StringVarP(
+		&output,
+		"output",
+		"o",
+		"plain",
+		`Output format: plain|yaml|json|csv|tsv|go-template=...|go-template-file=...|jsonpath=...
+The "yaml" and "json" formats can be used as input of "project permission set".`,
+	)
+
+	return cmd
+}
@@ -0,0 +1,48 @@
+package permission
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+)
+
+func GrantPermissionCmd() *cobra.Command {
+	var (
+		key        string
+		users      string
+		groups     string
+		permission string
+		input      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "grant",
+		Short: "Grant permissions on projects to users and groups",
+		Long: `Grant a project permission to users and groups. An existing permission of a
+user or group is replaced by the new one. Other users and groups are not changed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if key != "" && users == "" && groups == "" {
+				return fmt.Errorf("--user or --group is required when using --key")
+			}
+			projects, err := parseProjects(key, users, groups, permission, input, true)
+			if err != nil {
+				return err
+			}
+			if !hasEntries(projects) {
+				return fmt.Errorf("no permissions to grant")
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			return client.GrantProjectPermissions(cmd.Context(), projects)
+		},
+	}
+
+	addFlags(cmd, &key, &users, &groups, &permission, &input)
+
+	return cmd
+}
@@ -0,0 +1,151 @@
+package permission
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
+)
+
+func ProjectPermissionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "permission",
+		Short: "Manage project permissions",
+		Long: `Manage explicit user and group permissions of Bitbucket projects
+(PROJECT_READ, PROJECT_WRITE, PROJECT_ADMIN).
+
+Projects and permissions can be given with --key, --user, --group and --permission,
+or with a YAML or JSON file. The output of "project permission get -o yaml" can be
+used as input file, and the "key" field matches "project get" documents.`,
+	}
+
+	cmd.AddCommand(
+		GetPermissionCmd(),
+		GrantPermissionCmd(),
+		RevokePermissionCmd(),
+		SetPermissionCmd(),
+		DiffPermissionCmd(),
+	)
+
+	return cmd
+}
+
+const inputHelp = `Path to YAML or JSON file with project permissions, or "-" to read from stdin.
+Example file content:
+  projects:
+    - key: PRJ
+      permissions:
+        users:
+          - name: user1
+            permission: PROJECT_WRITE
+        groups:
+          - name: developers
+            permission: PROJECT_READ`
+
+// permissionRow is one line of plain output
+type permissionRow struct {
+	ProjectKey string `json:"projectKey" yaml:"projectKey"`
+	Type       string `json:"type" yaml:"type"`
+	Name       string `json:"name" yaml:"name"`
+	Permission string `json:"permission" yaml:"permission"`
+}
+
+// parseProjects builds the project list from flags or from an input file.
+// Permissions from flags apply to every project in --key.
+func parseProjects(key, users, groups, permission, input string, requirePermission bool) ([]models.ExtendedProject, error) {
+	if (key != "" && input != "") || (key == "" && input == "") {
+		return nil, fmt.Errorf("either --key or --input must be specified, but not both")
+	}
+
+	var projects []models.ExtendedProject
+	if input != "" {
+		var parsed models.ProjectPermissionYaml
+		if err := utils.ParseFile(input, &parsed); err != nil {
+			return nil, fmt.Errorf("failed to parse file %s: %w", input, err)
+		}
+		if len(parsed.Projects) == 0 {
+			return nil, fmt.Errorf("no projects found in file %s", input)
+		}
+		projects = parsed.Projects
+	} else {
+		var perms *models.Permissions
+		if users != "" || groups != "" {
+			perms = &models.Permissions{
+				Users:  entries(users, permission),
+				Groups: entries(groups, permission),
+			}
+		}
+		for _, k := range strings.Split(key, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				projects = append(projects, models.ExtendedProject{Key: k, Permissions: perms})
+			}
+		}
+	}
+
+	for i := range projects {
+		if projects[i].Key == "" {
+			return nil, fmt.Errorf("project at index %d is missing required field 'key'", i)
+		}
+		if err := utils.NormalizePermissions(projects[i].Permissions, utils.ProjectPermissions, requirePermission); err != nil {
+			return nil, fmt.Errorf("project %s: %w", projects[i].Key, err)
+		}
+	}
+	return projects, nil
+}
+
+// entries builds permission entries from comma-separated names, nil if names is empty
+func entries(names, permission string) []models.PermissionEntry {
+	var result []models.PermissionEntry
+	for _, n := range strings.Split(names, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			result = append(result, models.PermissionEntry{Name: n, Permission: permission})
+		}
+	}
+	return result
+}
+
+// hasEntries reports whether any project lists a user or group
+func hasEntries(projects []models.ExtendedProject) bool {
+	for _, p := range projects {
+		if p.Permissions != nil && (len(p.Permissions.Users) > 0 || len(p.Permissions.Groups) > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// toRows flattens project permissions for plain output
+func toRows(projects []models.ExtendedProject) []permissionRow {
+	var rows []permissionRow
+	for _, p := range projects {
+		if p.Permissions == nil {
+			continue
+		}
+		for _, e := range p.Permissions.Users {
+			rows = append(rows, permissionRow{ProjectKey: p.Key, Type: "user", Name: e.Name, Permission: e.Permission})
+		}
+		for _, e := range p.Permissions.Groups {
+			rows = append(rows, permissionRow{ProjectKey: p.Key, Type: "group", Name: e.Name, Permission: e.Permission})
+		}
+	}
+	return rows
+}
+
+// printProjects prints projects as a YAML/JSON document or as plain rows
+func printProjects(projects []models.ExtendedProject, output string) error {
+	if utils.IsTable(output) {
+		return utils.PrintStructured("projects", toRows(projects), output, "projectKey,type,name,permission")
+	}
+	return utils.PrintStructured("projects", projects, output, "")
+}
+
+// addFlags registers the flags shared by grant, revoke, set and diff
+func addFlags(cmd *cobra.Command, key, users, groups, permission, input *string) {
+	cmd.Flags().StringVarP(key, "key", "k", "", "Comma-separated project keys")
+	cmd.Flags().StringVar(users, "user", "", "Comma-separated usernames")
+	cmd.Flags().StringVar(groups, "group", "", "Comma-separated group names")
+	cmd.Flags().StringVar(permission, "permission", "", "Permission for --user and --group: "+strings.Join(utils.ProjectPermissions, "|"))
+	cmd.Flags().StringVarP(input, "input", "i", "", inputHelp)
+}
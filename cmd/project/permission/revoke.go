@@ -0,0 +1,65 @@
+package permission
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
+)
+
+func RevokePermissionCmd() *cobra.Command {
+	var (
+		key        string
+		users      string
+		groups     string
+		permission string
+		input      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke all project permissions of users and groups",
+		Long: `Revoke all explicit permissions of users and groups on projects.
+The permission field of the input file is ignored.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if key != "" && users == "" && groups == "" {
+				return fmt.Errorf("--user or --group is required when using --key")
+			}
+			projects, err := parseProjects(key, users, groups, permission, input, false)
+			if err != nil {
+				return err
+			}
+			if !hasEntries(projects) {
+				return fmt.Errorf("no permissions to revoke")
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			if err := utils.ConfirmDelete(config.KindPermission, revokeTargets(projects)); err != nil {
+				return err
+			}
+
+			return client.RevokeProjectPermissions(cmd.Context(), projects)
+		},
+	}
+
+	addFlags(cmd, &key, &users, &groups, &permission, &input)
+	cmd.Flags().Lookup("permission").Usage = "Ignored, all permissions are revoked"
+
+	return cmd
+}
+
+// revokeTargets lists the permissions to revoke for ConfirmDelete
+func revokeTargets(projects []models.ExtendedProject) []string {
+	var targets []string
+	for _, r := range projects {
+		targets = append(targets, utils.PermissionTargets(r.Key, r.Permissions)...)
+	}
+	return targets
+}
@@ -0,0 +1,124 @@
+package permission
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
+)
+
+const setHelp = `The given users and groups become the exact list of explicit permissions:
+missing ones are granted, changed ones are updated and unlisted ones are revoked.
+Users and groups are managed separately: if a project lists only users, its group
+permissions are left untouched. Use "users: []" to revoke all user permissions.`
+
+func SetPermissionCmd() *cobra.Command {
+	var (
+		key        string
+		users      string
+		groups     string
+		permission string
+		input      string
+		output     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set the exact permissions of projects",
+		Long:  "Set the exact user and group permissions of projects.\n\n" + setHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if key != "" && users == "" && groups == "" {
+				return fmt.Errorf("--user or --group is required when using --key")
+			}
+			projects, err := parseProjects(key, users, groups, permission, input, true)
+			if err != nil {
+				return err
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			grant, revoke, drift, err := projectDrift(cmd.Context(), client, projects)
+			if err != nil {
+				return err
+			}
+			if len(drift) == 0 {
+				client.Logger.Info("No changes, permissions are in sync")
+				return nil
+			}
+
+			if err := utils.ConfirmDelete(config.KindPermission, revokeTargets(revoke)); err != nil {
+				return err
+			}
+
+			var failed bool
+			if err := client.GrantProjectPermissions(cmd.Context(), grant); err != nil {
+				client.Logger.Error(err.Error())
+				failed = true
+			}
+			if err := client.RevokeProjectPermissions(cmd.Context(), revoke); err != nil {
+				client.Logger.Error(err.Error())
+				failed = true
+			}
+
+			if err := printDrift(drift, output); err != nil {
+				return err
+			}
+			if failed {
+				return fmt.Errorf("some permission changes failed, see log")
+			}
+			return nil
+		},
+	}
+
+	addFlags(cmd, &key, &users, &groups, &permission, &input)
+	cmd.Flags().StringVarP(&output, "output", "o", "plain", "Output format of the changes: plain|yaml|json")
+
+	return cmd
+}
+
+// projectDrift compares the desired permissions with Bitbucket and returns
+// the permissions to grant and revoke together with the per-project changes
+func projectDrift(ctx context.Context, client *bitbucket.Client, desired []models.ExtendedProject) (grant, revoke []models.ExtendedProject, drift []models.PermissionDrift, err error) {
+	current, err := client.GetProjectPermissions(ctx, desired)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get current permissions: %w", err)
+	}
+	currentByKey := make(map[string]*models.Permissions, len(current))
+	for _, p := range current {
+		currentByKey[strings.ToUpper(p.Key)] = p.Permissions
+	}
+
+	for _, p := range desired {
+		if p.Permissions == nil {
+			continue
+		}
+		cur := currentByKey[strings.ToUpper(p.Key)]
+		if cur == nil {
+			cur = &models.Permissions{}
+		}
+		g, r, changes := utils.DiffPermissions(*cur, *p.Permissions)
+		if len(changes) == 0 {
+			continue
+		}
+		grant = append(grant, models.ExtendedProject{Key: p.Key, Permissions: &g})
+		revoke = append(revoke, models.ExtendedProject{Key: p.Key, Permissions: &r})
+		drift = append(drift, models.PermissionDrift{ProjectKey: p.Key, Changes: changes})
+	}
+	return grant, revoke, drift, nil
+}
+
+// printDrift prints the per-project permission changes
+func printDrift(drift []models.PermissionDrift, output string) error {
+	if err := utils.PrintStructured("projects", drift, output, "projectKey,changes.type,changes.name,changes.from,changes.to"); err != nil {
+		return fmt.Errorf("failed to print output: %w", err)
+	}
+	return nil
+}
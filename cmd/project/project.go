@@ -2,6 +2,7 @@ package project
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/cmd/project/permission"
 )
 
 func NewProjectCmd() *cobra.Command {
@@ -15,6 +16,8 @@ func NewProjectCmd() *cobra.Command {
 		NewCreateCmd(),
 		NewUpdateCmd(),
 		NewDeleteCmd(),
+		NewDiffCmd(),
+		permission.ProjectPermissionCmd(),
 	)
 
 	return cmd
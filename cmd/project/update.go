@@ -1,7 +1,6 @@
 package project
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -30,7 +29,7 @@ func NewUpdateCmd() *cobra.Command {
 				return fmt.Errorf("either --key and --name or --input must be specified, but not both")
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -66,7 +65,7 @@ func NewUpdateCmd() *cobra.Command {
 
 			// Only call UpdateProjects once, if any projects were collected
 			if len(projects) > 0 {
-				updatedProjects, err := client.UpdateProjects(projects)
+				updatedProjects, err := client.UpdateProjects(cmd.Context(), projects)
 				if err != nil {
 					client.Logger.Error(err.Error())
 					return nil
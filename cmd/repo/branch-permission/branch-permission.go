@@ -15,6 +15,7 @@ func RepoBranchPermissionCmd() *cobra.Command {
 		CreateBranchPermissionCmd(),
 		DeleteBranchPermissionCmd(),
 		UpdateBranchPermissionCmd(),
+		DiffBranchPermissionCmd(),
 	)
 
 	return cmd
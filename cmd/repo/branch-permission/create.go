@@ -1,7 +1,6 @@
 package branchpermission
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -34,7 +33,7 @@ so make sure to use unique matcher/type combinations to avoid confusion.`,
 
 			repos := parsed.ToRepositoryYaml()
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -56,7 +55,7 @@ so make sure to use unique matcher/type combinations to avoid confusion.`,
 				return fmt.Errorf("no branch permissions defined in file %s", input)
 			}
 
-			updatedRepos, err := client.CreateBranchPermissions(repos.Repositories)
+			updatedRepos, err := client.CreateBranchPermissions(cmd.Context(), repos.Repositories)
 			if err != nil {
 				client.Logger.Error(err.Error())
 			}
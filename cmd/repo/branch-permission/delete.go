@@ -1,13 +1,13 @@
 package branchpermission
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/vinisman/bbctl/internal/bitbucket"
 	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/internal/state"
 	"github.com/vinisman/bbctl/utils"
 	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
 )
@@ -25,7 +25,7 @@ func DeleteBranchPermissionCmd() *cobra.Command {
 
 Can delete from command line using --ids flag or from a YAML file using --input.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -85,7 +85,11 @@ Can delete from command line using --ids flag or from a YAML file using --input.
 				return fmt.Errorf("no branch permissions defined for deletion")
 			}
 
-			err = client.DeleteBranchPermissions(repositories)
+			if err := state.BackupBeforeDelete(cmd.Context(), client, "branch-permission-delete", repositories, false); err != nil {
+				return err
+			}
+
+			err = client.DeleteBranchPermissions(cmd.Context(), repositories)
 			if err != nil {
 				client.Logger.Error(err.Error())
 			}
@@ -1,7 +1,6 @@
 package repo
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -35,7 +34,7 @@ You must specify either:
 				return fmt.Errorf("either --input or (--projectKey and --name) must be specified")
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -70,7 +69,7 @@ You must specify either:
 				repos = []models.ExtendedRepository{repo}
 			}
 
-			createdRepos, err := client.CreateRepos(repos)
+			createdRepos, err := client.CreateRepos(cmd.Context(), repos)
 			if err != nil {
 				client.Logger.Error(err.Error())
 			}
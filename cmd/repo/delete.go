@@ -1,13 +1,14 @@
 package repo
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/config"
 	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/internal/state"
 	"github.com/vinisman/bbctl/utils"
 )
 
@@ -27,7 +28,7 @@ func NewDeleteCmd() *cobra.Command {
 				return fmt.Errorf("either --repositorySlug or --input must be specified, but not both")
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -42,7 +43,13 @@ func NewDeleteCmd() *cobra.Command {
 				if len(parsed.Repositories) == 0 {
 					return fmt.Errorf("no repositories found in %s", input)
 				}
-				return client.DeleteRepos(parsed.Repositories)
+				if err := confirmDelete(parsed.Repositories); err != nil {
+					return err
+				}
+				if err := state.BackupBeforeDelete(cmd.Context(), client, "repo-delete", parsed.Repositories, false); err != nil {
+					return err
+				}
+				return client.DeleteRepos(cmd.Context(), parsed.Repositories)
 			}
 
 			// Case 2: delete repos by project+slug (support multiple comma-separated)
@@ -64,7 +71,13 @@ func NewDeleteCmd() *cobra.Command {
 				})
 			}
 
-			err = client.DeleteRepos(repos)
+			if err := confirmDelete(repos); err != nil {
+				return err
+			}
+			if err := state.BackupBeforeDelete(cmd.Context(), client, "repo-delete", repos, false); err != nil {
+				return err
+			}
+			err = client.DeleteRepos(cmd.Context(), repos)
 			if err != nil {
 				client.Logger.Error(err.Error())
 			}
@@ -84,3 +97,12 @@ repositories:
 
 	return cmd
 }
+
+// confirmDelete checks the repositories against the delete safeguards
+func confirmDelete(repos []models.ExtendedRepository) error {
+	targets := make([]string, 0, len(repos))
+	for _, r := range repos {
+		targets = append(targets, r.ProjectKey+"/"+r.RepositorySlug)
+	}
+	return utils.ConfirmDelete(config.KindRepository, targets)
+}
@@ -1,7 +1,6 @@
 package repo
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
@@ -36,7 +35,7 @@ or --input for a YAML file containing multiple forks.`,
 				return fmt.Errorf("either --input, or --repositorySlug must be specified, but not both")
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -49,7 +48,7 @@ or --input for a YAML file containing multiple forks.`,
 				if err := utils.ParseFile(input, &parsed); err != nil {
 					return fmt.Errorf("failed to parse YAML file: %w", err)
 				}
-				forkedRepos, err := client.ForkRepos(parsed.Repositories)
+				forkedRepos, err := client.ForkRepos(cmd.Context(), parsed.Repositories)
 				if err != nil {
 					client.Logger.Error(err.Error())
 				}
@@ -98,7 +97,7 @@ or --input for a YAML file containing multiple forks.`,
 				RestRepository: &restRepo,
 			}
 
-			forkedRepos, err := client.ForkRepos([]models.ExtendedRepository{repo})
+			forkedRepos, err := client.ForkRepos(cmd.Context(), []models.ExtendedRepository{repo})
 			if err != nil {
 				client.Logger.Error(err.Error())
 			}
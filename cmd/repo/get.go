@@ -1,7 +1,6 @@
 package repo
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -76,7 +75,7 @@ Only one of these options should be used at a time.`,
 				return fmt.Errorf("please specify exactly one of --projectKey, --repositorySlug or --input")
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -98,7 +97,7 @@ Only one of these options should be used at a time.`,
 				return fmt.Errorf("--show-details cannot be empty")
 			}
 
-			if showDetails != "" && output != "plain" {
+			if showDetails != "" && !utils.IsTable(output) {
 				// enable only the options specified in showDetails
 				for _, opt := range utils.ParseColumnsToLower(showDetails) {
 					switch opt {
@@ -161,7 +160,7 @@ Only one of these options should be used at a time.`,
 				}
 				var inputErrors []error
 				for _, repo := range parsed.Repositories {
-					r, err := client.GetReposBySlugs(repo.ProjectKey, []string{repo.RepositorySlug}, options)
+					r, err := client.GetReposBySlugs(cmd.Context(), repo.ProjectKey, []string{repo.RepositorySlug}, options)
 					if err != nil {
 						client.Logger.Error("Skipping repository due to error",
 							"project", repo.ProjectKey,
@@ -200,7 +199,7 @@ Only one of these options should be used at a time.`,
 					}
 
 					for project, slugs := range projectMap {
-						r, err := client.GetReposBySlugs(project, slugs, options)
+						r, err := client.GetReposBySlugs(cmd.Context(), project, slugs, options)
 						if err != nil {
 							return err
 						}
@@ -208,13 +207,13 @@ Only one of these options should be used at a time.`,
 					}
 				} else if len(slugList) == 0 && len(projects) == 1 {
 					// Get all repos for single project
-					repos, err = client.GetAllReposForProject(projects[0], options)
+					repos, err = client.GetAllReposForProject(cmd.Context(), projects[0], options)
 					if err != nil {
 						return err
 					}
 				} else if len(slugList) == 0 && len(projects) > 1 {
 					// Get all repos for multiple projects
-					repos, err = client.GetAllRepos(projects, options)
+					repos, err = client.GetAllRepos(cmd.Context(), projects, options)
 					if err != nil {
 						return err
 					}
@@ -224,7 +223,7 @@ Only one of these options should be used at a time.`,
 			}
 
 			// If user explicitly provided show-details, strip fields not requested before structured output
-			if showDetails != "" && output != "plain" {
+			if showDetails != "" && !utils.IsTable(output) {
 				for i := range repos {
 					if !requestedRepository {
 						repos[i].RestRepository = nil
@@ -246,7 +245,7 @@ Only one of these options should be used at a time.`,
 			}
 
 			// Structured output
-			if output == "yaml" || output == "json" {
+			if !utils.IsTable(output) {
 				if requestedConfigs {
 					outRepos := make([]map[string]any, 0, len(repos))
 					for _, repo := range repos {
@@ -270,16 +269,14 @@ Only one of these options should be used at a time.`,
 				}
 				return utils.PrintStructured("repositories", repos, output, columns)
 			}
-			utils.PrintRepos(repos, cols)
-
-			return nil
+			return utils.PrintRepos(repos, cols, output)
 		},
 	}
 
 	cmd.Flags().StringVarP(&projectKey, "projectKey", "k", "", "Comma-separated project keys")
 	cmd.Flags().StringVarP(&repositorySlug, "repositorySlug", "s", "", "Comma-separated repository identifiers in format <projectKey>/<repositorySlug>")
-	cmd.Flags().StringVar(&columns, "columns", "", "Comma-separated list of fields to display (for plain output)")
-	cmd.Flags().StringVarP(&output, "output", "o", "plain", "Output format: plain|yaml|json")
+	cmd.Flags().StringVar(&columns, "columns", "", "Comma-separated list of fields to display (for plain, csv and tsv output)")
+	cmd.Flags().StringVarP(&output, "output", "o", "plain", "Output format: plain|yaml|json|csv|tsv|go-template=...|go-template-file=...|jsonpath=...")
 	cmd.Flags().StringVar(&manifestFile, "manifest-file", "", "Path to the manifest file to output")
 	cmd.Flags().StringSliceVar(&configFiles, "config-file", []string{}, "Config file(s) to output as separate sections in format key=filepath (repeat flag or use comma-separated values)")
 	cmd.Flags().StringVar(&showDetails, "show-details", "repository", `Comma-separated list of options to include in YAML/JSON output
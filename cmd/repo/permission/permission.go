@@ -0,0 +1,145 @@
+package permission
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
+)
+
+func RepoPermissionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "permission",
+		Short: "Manage repository permissions",
+		Long: `Manage explicit user and group permissions of Bitbucket repositories
+(REPO_READ, REPO_WRITE, REPO_ADMIN).
+
+Repositories and permissions can be given with --repositorySlug, --user, --group and
+--permission, or with a YAML or JSON file. The "permissions" field is part of the
+repository document, so it can be combined with other repository settings in one file.`,
+	}
+
+	cmd.AddCommand(
+		GetPermissionCmd(),
+		GrantPermissionCmd(),
+		RevokePermissionCmd(),
+		SetPermissionCmd(),
+		DiffPermissionCmd(),
+	)
+
+	return cmd
+}
+
+const inputHelp = `Path to YAML or JSON file with repository permissions, or "-" to read from stdin.
+Example file content:
+  repositories:
+    - projectKey: PRJ
+      repositorySlug: repo1
+      permissions:
+        users:
+          - name: user1
+            permission: REPO_WRITE
+        groups:
+          - name: developers
+            permission: REPO_READ`
+
+// permissionRow is one line of plain output
+type permissionRow struct {
+	ProjectKey     string `json:"projectKey" yaml:"projectKey"`
+	RepositorySlug string `json:"repositorySlug" yaml:"repositorySlug"`
+	Type           string `json:"type" yaml:"type"`
+	Name           string `json:"name" yaml:"name"`
+	Permission     string `json:"permission" yaml:"permission"`
+}
+
+// parseRepos builds the repository list from flags or from an input file.
+// Permissions from flags apply to every repository in --repositorySlug.
+func parseRepos(repositorySlug, users, groups, permission, input string, requirePermission bool) ([]models.ExtendedRepository, error) {
+	if (repositorySlug != "" && input != "") || (repositorySlug == "" && input == "") {
+		return nil, fmt.Errorf("either --repositorySlug or --input must be specified, but not both")
+	}
+
+	repos, err := utils.ParseRepositoriesFromArgs(repositorySlug, input)
+	if err != nil {
+		return nil, err
+	}
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("no repositories found")
+	}
+
+	if repositorySlug != "" && (users != "" || groups != "") {
+		for i := range repos {
+			repos[i].Permissions = &models.Permissions{
+				Users:  entries(users, permission),
+				Groups: entries(groups, permission),
+			}
+		}
+	}
+
+	for i := range repos {
+		if repos[i].ProjectKey == "" || repos[i].RepositorySlug == "" {
+			return nil, fmt.Errorf("repository at index %d is missing required field 'projectKey' or 'repositorySlug'", i)
+		}
+		if err := utils.NormalizePermissions(repos[i].Permissions, utils.RepoPermissions, requirePermission); err != nil {
+			return nil, fmt.Errorf("repository %s/%s: %w", repos[i].ProjectKey, repos[i].RepositorySlug, err)
+		}
+	}
+	return repos, nil
+}
+
+// entries builds permission entries from comma-separated names, nil if names is empty
+func entries(names, permission string) []models.PermissionEntry {
+	var result []models.PermissionEntry
+	for _, n := range strings.Split(names, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			result = append(result, models.PermissionEntry{Name: n, Permission: permission})
+		}
+	}
+	return result
+}
+
+// hasEntries reports whether any repository lists a user or group
+func hasEntries(repos []models.ExtendedRepository) bool {
+	for _, r := range repos {
+		if r.Permissions != nil && (len(r.Permissions.Users) > 0 || len(r.Permissions.Groups) > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// toRows flattens repository permissions for plain output
+func toRows(repos []models.ExtendedRepository) []permissionRow {
+	var rows []permissionRow
+	for _, r := range repos {
+		if r.Permissions == nil {
+			continue
+		}
+		for _, e := range r.Permissions.Users {
+			rows = append(rows, permissionRow{ProjectKey: r.ProjectKey, RepositorySlug: r.RepositorySlug, Type: "user", Name: e.Name, Permission: e.Permission})
+		}
+		for _, e := range r.Permissions.Groups {
+			rows = append(rows, permissionRow{ProjectKey: r.ProjectKey, RepositorySlug: r.RepositorySlug, Type: "group", Name: e.Name, Permission: e.Permission})
+		}
+	}
+	return rows
+}
+
+// printRepos prints repositories as a YAML/JSON document or as plain rows
+func printRepos(repos []models.ExtendedRepository, output string) error {
+	if utils.IsTable(output) {
+		return utils.PrintStructured("repositories", toRows(repos), output, "projectKey,repositorySlug,type,name,permission")
+	}
+	return utils.PrintStructured("repositories", repos, output, "")
+}
+
+// addFlags registers the flags shared by grant, revoke, set and diff
+func addFlags(cmd *cobra.Command, repositorySlug, users, groups, permission, input *string) {
+	cmd.Flags().StringVarP(repositorySlug, "repositorySlug", "s", "", "Repository identifiers in format <projectKey>/<repositorySlug>, multiple repositories can be comma-separated")
+	cmd.Flags().StringVar(users, "user", "", "Comma-separated usernames")
+	cmd.Flags().StringVar(groups, "group", "", "Comma-separated group names")
+	cmd.Flags().StringVar(permission, "permission", "", "Permission for --user and --group: "+strings.Join(utils.RepoPermissions, "|"))
+	cmd.Flags().StringVarP(input, "input", "i", "", inputHelp)
+}
@@ -0,0 +1,65 @@
+package permission
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
+)
+
+func RevokePermissionCmd() *cobra.Command {
+	var (
+		repositorySlug string
+		users          string
+		groups         string
+		permission     string
+		input          string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke all repository permissions of users and groups",
+		Long: `Revoke all explicit permissions of users and groups on repositories.
+The permission field of the input file is ignored.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if repositorySlug != "" && users == "" && groups == "" {
+				return fmt.Errorf("--user or --group is required when using --repositorySlug")
+			}
+			repos, err := parseRepos(repositorySlug, users, groups, permission, input, false)
+			if err != nil {
+				return err
+			}
+			if !hasEntries(repos) {
+				return fmt.Errorf("no permissions to revoke")
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			if err := utils.ConfirmDelete(config.KindPermission, revokeTargets(repos)); err != nil {
+				return err
+			}
+
+			return client.RevokeRepoPermissions(cmd.Context(), repos)
+		},
+	}
+
+	addFlags(cmd, &repositorySlug, &users, &groups, &permission, &input)
+	cmd.Flags().Lookup("permission").Usage = "Ignored, all permissions are revoked"
+
+	return cmd
+}
+
+// revokeTargets lists the permissions to revoke for ConfirmDelete
+func revokeTargets(repos []models.ExtendedRepository) []string {
+	var targets []string
+	for _, r := range repos {
+		targets = append(targets, utils.PermissionTargets(r.ProjectKey+"/"+r.RepositorySlug, r.Permissions)...)
+	}
+	return targets
+}
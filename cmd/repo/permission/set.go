@@ -0,0 +1,129 @@
+package permission
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
+)
+
+const setHelp = `The given users and groups become the exact list of explicit permissions:
+missing ones are granted, changed ones are updated and unlisted ones are revoked.
+Users and groups are managed separately: if a repository lists only users, its group
+permissions are left untouched. Use "users: []" to revoke all user permissions.`
+
+func SetPermissionCmd() *cobra.Command {
+	var (
+		repositorySlug string
+		users          string
+		groups         string
+		permission     string
+		input          string
+		output         string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set the exact permissions of repositories",
+		Long:  "Set the exact user and group permissions of repositories.\n\n" + setHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if repositorySlug != "" && users == "" && groups == "" {
+				return fmt.Errorf("--user or --group is required when using --repositorySlug")
+			}
+			repos, err := parseRepos(repositorySlug, users, groups, permission, input, true)
+			if err != nil {
+				return err
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			grant, revoke, drift, err := repoDrift(cmd.Context(), client, repos)
+			if err != nil {
+				return err
+			}
+			if len(drift) == 0 {
+				client.Logger.Info("No changes, permissions are in sync")
+				return nil
+			}
+
+			if err := utils.ConfirmDelete(config.KindPermission, revokeTargets(revoke)); err != nil {
+				return err
+			}
+
+			var failed bool
+			if err := client.GrantRepoPermissions(cmd.Context(), grant); err != nil {
+				client.Logger.Error(err.Error())
+				failed = true
+			}
+			if err := client.RevokeRepoPermissions(cmd.Context(), revoke); err != nil {
+				client.Logger.Error(err.Error())
+				failed = true
+			}
+
+			if err := printDrift(drift, output); err != nil {
+				return err
+			}
+			if failed {
+				return fmt.Errorf("some permission changes failed, see log")
+			}
+			return nil
+		},
+	}
+
+	addFlags(cmd, &repositorySlug, &users, &groups, &permission, &input)
+	cmd.Flags().StringVarP(&output, "output", "o", "plain", "Output format of the changes: plain|yaml|json")
+
+	return cmd
+}
+
+// repoDrift compares the desired permissions with Bitbucket and returns
+// the permissions to grant and revoke together with the per-repository changes
+func repoDrift(ctx context.Context, client *bitbucket.Client, desired []models.ExtendedRepository) (grant, revoke []models.ExtendedRepository, drift []models.PermissionDrift, err error) {
+	current, err := client.GetRepoPermissions(ctx, desired)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get current permissions: %w", err)
+	}
+	currentByRepo := make(map[string]*models.Permissions, len(current))
+	for _, r := range current {
+		currentByRepo[repoKey(r)] = r.Permissions
+	}
+
+	for _, r := range desired {
+		if r.Permissions == nil {
+			continue
+		}
+		cur := currentByRepo[repoKey(r)]
+		if cur == nil {
+			cur = &models.Permissions{}
+		}
+		g, rv, changes := utils.DiffPermissions(*cur, *r.Permissions)
+		if len(changes) == 0 {
+			continue
+		}
+		grant = append(grant, models.ExtendedRepository{ProjectKey: r.ProjectKey, RepositorySlug: r.RepositorySlug, Permissions: &g})
+		revoke = append(revoke, models.ExtendedRepository{ProjectKey: r.ProjectKey, RepositorySlug: r.RepositorySlug, Permissions: &rv})
+		drift = append(drift, models.PermissionDrift{ProjectKey: r.ProjectKey, RepositorySlug: r.RepositorySlug, Changes: changes})
+	}
+	return grant, revoke, drift, nil
+}
+
+// repoKey identifies a repository case-insensitively
+func repoKey(r models.ExtendedRepository) string {
+	return strings.ToUpper(r.ProjectKey) + "/" + strings.ToLower(r.RepositorySlug)
+}
+
+// printDrift prints the per-repository permission changes
+func printDrift(drift []models.PermissionDrift, output string) error {
+	if err := utils.PrintStructured("repositories", drift, output, "projectKey,repositorySlug,changes.type,changes.name,changes.from,changes.to"); err != nil {
+		return fmt.Errorf("failed to print output: %w", err)
+	}
+	return nil
+}
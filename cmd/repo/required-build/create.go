@@ -1,7 +1,6 @@
 package requiredbuild
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -32,7 +31,7 @@ so make sure to use unique names to avoid confusion or accidental overwrites.`,
 			}
 
 			if len(parsed.Repositories) == 0 {
-				client, _ := bitbucket.NewClient(context.Background())
+				client, _ := bitbucket.NewClient()
 				if client != nil {
 					client.Logger.Info("no required-builds found in file", "file", input)
 				}
@@ -50,12 +49,12 @@ so make sure to use unique names to avoid confusion or accidental overwrites.`,
 				return fmt.Errorf("no required-builds defined in file %s", input)
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
 
-			updatedRepos, err := client.CreateRequiredBuilds(parsed.Repositories)
+			updatedRepos, err := client.CreateRequiredBuilds(cmd.Context(), parsed.Repositories)
 			if err != nil {
 				client.Logger.Error(err.Error())
 			}
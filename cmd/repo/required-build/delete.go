@@ -1,7 +1,6 @@
 package requiredbuild
 
 import (
-	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -41,7 +40,7 @@ func DeleteRequiredBuildCmd() *cobra.Command {
 				}
 
 				if len(parsed.Repositories) == 0 {
-					client, _ := bitbucket.NewClient(context.Background())
+					client, _ := bitbucket.NewClient()
 					if client != nil {
 						client.Logger.Info("no repositories found in file", "file", input)
 					}
@@ -77,12 +76,12 @@ func DeleteRequiredBuildCmd() *cobra.Command {
 				}
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
 
-			err = client.DeleteRequiredBuilds(repositories)
+			err = client.DeleteRequiredBuilds(cmd.Context(), repositories)
 			if err != nil {
 				client.Logger.Error(err.Error())
 			}
@@ -1,7 +1,6 @@
 package requiredbuild
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -52,22 +51,22 @@ Notes:
 				if err != nil {
 					return fmt.Errorf("failed to read rollback file: %w", err)
 				}
-				client, err := bitbucket.NewClient(context.Background())
+				client, err := bitbucket.NewClient()
 				if err != nil {
 					return err
 				}
 				if len(plan.Delete) > 0 {
-					if err := client.DeleteRequiredBuilds(plan.Delete); err != nil {
+					if err := client.DeleteRequiredBuilds(cmd.Context(), plan.Delete); err != nil {
 						return fmt.Errorf("rollback delete failed: %w", err)
 					}
 				}
 				if len(plan.Update) > 0 {
-					if _, err := client.UpdateRequiredBuilds(plan.Update); err != nil {
+					if _, err := client.UpdateRequiredBuilds(cmd.Context(), plan.Update); err != nil {
 						return fmt.Errorf("rollback update failed: %w", err)
 					}
 				}
 				if len(plan.Create) > 0 {
-					if _, err := client.CreateRequiredBuilds(plan.Create); err != nil {
+					if _, err := client.CreateRequiredBuilds(cmd.Context(), plan.Create); err != nil {
 						return fmt.Errorf("rollback create failed: %w", err)
 					}
 				}
@@ -162,21 +161,21 @@ Notes:
 			}
 
 			if apply {
-				client, err := bitbucket.NewClient(context.Background())
+				client, err := bitbucket.NewClient()
 				if err != nil {
 					return err
 				}
 
 				// Apply in order: delete -> update -> create
 				if len(diff.Delete) > 0 {
-					if err := client.DeleteRequiredBuilds(diff.Delete); err != nil {
+					if err := client.DeleteRequiredBuilds(cmd.Context(), diff.Delete); err != nil {
 						return fmt.Errorf("apply delete failed: %w", err)
 					}
 				}
 
 				var updatedRepos []models.ExtendedRepository
 				if len(diff.Update) > 0 {
-					updatedRepos, err = client.UpdateRequiredBuilds(diff.Update)
+					updatedRepos, err = client.UpdateRequiredBuilds(cmd.Context(), diff.Update)
 					if err != nil {
 						return fmt.Errorf("apply update failed: %w", err)
 					}
@@ -184,7 +183,7 @@ Notes:
 
 				var createdRepos []models.ExtendedRepository
 				if len(diff.Create) > 0 {
-					createdRepos, err = client.CreateRequiredBuilds(diff.Create)
+					createdRepos, err = client.CreateRequiredBuilds(cmd.Context(), diff.Create)
 					if err != nil {
 						return fmt.Errorf("apply create failed: %w", err)
 					}
@@ -1,7 +1,6 @@
 package requiredbuild
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -28,19 +27,19 @@ func UpdateRequiredBuildCmd() *cobra.Command {
 			}
 
 			if len(parsed.Repositories) == 0 {
-				client, _ := bitbucket.NewClient(context.Background())
+				client, _ := bitbucket.NewClient()
 				if client != nil {
 					client.Logger.Info("no repositories found in file", "file", input)
 				}
 				return nil
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
 
-			updated, err := client.UpdateRequiredBuilds(parsed.Repositories)
+			updated, err := client.UpdateRequiredBuilds(cmd.Context(), parsed.Repositories)
 			if err != nil {
 				client.Logger.Error(err.Error())
 			}
@@ -1,7 +1,6 @@
 package reviewergroup
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -31,7 +30,7 @@ Reviewer groups can be used to set default reviewers for pull requests.`,
 				return fmt.Errorf("failed to parse input file: %w", err)
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -53,7 +52,7 @@ Reviewer groups can be used to set default reviewers for pull requests.`,
 				return fmt.Errorf("no reviewer groups defined in file %s", input)
 			}
 
-			updatedRepos, err := client.CreateReviewerGroups(parsed.Repositories)
+			updatedRepos, err := client.CreateReviewerGroups(cmd.Context(), parsed.Repositories)
 			if err != nil {
 				client.Logger.Error(err.Error())
 			}
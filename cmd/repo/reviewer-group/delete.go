@@ -1,7 +1,6 @@
 package reviewergroup
 
 import (
-	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -41,7 +40,7 @@ func DeleteReviewerGroupCmd() *cobra.Command {
 				}
 
 				if len(parsed.Repositories) == 0 {
-					client, _ := bitbucket.NewClient(context.Background())
+					client, _ := bitbucket.NewClient()
 					if client != nil {
 						client.Logger.Info("no repositories found in file", "file", input)
 					}
@@ -77,12 +76,12 @@ func DeleteReviewerGroupCmd() *cobra.Command {
 				}
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
 
-			_, err = client.DeleteReviewerGroups(repositories)
+			_, err = client.DeleteReviewerGroups(cmd.Context(), repositories)
 			if err != nil {
 				client.Logger.Error(err.Error())
 			}
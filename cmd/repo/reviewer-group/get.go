@@ -1,7 +1,6 @@
 package reviewergroup
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
@@ -15,6 +14,7 @@ func GetReviewerGroupCmd() *cobra.Command {
 	var (
 		repositorySlug string
 		output         string
+		columns        string
 		input          string
 	)
 	cmd := &cobra.Command{
@@ -22,7 +22,7 @@ func GetReviewerGroupCmd() *cobra.Command {
 		Short: "Get list of reviewer groups for repository",
 		Long:  "Get all reviewer groups configured for specified repositories",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -56,19 +56,20 @@ func GetReviewerGroupCmd() *cobra.Command {
 				}
 			}
 
-			values, err := client.GetReviewerGroups(repositories)
+			values, err := client.GetReviewerGroups(cmd.Context(), repositories)
 			if err != nil {
 				client.Logger.Error(err.Error())
 				return nil
 			}
 
-			return utils.PrintStructured("repositories", values, output, "projectKey,repositorySlug,reviewerGroups.id,reviewerGroups.name")
+			return utils.PrintStructured("repositories", values, output, columns)
 
 		},
 	}
 
 	cmd.Flags().StringVarP(&repositorySlug, "repositorySlug", "s", "", "Repository identifiers in format <projectKey>/<repositorySlug>, multiple repositories can be comma-separated")
-	cmd.Flags().StringVarP(&output, "output", "o", "plain", "Output format: plain|yaml|json")
+	cmd.Flags().StringVarP(&output, "output", "o", "plain", "Output format: plain|yaml|json|csv|tsv|go-template=...|go-template-file=...|jsonpath=...")
+	cmd.Flags().StringVar(&columns, "columns", "projectKey,repositorySlug,reviewerGroups.id,reviewerGroups.name", "Comma-separated fields to display for plain, csv and tsv output; a path through a list such as reviewerGroups.name gives one row per item")
 	cmd.Flags().StringVarP(&input, "input", "i", "", `Input YAML or JSON file or '-' for stdin containing repositories
 	Example:
 repositories:
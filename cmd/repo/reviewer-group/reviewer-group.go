@@ -16,6 +16,7 @@ func RepoReviewerGroupCmd() *cobra.Command {
 		CreateReviewerGroupCmd(),
 		UpdateReviewerGroupCmd(),
 		DeleteReviewerGroupCmd(),
+		DiffReviewerGroupCmd(),
 	)
 
 	return cmd
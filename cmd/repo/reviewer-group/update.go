@@ -1,7 +1,6 @@
 package reviewergroup
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -32,19 +31,19 @@ Each reviewer group must have an ID field to identify which group to update.`,
 			}
 
 			if len(parsed.Repositories) == 0 {
-				client, _ := bitbucket.NewClient(context.Background())
+				client, _ := bitbucket.NewClient()
 				if client != nil {
 					client.Logger.Info("no repositories found in file", "file", input)
 				}
 				return nil
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
 
-			updated, err := client.UpdateReviewerGroups(parsed.Repositories)
+			updated, err := client.UpdateReviewerGroups(cmd.Context(), parsed.Repositories)
 			if err != nil {
 				client.Logger.Error(err.Error())
 			}
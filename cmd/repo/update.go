@@ -1,7 +1,6 @@
 package repo
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
@@ -34,7 +33,7 @@ or multiple repositories defined in a YAML file with --input.`,
 				return fmt.Errorf("either --input or --repositorySlug must be specified, but not both")
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -47,7 +46,7 @@ or multiple repositories defined in a YAML file with --input.`,
 				if err := utils.ParseFile(input, &parsed); err != nil {
 					return err
 				}
-				updatedRepos, err := client.UpdateRepos(parsed.Repositories)
+				updatedRepos, err := client.UpdateRepos(cmd.Context(), parsed.Repositories)
 				if err != nil {
 					client.Logger.Error(err.Error())
 				}
@@ -85,7 +84,7 @@ or multiple repositories defined in a YAML file with --input.`,
 			if defaultBranch != "" {
 				repo.RestRepository.DefaultBranch = &defaultBranch
 			}
-			updatedRepos, err := client.UpdateRepos([]models.ExtendedRepository{repo})
+			updatedRepos, err := client.UpdateRepos(cmd.Context(), []models.ExtendedRepository{repo})
 
 			if err != nil {
 				client.Logger.Error(err.Error())
@@ -1,7 +1,6 @@
 package webhook
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -32,7 +31,7 @@ so make sure to use unique names to avoid confusion or accidental overwrites.`,
 				return fmt.Errorf("failed to parse input file: %w", err)
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -54,7 +53,7 @@ so make sure to use unique names to avoid confusion or accidental overwrites.`,
 				return fmt.Errorf("no webhooks defined in file %s", input)
 			}
 
-			updatedRepos, err := client.CreateWebhooks(parsed.Repositories)
+			updatedRepos, err := client.CreateWebhooks(cmd.Context(), parsed.Repositories)
 			if err != nil {
 				client.Logger.Error(err.Error())
 			}
@@ -1,7 +1,6 @@
 package webhook
 
 import (
-	"context"
 	"fmt"
 	"math"
 	"strconv"
@@ -10,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/vinisman/bbctl/internal/bitbucket"
 	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/internal/state"
 	"github.com/vinisman/bbctl/utils"
 	"github.com/vinisman/bitbucket-sdk-go/openapi"
 )
@@ -42,7 +42,7 @@ func DeleteWebHookCmd() *cobra.Command {
 				}
 
 				if len(parsed.Repositories) == 0 {
-					client, _ := bitbucket.NewClient(context.Background())
+					client, _ := bitbucket.NewClient()
 					if client != nil {
 						client.Logger.Info("no repositories found in file", "file", input)
 					}
@@ -82,12 +82,16 @@ func DeleteWebHookCmd() *cobra.Command {
 				}
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
 
-			err = client.DeleteWebhooks(repositories)
+			if err := state.BackupBeforeDelete(cmd.Context(), client, "webhook-delete", repositories, false); err != nil {
+				return err
+			}
+
+			err = client.DeleteWebhooks(cmd.Context(), repositories)
 			if err != nil {
 				client.Logger.Error(err.Error())
 			}
@@ -1,7 +1,6 @@
 package webhook
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -47,22 +46,22 @@ Options:
 				if err != nil {
 					return fmt.Errorf("failed to read rollback file: %w", err)
 				}
-				client, err := bitbucket.NewClient(context.Background())
+				client, err := bitbucket.NewClient()
 				if err != nil {
 					return err
 				}
 				if len(plan.Delete) > 0 {
-					if err := client.DeleteWebhooks(plan.Delete); err != nil {
+					if err := client.DeleteWebhooks(cmd.Context(), plan.Delete); err != nil {
 						return fmt.Errorf("rollback delete failed: %w", err)
 					}
 				}
 				if len(plan.Update) > 0 {
-					if _, err := client.UpdateWebhooks(plan.Update); err != nil {
+					if _, err := client.UpdateWebhooks(cmd.Context(), plan.Update); err != nil {
 						return fmt.Errorf("rollback update failed: %w", err)
 					}
 				}
 				if len(plan.Create) > 0 {
-					if _, err := client.CreateWebhooks(plan.Create); err != nil {
+					if _, err := client.CreateWebhooks(cmd.Context(), plan.Create); err != nil {
 						return fmt.Errorf("rollback create failed: %w", err)
 					}
 				}
@@ -121,20 +120,20 @@ Options:
 			}
 
 			if apply {
-				client, err := bitbucket.NewClient(context.Background())
+				client, err := bitbucket.NewClient()
 				if err != nil {
 					return err
 				}
 
 				if len(diff.Delete) > 0 {
-					if err := client.DeleteWebhooks(diff.Delete); err != nil {
+					if err := client.DeleteWebhooks(cmd.Context(), diff.Delete); err != nil {
 						return fmt.Errorf("apply delete failed: %w", err)
 					}
 				}
 
 				var updatedRepos []models.ExtendedRepository
 				if len(diff.Update) > 0 {
-					updatedRepos, err = client.UpdateWebhooks(diff.Update)
+					updatedRepos, err = client.UpdateWebhooks(cmd.Context(), diff.Update)
 					if err != nil {
 						return fmt.Errorf("apply update failed: %w", err)
 					}
@@ -142,7 +141,7 @@ Options:
 
 				var createdRepos []models.ExtendedRepository
 				if len(diff.Create) > 0 {
-					createdRepos, err = client.CreateWebhooks(diff.Create)
+					createdRepos, err = client.CreateWebhooks(cmd.Context(), diff.Create)
 					if err != nil {
 						return fmt.Errorf("apply create failed: %w", err)
 					}
@@ -1,7 +1,6 @@
 package webhook
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
@@ -15,13 +14,14 @@ func GetWebHookCmd() *cobra.Command {
 	var (
 		repositorySlug string
 		output         string
+		columns        string
 		input          string
 	)
 	cmd := &cobra.Command{
 		Use:   "get",
 		Short: "Get list of webhooks for repository",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -55,19 +55,20 @@ func GetWebHookCmd() *cobra.Command {
 				}
 			}
 
-			values, err := client.GetWebhooks(repositories)
+			values, err := client.GetWebhooks(cmd.Context(), repositories)
 			if err != nil {
 				client.Logger.Error(err.Error())
 				return nil
 			}
 
-			return utils.PrintStructured("repositories", values, output, "projectKey,repositorySlug,webhooks.id,webhooks.name")
+			return utils.PrintStructured("repositories", values, output, columns)
 
 		},
 	}
 
 	cmd.Flags().StringVarP(&repositorySlug, "repositorySlug", "s", "", "Repository identifiers in format <projectKey>/<repositorySlug>, multiple repositories can be comma-separated")
-	cmd.Flags().StringVarP(&output, "output", "o", "plain", "Output format: plain|yaml|json")
+	cmd.Flags().StringVarP(&output, "output", "o", "plain", "Output format: plain|yaml|json|csv|tsv|go-template=...|go-template-file=...|jsonpath=...")
+	cmd.Flags().StringVar(&columns, "columns", "projectKey,repositorySlug,webhooks.id,webhooks.name", "Comma-separated fields to display for plain, csv and tsv output; a path through a list such as webhooks.name gives one row per item")
 	cmd.Flags().StringVarP(&input, "input", "i", "", `Input YAML or JSON file or '-' for stdin containing repositories
 	Example:
 repositories:
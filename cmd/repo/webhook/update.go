@@ -1,7 +1,6 @@
 package webhook
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -29,19 +28,19 @@ func UpdateWebHookCmd() *cobra.Command {
 			}
 
 			if len(parsed.Repositories) == 0 {
-				client, _ := bitbucket.NewClient(context.Background())
+				client, _ := bitbucket.NewClient()
 				if client != nil {
 					client.Logger.Info("no repositories found in file", "file", input)
 				}
 				return nil
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
 
-			updated, err := client.UpdateWebhooks(parsed.Repositories)
+			updated, err := client.UpdateWebhooks(cmd.Context(), parsed.Repositories)
 			if err != nil {
 				client.Logger.Error(err.Error())
 			}
@@ -1,10 +1,9 @@
 package workzone
 
 import (
-	"context"
-
 	"github.com/spf13/cobra"
 	bb "github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/state"
 	wz "github.com/vinisman/bbctl/internal/workzone"
 	"github.com/vinisman/bbctl/utils"
 )
@@ -31,7 +30,7 @@ Sections (plugin tab names):
 Notes:
   - For delete operations, payload is not required; repo list can be provided via --repositorySlug or --input.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := bb.NewClient(context.Background())
+			client, err := bb.NewClient()
 			if err != nil {
 				return err
 			}
@@ -54,7 +53,11 @@ Notes:
 				SectionMergerules: {execute: wzClient.DeleteReposAutomergers, message: "deleted mergerules"},
 			}
 
-			executeSections(client.Logger, repos, normalized, operations)
+			if err := state.BackupBeforeDelete(cmd.Context(), client, "workzone-delete", repos, true); err != nil {
+				return err
+			}
+
+			executeSections(cmd.Context(), client.Logger, repos, normalized, operations)
 			return nil
 		},
 	}
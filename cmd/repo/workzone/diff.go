@@ -0,0 +1,364 @@
+package workzone
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/spf13/cobra"
+	bb "github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/models"
+	wz "github.com/vinisman/bbctl/internal/workzone"
+	"github.com/vinisman/bbctl/utils"
+	wzsdk "github.com/vinisman/workzone-sdk-go/client"
+)
+
+var (
+	diffSource       string
+	diffTarget       string
+	diffSections     []string
+	diffOutput       string
+	diffApply        bool
+	diffRollbackOut  string
+	diffRollbackFile string
+)
+
+// WorkzoneBranchChange describes a change of a single branch entry within a section
+type WorkzoneBranchChange struct {
+	Branch string `json:"branch" yaml:"branch"`
+	Change string `json:"change" yaml:"change"` // added, changed or removed
+}
+
+// WorkzoneSectionDiff describes the changes of one section and the action --apply takes for it
+type WorkzoneSectionDiff struct {
+	Section  string                 `json:"section" yaml:"section"`
+	Action   string                 `json:"action" yaml:"action"` // set or delete
+	Fields   []string               `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Branches []WorkzoneBranchChange `json:"branches,omitempty" yaml:"branches,omitempty"`
+}
+
+// WorkzoneRepoDiff lists changed sections of a repository
+type WorkzoneRepoDiff struct {
+	ProjectKey     string                `json:"projectKey" yaml:"projectKey"`
+	RepositorySlug string                `json:"repositorySlug" yaml:"repositorySlug"`
+	Sections       []WorkzoneSectionDiff `json:"sections" yaml:"sections"`
+}
+
+// workzoneDiff holds the printable diff together with the payloads to apply and to roll back.
+// In Apply and Rollback, Update holds sections to set and Delete holds sections to remove.
+type workzoneDiff struct {
+	Repositories []WorkzoneRepoDiff
+	Apply        models.RepoDiff
+	Rollback     models.RollbackPlan
+}
+
+// DiffWorkzoneCmd compares Workzone settings between two files and optionally applies the difference
+func DiffWorkzoneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare Workzone settings between two files and generate/apply diff",
+		Long: `Compare Workzone settings between two YAML/JSON files produced by "repo workzone get"
+and show per-branch differences for the selected sections.
+
+Sections (plugin tab names):
+  - properties: repository workflow properties (changed fields are listed)
+  - reviewers: branch reviewers list
+  - signatures: branch sign approvers list
+  - mergerules: branch automergers list
+
+Notes:
+  - SOURCE is the current state, TARGET the desired state.
+  - Branch entries are matched by refName/refPattern (and srcRefName/srcRefPattern when set).
+  - A selected section missing or empty in TARGET is deleted on --apply.
+  - Repositories missing from TARGET are left untouched.
+  - Workzone replaces a whole section on set, so --apply sets every changed section
+    of every changed repository from TARGET; unchanged repositories are not touched.
+
+Options:
+  - --apply: execute the diff against Bitbucket (delete, then set)
+  - --apply-rollback-out: save a rollback file with the previous (SOURCE) sections after successful --apply
+  - --rollback: execute a rollback file (restores the previous sections)
+
+Examples:
+  bbctl repo workzone diff --source wz_v1.yaml --target wz_v2.yaml --section reviewers
+  bbctl repo workzone diff -s wz_v1.yaml -t wz_v2.yaml --section all --apply --apply-rollback-out rollback-wz.yaml
+  bbctl repo workzone diff --rollback rollback-wz.yaml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if diffOutput != "yaml" && diffOutput != "json" {
+				return fmt.Errorf("invalid output format: %s, allowed values: yaml, json", diffOutput)
+			}
+
+			if diffRollbackFile != "" {
+				if diffApply {
+					return fmt.Errorf("--rollback cannot be combined with --apply")
+				}
+				plan, err := utils.ReadRollbackPlan(diffRollbackFile)
+				if err != nil {
+					return fmt.Errorf("failed to read rollback file: %w", err)
+				}
+				client, err := bb.NewClient()
+				if err != nil {
+					return err
+				}
+				if err := wz.NewClient(client).ApplyReposDiff(cmd.Context(), models.RepoDiff{Update: plan.Update, Delete: plan.Delete}); err != nil {
+					return fmt.Errorf("rollback failed: %w", err)
+				}
+				return utils.PrintStructured("rollback", plan, diffOutput, "")
+			}
+
+			if diffSource == "" || diffTarget == "" {
+				return fmt.Errorf("both --source and --target are required")
+			}
+			if diffRollbackOut != "" && !diffApply {
+				return fmt.Errorf("--apply-rollback-out can only be used together with --apply")
+			}
+
+			normalized, err := normalizeSections(diffSections, false)
+			if err != nil {
+				return err
+			}
+
+			var parsedSource models.RepositoryYaml
+			if err := utils.ParseFile(diffSource, &parsedSource); err != nil {
+				return fmt.Errorf("failed to parse source file: %w", err)
+			}
+			var parsedTarget models.RepositoryYaml
+			if err := utils.ParseFile(diffTarget, &parsedTarget); err != nil {
+				return fmt.Errorf("failed to parse target file: %w", err)
+			}
+
+			diff := generateWorkzoneDiff(parsedSource.Repositories, parsedTarget.Repositories, normalized)
+
+			if !diffApply || len(diff.Repositories) == 0 {
+				return utils.PrintStructured("diff", diff.Repositories, diffOutput, "")
+			}
+
+			client, err := bb.NewClient()
+			if err != nil {
+				return err
+			}
+			if err := wz.NewClient(client).ApplyReposDiff(cmd.Context(), diff.Apply); err != nil {
+				return fmt.Errorf("apply failed: %w", err)
+			}
+
+			if diffRollbackOut != "" {
+				if err := utils.WriteRollbackPlan(diffRollbackOut, diffOutput, &diff.Rollback); err != nil {
+					return fmt.Errorf("failed to write rollback plan: %w", err)
+				}
+			}
+
+			return utils.PrintStructured("apply", diff.Repositories, diffOutput, "")
+		},
+	}
+
+	cmd.Flags().StringVarP(&diffSource, "source", "s", "", "Source YAML or JSON file (current state)")
+	cmd.Flags().StringVarP(&diffTarget, "target", "t", "", "Target YAML or JSON file (desired state)")
+	cmd.Flags().StringSliceVar(&diffSections, "section", []string{}, "Sections to compare (repeatable or comma-separated): properties|reviewers|signatures|mergerules|all")
+	cmd.Flags().StringVarP(&diffOutput, "output", "o", "yaml", "Output format: yaml or json")
+	cmd.Flags().BoolVarP(&diffApply, "apply", "a", false, "Apply the diff to Bitbucket: delete, then set")
+	cmd.Flags().StringVar(&diffRollbackOut, "apply-rollback-out", "", "Write rollback file to path after successful --apply (format controlled by -o)")
+	cmd.Flags().StringVar(&diffRollbackFile, "rollback", "", "Execute rollback file (restores sections changed by a previous apply)")
+
+	return cmd
+}
+
+// generateWorkzoneDiff compares selected sections of repositories present in target against source
+func generateWorkzoneDiff(source, target []models.ExtendedRepository, normalized map[string]bool) workzoneDiff {
+	res := workzoneDiff{
+		Repositories: []WorkzoneRepoDiff{},
+		Apply: models.RepoDiff{
+			Create: []models.ExtendedRepository{},
+			Update: []models.ExtendedRepository{},
+			Delete: []models.ExtendedRepository{},
+		},
+		Rollback: models.RollbackPlan{
+			Create: []models.ExtendedRepository{},
+			Update: []models.ExtendedRepository{},
+			Delete: []models.ExtendedRepository{},
+		},
+	}
+
+	type key struct{ p, r string }
+	current := make(map[key]models.WorkzoneData, len(source))
+	for _, r := range source {
+		if r.Workzone != nil {
+			current[key{r.ProjectKey, r.RepositorySlug}] = *r.Workzone
+		}
+	}
+
+	for _, r := range target {
+		cur := current[key{r.ProjectKey, r.RepositorySlug}]
+		want := models.WorkzoneData{}
+		if r.Workzone != nil {
+			want = *r.Workzone
+		}
+
+		rd := WorkzoneRepoDiff{ProjectKey: r.ProjectKey, RepositorySlug: r.RepositorySlug}
+		var set, del, undoSet, undoDel models.WorkzoneData
+
+		for _, section := range allSections {
+			if !normalized[section] {
+				continue
+			}
+			sd := WorkzoneSectionDiff{Section: section, Action: "set"}
+			switch section {
+			case SectionProperties:
+				if !reflect.DeepEqual(cur.WorkflowProperties, want.WorkflowProperties) {
+					sd.Fields = changedFields(cur.WorkflowProperties, want.WorkflowProperties)
+				}
+			case SectionReviewers:
+				sd.Branches = diffBranches(cur.Reviewers, want.Reviewers, func(it wzsdk.RestBranchReviewers) string {
+					return branchKey(it.RefName, it.RefPattern, it.SrcRefName, it.SrcRefPattern)
+				})
+			case SectionSignatures:
+				sd.Branches = diffBranches(cur.Signapprovers, want.Signapprovers, func(it wzsdk.RestBranchSignapprovers) string {
+					return branchKey(it.RefName, it.RefPattern, it.SrcRefName, it.SrcRefPattern)
+				})
+			case SectionMergerules:
+				sd.Branches = diffBranches(cur.Mergerules, want.Mergerules, func(it wzsdk.RestBranchAutoMergers) string {
+					return branchKey(it.RefName, it.RefPattern, it.SrcRefName, it.SrcRefPattern)
+				})
+			}
+			if len(sd.Fields) == 0 && len(sd.Branches) == 0 {
+				continue
+			}
+
+			if hasSection(want, section) {
+				copySection(&set, want, section)
+			} else {
+				sd.Action = "delete"
+				copySection(&del, cur, section)
+			}
+			// rollback restores the previous section, or removes a section that did not exist
+			if hasSection(cur, section) {
+				copySection(&undoSet, cur, section)
+			} else {
+				copySection(&undoDel, want, section)
+			}
+			rd.Sections = append(rd.Sections, sd)
+		}
+
+		if len(rd.Sections) == 0 {
+			continue
+		}
+		res.Repositories = append(res.Repositories, rd)
+		res.Apply.Update = appendSections(res.Apply.Update, r, set)
+		res.Apply.Delete = appendSections(res.Apply.Delete, r, del)
+		res.Rollback.Update = appendSections(res.Rollback.Update, r, undoSet)
+		res.Rollback.Delete = appendSections(res.Rollback.Delete, r, undoDel)
+	}
+
+	return res
+}
+
+// diffBranches reports added, changed and removed entries of a branch list section
+func diffBranches[T any](cur, want []T, key func(T) string) []WorkzoneBranchChange {
+	curByKey := make(map[string]T, len(cur))
+	for _, it := range cur {
+		curByKey[key(it)] = it
+	}
+	wantKeys := make(map[string]bool, len(want))
+
+	var changes []WorkzoneBranchChange
+	for _, it := range want {
+		k := key(it)
+		wantKeys[k] = true
+		old, ok := curByKey[k]
+		switch {
+		case !ok:
+			changes = append(changes, WorkzoneBranchChange{Branch: k, Change: "added"})
+		case !reflect.DeepEqual(old, it):
+			changes = append(changes, WorkzoneBranchChange{Branch: k, Change: "changed"})
+		}
+	}
+	for _, it := range cur {
+		if k := key(it); !wantKeys[k] {
+			changes = append(changes, WorkzoneBranchChange{Branch: k, Change: "removed"})
+		}
+	}
+	return changes
+}
+
+// branchKey identifies a branch entry by its target ref and optional source ref
+func branchKey(refName, refPattern, srcRefName, srcRefPattern *string) string {
+	k := utils.SafeValue(refName)
+	if k == "" {
+		k = utils.SafeValue(refPattern)
+	}
+	src := utils.SafeValue(srcRefName)
+	if src == "" {
+		src = utils.SafeValue(srcRefPattern)
+	}
+	if src != "" {
+		k = src + " -> " + k
+	}
+	return k
+}
+
+// changedFields returns sorted JSON field names whose values differ between a and b
+func changedFields(a, b *wzsdk.WorkflowProperties) []string {
+	toMap := func(p *wzsdk.WorkflowProperties) map[string]any {
+		m := map[string]any{}
+		if p != nil {
+			data, _ := json.Marshal(p)
+			_ = json.Unmarshal(data, &m)
+		}
+		return m
+	}
+	am, bm := toMap(a), toMap(b)
+
+	var fields []string
+	for k, v := range am {
+		if !reflect.DeepEqual(v, bm[k]) {
+			fields = append(fields, k)
+		}
+	}
+	for k := range bm {
+		if _, ok := am[k]; !ok {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// hasSection reports whether a section holds any data
+func hasSection(w models.WorkzoneData, section string) bool {
+	switch section {
+	case SectionProperties:
+		return w.WorkflowProperties != nil
+	case SectionReviewers:
+		return len(w.Reviewers) > 0
+	case SectionSignatures:
+		return len(w.Signapprovers) > 0
+	case SectionMergerules:
+		return len(w.Mergerules) > 0
+	}
+	return false
+}
+
+// copySection copies one section from src into dst
+func copySection(dst *models.WorkzoneData, src models.WorkzoneData, section string) {
+	switch section {
+	case SectionProperties:
+		dst.WorkflowProperties = src.WorkflowProperties
+	case SectionReviewers:
+		dst.Reviewers = src.Reviewers
+	case SectionSignatures:
+		dst.Signapprovers = src.Signapprovers
+	case SectionMergerules:
+		dst.Mergerules = src.Mergerules
+	}
+}
+
+// appendSections appends repo with the given sections if at least one section is set
+func appendSections(list []models.ExtendedRepository, repo models.ExtendedRepository, w models.WorkzoneData) []models.ExtendedRepository {
+	for _, section := range allSections {
+		if hasSection(w, section) {
+			data := w
+			return append(list, models.ExtendedRepository{ProjectKey: repo.ProjectKey, RepositorySlug: repo.RepositorySlug, Workzone: &data})
+		}
+	}
+	return list
+}
@@ -1,7 +1,6 @@
 package workzone
 
 import (
-	"context"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -17,6 +16,7 @@ var (
 	output    string
 	sections  []string
 	input     string
+	columns   string
 )
 
 // GetWorkzoneCmd returns a cobra command to get workzone settings for a repository
@@ -41,7 +41,7 @@ Examples:
   bbctl repo workzone get --section reviewers,mergerules --repositorySlug KEY/slug -o yaml
   bbctl repo workzone get --section reviewers --section mergerules --input repos.yaml -o json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := bb.NewClient(context.Background())
+			client, err := bb.NewClient()
 			if err != nil {
 				return err
 			}
@@ -72,28 +72,28 @@ Examples:
 			if normalized[SectionProperties] {
 				num++
 				go func() {
-					out, err := wzClient.GetRepoWorkflows(repos)
+					out, err := wzClient.GetRepoWorkflows(cmd.Context(), repos)
 					resCh <- secRes{kind: SectionProperties, out: out, err: err}
 				}()
 			}
 			if normalized[SectionReviewers] {
 				num++
 				go func() {
-					out, err := wzClient.GetReposReviewersList(repos)
+					out, err := wzClient.GetReposReviewersList(cmd.Context(), repos)
 					resCh <- secRes{kind: SectionReviewers, out: out, err: err}
 				}()
 			}
 			if normalized[SectionSignatures] {
 				num++
 				go func() {
-					out, err := wzClient.GetReposSignapprovers(repos)
+					out, err := wzClient.GetReposSignapprovers(cmd.Context(), repos)
 					resCh <- secRes{kind: SectionSignatures, out: out, err: err}
 				}()
 			}
 			if normalized[SectionMergerules] {
 				num++
 				go func() {
-					out, err := wzClient.GetReposAutomergers(repos)
+					out, err := wzClient.GetReposAutomergers(cmd.Context(), repos)
 					resCh <- secRes{kind: SectionMergerules, out: out, err: err}
 				}()
 			}
@@ -120,12 +120,16 @@ Examples:
 			if normalized[SectionMergerules] {
 				fields = append(fields, "workzone.mergerules")
 			}
-			return utils.PrintStructured("repositories", agg, output, strings.Join(fields, ","))
+			if columns == "" {
+				columns = strings.Join(fields, ",")
+			}
+			return utils.PrintStructured("repositories", agg, output, columns)
 		},
 	}
 
 	cmd.Flags().StringVarP(&repoIdent, "repositorySlug", "s", "", "Repository identifier <projectKey>/<repoSlug>")
-	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Output format: plain|yaml|json")
+	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Output format: plain|yaml|json|csv|tsv|go-template=...|go-template-file=...|jsonpath=...")
+	cmd.Flags().StringVar(&columns, "columns", "", "Comma-separated fields to display for plain, csv and tsv output, e.g. workzone.reviewers.refname,workzone.reviewers.groups (default the fetched sections)")
 	cmd.Flags().StringSliceVar(&sections, "section", []string{}, "Sections to fetch (repeatable or comma-separated, default: all): properties|reviewers|signatures|mergerules")
 	cmd.Flags().StringVarP(&input, "input", "i", "", `Input YAML or JSON file or '-' for stdin containing repositories
 Example:
@@ -1,6 +1,7 @@
 package workzone
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -52,12 +53,13 @@ func normalizeSections(sections []string, allowEmpty bool) (map[string]bool, err
 
 // sectionOperation defines an operation on a section
 type sectionOperation struct {
-	execute func([]models.ExtendedRepository) error
+	execute func(context.Context, []models.ExtendedRepository) error
 	message string // success message template (e.g., "set", "updated", "deleted")
 }
 
 // executeSections executes operations for selected sections and reports results
 func executeSections(
+	ctx context.Context,
 	logger *slog.Logger,
 	repos []models.ExtendedRepository,
 	normalized map[string]bool,
@@ -84,7 +86,11 @@ func executeSections(
 		}
 
 		totalSections++
-		if err := op.execute(repos); err != nil {
+		if ctx.Err() != nil {
+			logger.Warn("Skipped, operation interrupted", "section", section)
+			continue
+		}
+		if err := op.execute(ctx, repos); err != nil {
 			logger.Error(err.Error())
 		} else {
 			successCount++
@@ -1,7 +1,6 @@
 package workzone
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -37,7 +36,7 @@ Notes:
 				return fmt.Errorf("please specify --input with payload")
 			}
 
-			client, err := bb.NewClient(context.Background())
+			client, err := bb.NewClient()
 			if err != nil {
 				return err
 			}
@@ -60,7 +59,7 @@ Notes:
 				SectionMergerules: {execute: wzClient.SetReposAutomergers, message: "updated mergerules"},
 			}
 
-			executeSections(client.Logger, repos, normalized, operations)
+			executeSections(cmd.Context(), client.Logger, repos, normalized, operations)
 			return nil
 		},
 	}
@@ -16,6 +16,7 @@ func RepoWorkzoneCmd() *cobra.Command {
 		SetWorkzoneCmd(),
 		UpdateWorkzoneCmd(),
 		DeleteWorkzoneCmd(),
+		DiffWorkzoneCmd(),
 	)
 
 	return cmd
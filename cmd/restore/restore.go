@@ -0,0 +1,86 @@
+package restore
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/internal/state"
+	"github.com/vinisman/bbctl/utils"
+)
+
+func NewRestoreCmd() *cobra.Command {
+	var (
+		from   string
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Recreate repository settings from a pre-delete backup",
+		Long: `Recreate repositories and their settings from a backup file written before
+"repo delete", "repo webhook delete", "repo branch-permission delete" and
+"repo workzone delete" (see --backup-dir and --no-backup).
+
+The backup is applied like "bbctl apply" without deletions:
+ - Missing repositories are created empty, the content of a deleted repository
+   cannot be restored.
+ - Webhooks, required builds, branch permissions and reviewer groups are matched
+   to live items by natural key; missing ones are created, changed ones updated.
+   Settings added after the backup are kept.
+ - Workzone sections are set to their backed up values.
+
+Examples:
+  bbctl restore --from ~/.config/bbctl/backups/repo-delete-20250101-120000.000.yaml
+  bbctl --dry-run restore --from webhook-delete-20250101-120000.000.yaml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if from == "" {
+				return fmt.Errorf("--from must be specified")
+			}
+			if output != "yaml" && output != "json" {
+				return fmt.Errorf("invalid output format: %s, allowed values: yaml, json", output)
+			}
+
+			var backup models.RepositoryYaml
+			if err := utils.ParseFile(from, &backup); err != nil {
+				return fmt.Errorf("failed to parse file %s: %w", from, err)
+			}
+			if len(backup.Repositories) == 0 {
+				return fmt.Errorf("no repositories found in file %s", from)
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			plan, err := state.RestorePlan(cmd.Context(), client, backup.Repositories)
+			if err != nil {
+				return fmt.Errorf("failed to build plan: %w", err)
+			}
+			if state.CountChanges(plan) == 0 {
+				client.Logger.Info("Nothing to restore, live state matches the backup")
+				return nil
+			}
+
+			// Settings of repositories that are still to be created cannot be
+			// looked up, so a dry run prints the plan instead of previewing each item
+			if client.DryRun() {
+				client.Logger.Info("Dry run, restore plan not applied", "changes", state.CountChanges(plan))
+				return utils.PrintStructured("restore", plan, output, "")
+			}
+
+			if err := state.Apply(cmd.Context(), client, plan, ""); err != nil {
+				return err
+			}
+
+			return utils.PrintStructured("restore", plan, output, "")
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", `Backup file to restore, or "-" to read from stdin`)
+	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Output format for the restored changes: yaml or json")
+
+	return cmd
+}
@@ -5,15 +5,25 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/cmd/apply"
+	configcmd "github.com/vinisman/bbctl/cmd/config"
+	"github.com/vinisman/bbctl/cmd/dev"
+	"github.com/vinisman/bbctl/cmd/export"
 	"github.com/vinisman/bbctl/cmd/group"
+	"github.com/vinisman/bbctl/cmd/migrate"
+	"github.com/vinisman/bbctl/cmd/plan"
 	"github.com/vinisman/bbctl/cmd/project"
 	"github.com/vinisman/bbctl/cmd/repo"
+	"github.com/vinisman/bbctl/cmd/restore"
 	"github.com/vinisman/bbctl/cmd/user"
 	"github.com/vinisman/bbctl/cmd/validate"
+	"github.com/vinisman/bbctl/internal/bitbucket"
 	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/utils"
 )
 
 var (
@@ -27,6 +37,31 @@ var (
 	flagPageSize   int
 	flagMaxWorkers int
 	flagInsecure   bool
+	flagContext    string
+	flagTokenFile  string
+	flagCredProc   string
+	flagRetries    int
+	flagRetryWait  time.Duration
+	flagRateLimit  string
+	flagRateBurst  int
+	flagCAFile     string
+	flagClientCert string
+	flagClientKey  string
+	flagProxy      string
+	flagNoProxy    string
+	flagReport     string
+	flagFailedOut  string
+	flagDryRun     bool
+	flagYes        bool
+	flagMaxDeletes int
+	flagForceProt  bool
+	flagBackupDir  string
+	flagNoBackup   bool
+	flagRecordDir  string
+	flagReplayDir  string
+
+	// executedCmd is the command being run, for the dry-run summary
+	executedCmd *cobra.Command
 
 	// Version and Commit are set at build time via -ldflags
 	Version string
@@ -38,15 +73,22 @@ func NewRootCmd() *cobra.Command {
 		Use:   "bbctl",
 		Short: "Bitbucket Data Center CLI",
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			if cmd.Name() == "version" {
+			if cmd.Name() == "version" || (cmd.Parent() != nil && cmd.Parent().Name() == "config") {
 				return nil
 			}
 
+			// Initialize logger
+			level := slog.LevelInfo
+			if debug {
+				level = slog.LevelDebug
+			}
+			config.GlobalLogger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
 			// Load environment variables from .env file
 			_ = godotenv.Load()
 
-			// Load config from environment
-			c, err := config.LoadConfig()
+			// Load config from the selected context and environment
+			c, err := config.LoadConfig(flagContext)
 			if err != nil {
 				return err
 			}
@@ -76,20 +118,89 @@ func NewRootCmd() *cobra.Command {
 			if flagInsecure {
 				c.Insecure = flagInsecure
 			}
+			if flagCAFile != "" {
+				c.CAFile = flagCAFile
+			}
+			if flagClientCert != "" {
+				c.ClientCert = flagClientCert
+			}
+			if flagClientKey != "" {
+				c.ClientKey = flagClientKey
+			}
+			if flagProxy != "" {
+				c.Proxy = flagProxy
+			}
+			if cmd.Flags().Changed("no-proxy") {
+				c.NoProxy = flagNoProxy
+			}
+			if c.ClientKey != "" && c.ClientCert == "" {
+				return fmt.Errorf("--client-key requires --client-cert")
+			}
 
-			// Validate authentication
-			if c.Token == "" && (c.Username == "" || c.Password == "") {
-				return fmt.Errorf("either token or username/password must be provided")
+			if cmd.Flags().Changed("retries") {
+				if flagRetries < 0 {
+					return fmt.Errorf("--retries must not be negative")
+				}
+				c.Retries = flagRetries
+			}
+			if flagRetryWait > 0 {
+				c.RetryMaxWait = flagRetryWait
 			}
 
-			config.GlobalCfg = c
+			if flagRateLimit != "" {
+				r, err := config.ParseRateLimit(flagRateLimit)
+				if err != nil {
+					return err
+				}
+				c.RateLimit = r
+			}
+			if flagRateBurst > 0 {
+				c.RateBurst = flagRateBurst
+			}
 
-			// Initialize logger
-			level := slog.LevelInfo
-			if debug {
-				level = slog.LevelDebug
+			if flagTokenFile != "" {
+				c.TokenFile = flagTokenFile
 			}
-			config.GlobalLogger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+			if flagCredProc != "" {
+				c.CredentialProcess = flagCredProc
+			}
+
+			if flagRecordDir != "" && flagReplayDir != "" {
+				return fmt.Errorf("--record-dir and --replay-dir cannot be used together")
+			}
+			c.RecordDir = flagRecordDir
+			c.ReplayDir = flagReplayDir
+
+			// migrate connects with the credentials of its --from-context and --to-context,
+			// dev commands do not connect to Bitbucket and replayed recordings have no credentials
+			if cmd.Name() != "migrate" && (cmd.Parent() == nil || cmd.Parent().Name() != "dev") && c.ReplayDir == "" {
+				// Fill missing credentials from token file, credential process or .netrc
+				if err := c.ResolveCredentials(); err != nil {
+					return err
+				}
+
+				// Validate authentication
+				if err := c.CheckCredentials(); err != nil {
+					return err
+				}
+			}
+
+			c.DryRun = flagDryRun
+
+			if flagMaxDeletes < 0 {
+				return fmt.Errorf("--max-deletes must not be negative")
+			}
+			c.AssumeYes = flagYes
+			c.MaxDeletes = flagMaxDeletes
+			c.ForceProtected = flagForceProt
+			if flagBackupDir != "" {
+				c.BackupDir = flagBackupDir
+			}
+			c.NoBackup = flagNoBackup
+
+			config.GlobalCfg = c
+			executedCmd = cmd
+
 			return nil
 		},
 	}
@@ -98,11 +209,36 @@ func NewRootCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(&debug, "debug", false, "Enable debug mode (verbose logging)")
 	cmd.PersistentFlags().StringVar(&flagURL, "url", "", "Bitbucket Base URL (overrides BITBUCKET_BASE_URL)")
 	cmd.PersistentFlags().StringVar(&flagToken, "token", "", "Bitbucket API token (overrides BITBUCKET_TOKEN)")
+	cmd.PersistentFlags().StringVar(&flagTokenFile, "token-file", "", "File containing the Bitbucket API token (overrides BITBUCKET_TOKEN_FILE)")
+	cmd.PersistentFlags().StringVar(&flagCredProc, "credential-process", "", "Command whose output is the Bitbucket API token (overrides BITBUCKET_CREDENTIAL_PROCESS)")
 	cmd.PersistentFlags().StringVarP(&flagUsername, "username", "u", "", "Bitbucket username (overrides BITBUCKET_USERNAME)")
 	cmd.PersistentFlags().StringVarP(&flagPassword, "password", "p", "", "Bitbucket password (overrides BITBUCKET_PASSWORD)")
 	cmd.PersistentFlags().IntVar(&flagPageSize, "page-size", 0, "Page size for API requests (overrides BITBUCKET_PAGE_SIZE, default 50)")
 	cmd.PersistentFlags().IntVar(&flagMaxWorkers, "max-workers", 0, "Maximum number of concurrent workers (overrides BITBUCKET_MAX_WORKERS, default 5)")
 	cmd.PersistentFlags().BoolVar(&flagInsecure, "insecure", false, "Skip TLS certificate verification (for self-signed certificates)")
+	cmd.PersistentFlags().StringVar(&flagCAFile, "ca-file", "", "PEM file with CA certificates to trust in addition to the system ones (overrides BITBUCKET_CA_FILE)")
+	cmd.PersistentFlags().StringVar(&flagClientCert, "client-cert", "", "PEM client certificate for mTLS (overrides BITBUCKET_CLIENT_CERT)")
+	cmd.PersistentFlags().StringVar(&flagClientKey, "client-key", "", "PEM private key of --client-cert, if not in the same file (overrides BITBUCKET_CLIENT_KEY)")
+	cmd.PersistentFlags().StringVar(&flagProxy, "proxy", "", "Proxy URL for all requests (overrides BITBUCKET_PROXY, default HTTPS_PROXY/HTTP_PROXY)")
+	cmd.PersistentFlags().StringVar(&flagNoProxy, "no-proxy", "", "Comma-separated hosts, domains and CIDRs that bypass --proxy (overrides NO_PROXY)")
+	cmd.PersistentFlags().IntVar(&flagRetries, "retries", 3, "Retries for transient HTTP failures and 429 responses (overrides BITBUCKET_RETRIES)")
+	cmd.PersistentFlags().DurationVar(&flagRetryWait, "retry-max-wait", 0, "Maximum wait between retries, e.g. 10s (overrides BITBUCKET_RETRY_MAX_WAIT, default 30s)")
+	cmd.PersistentFlags().StringVar(&flagRateLimit, "rate-limit", "", "Maximum request rate shared by all workers, e.g. 10/s or 600/m (overrides BITBUCKET_RATE_LIMIT, default unlimited)")
+	cmd.PersistentFlags().IntVar(&flagRateBurst, "rate-burst", 0, "Requests allowed at once before --rate-limit applies (overrides BITBUCKET_RATE_BURST, default 1)")
+	cmd.PersistentFlags().StringVar(&flagReport, "report", "", "Write the status, HTTP code and error of every item of bulk operations to this YAML or JSON (.json) file")
+	cmd.PersistentFlags().StringVar(&flagFailedOut, "failed-out", "", "Write failed and skipped items to this file in the input format, to retry them with --input")
+	cmd.PersistentFlags().BoolVar(&flagDryRun, "dry-run", false, "Only look up the targets and print what would be created, updated or deleted, do not modify Bitbucket")
+	cmd.PersistentFlags().BoolVar(&flagYes, "yes", false, "Do not ask for confirmation before deleting (delete commands only ask when stdin is a terminal)")
+	cmd.PersistentFlags().IntVar(&flagMaxDeletes, "max-deletes", 0, "Refuse deletes and revokes with more targets than this (default unlimited)")
+	cmd.PersistentFlags().BoolVar(&flagForceProt, "force-protected", false, "Allow deleting resources matching the protected list of the context or BITBUCKET_PROTECTED")
+	cmd.PersistentFlags().StringVar(&flagBackupDir, "backup-dir", "", "Directory of the backups written before repository settings are deleted (overrides BITBUCKET_BACKUP_DIR, default backups/ next to the config file)")
+	cmd.PersistentFlags().BoolVar(&flagNoBackup, "no-backup", false, "Do not back up repository settings before deleting them")
+	cmd.PersistentFlags().StringVar(&flagRecordDir, "record-dir", "", "Write every HTTP request and response to this directory, with credentials redacted, to reproduce a run with --replay-dir")
+	cmd.PersistentFlags().StringVar(&flagReplayDir, "replay-dir", "", "Answer HTTP requests with the responses recorded by --record-dir in this directory instead of contacting Bitbucket")
+	cmd.PersistentFlags().StringVar(&flagContext, "context", "", "Context from ~/.config/bbctl/config.yaml to use (overrides BBCTL_CONTEXT and the current context)")
+
+	// Reports are written after the command has run, also when it failed
+	cobra.OnFinalize(printDryRun, writeReports)
 
 	// Add subcommands
 	cmd.AddCommand(
@@ -111,12 +247,70 @@ func NewRootCmd() *cobra.Command {
 		user.UserCmd(),
 		group.GroupCmd(),
 		validate.NewValidateCmd(),
+		plan.NewPlanCmd(),
+		apply.NewApplyCmd(),
+		restore.NewRestoreCmd(),
+		export.NewExportCmd(),
+		migrate.NewMigrateCmd(),
+		dev.NewDevCmd(),
+		configcmd.NewConfigCmd(),
 		versionCmd(),
 	)
 
 	return cmd
 }
 
+// printDryRun prints the changes a dry run would have made, unless the command
+// has printed the resulting objects as YAML or JSON already
+func printDryRun() {
+	if config.GlobalCfg == nil || !config.GlobalCfg.DryRun || executedCmd == nil {
+		return
+	}
+	if f := executedCmd.Flags().Lookup("output"); f != nil {
+		switch strings.ToLower(f.Value.String()) {
+		case "yaml", "yml", "json":
+			return
+		}
+		if utils.IsTemplate(f.Value.String()) {
+			return
+		}
+	}
+	summary := bitbucket.GlobalReport.Summary()
+	if len(summary.Items) == 0 {
+		return
+	}
+	if err := utils.PrintStructured("changes", summary.Items, "plain", "operation,kind,item,status,error"); err != nil {
+		config.GlobalLogger.Error("Failed to print dry-run changes", "error", err)
+	}
+	config.GlobalLogger.Info("Dry run, no changes were made", "planned", summary.Planned, "failed", summary.Failed)
+}
+
+// writeReports writes the --report and --failed-out files of the bulk operations
+func writeReports() {
+	logger := config.GlobalLogger
+	if logger == nil {
+		return
+	}
+	if flagReport != "" {
+		if err := bitbucket.GlobalReport.WriteReport(flagReport); err != nil {
+			logger.Error("Failed to write report", "error", err)
+		} else {
+			logger.Info("Report written", "file", flagReport)
+		}
+	}
+	if flagFailedOut != "" {
+		n, err := bitbucket.GlobalReport.WriteFailed(flagFailedOut)
+		switch {
+		case err != nil:
+			logger.Error("Failed to write failed items", "error", err)
+		case n == 0:
+			logger.Info("No failed items, nothing written", "file", flagFailedOut)
+		default:
+			logger.Info("Failed items written", "file", flagFailedOut, "count", n)
+		}
+	}
+}
+
 func versionCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "version",
@@ -1,7 +1,6 @@
 package user
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -34,7 +33,7 @@ This is required for older Bitbucket versions that do not support token-based op
 				return fmt.Errorf("either --name and --displayName or --input must be specified, but not both")
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -111,7 +110,7 @@ This is required for older Bitbucket versions that do not support token-based op
 				}
 			}
 
-			createdUsers, err := client.CreateUsers(users, passwords)
+			createdUsers, err := client.CreateUsers(cmd.Context(), users, passwords)
 			if err != nil {
 				client.Logger.Error(err.Error())
 				return nil
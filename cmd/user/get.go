@@ -1,7 +1,6 @@
 package user
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -13,10 +12,11 @@ import (
 
 func GetCmd() *cobra.Command {
 	var (
-		name   string
-		all    bool
-		output string
-		input  string
+		name    string
+		all     bool
+		output  string
+		columns string
+		input   string
 	)
 
 	cmd := &cobra.Command{
@@ -43,7 +43,7 @@ You must specify exactly one of the following options:
 				return fmt.Errorf("please specify exactly one of -n/--name, --all, or --input")
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -52,13 +52,13 @@ You must specify exactly one of the following options:
 
 			switch {
 			case all:
-				users, err = client.GetAllUsers()
+				users, err = client.GetAllUsers(cmd.Context())
 				if err != nil {
 					client.Logger.Error(err.Error())
 					return nil
 				}
 			case name != "":
-				users, err = client.GetUsers(utils.ParseColumnsToLower(name))
+				users, err = client.GetUsers(cmd.Context(), utils.ParseColumnsToLower(name))
 				if err != nil {
 					client.Logger.Error(err.Error())
 					return nil
@@ -84,14 +84,14 @@ You must specify exactly one of the following options:
 					return fmt.Errorf("no usernames found in file %s", input)
 				}
 
-				users, err = client.GetUsers(usernames)
+				users, err = client.GetUsers(cmd.Context(), usernames)
 				if err != nil {
 					client.Logger.Error(err.Error())
 					return nil
 				}
 			}
 
-			if err := utils.PrintStructured("users", users, output, "name,displayName,emailAddress,active"); err != nil {
+			if err := utils.PrintStructured("users", users, output, columns); err != nil {
 				return fmt.Errorf("failed to print output: %w", err)
 			}
 			return nil
@@ -105,9 +105,10 @@ You must specify exactly one of the following options:
 		"output",
 		"o",
 		"plain",
-		`Output format: plain|yaml|json.
+		`Output format: plain|yaml|json|csv|tsv|go-template=...|go-template-file=...|jsonpath=...
 The "yaml" and "json" formats print the full available structure with all fields.`,
 	)
+	cmd.Flags().StringVar(&columns, "columns", "name,displayName,emailAddress,active", "Comma-separated fields to display for plain, csv and tsv output")
 	cmd.Flags().StringVarP(&input, "input", "i", "", `Path to YAML or JSON file with users to get, or "-" to read from stdin.
 Example file content:
   users:
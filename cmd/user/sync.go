@@ -0,0 +1,189 @@
+package user
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/internal/state"
+	"github.com/vinisman/bbctl/utils"
+	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
+)
+
+// userSyncRow is one line of the sync summary
+type userSyncRow struct {
+	Action       string `json:"action" yaml:"action"`
+	Name         string `json:"name" yaml:"name"`
+	DisplayName  string `json:"displayName" yaml:"displayName"`
+	EmailAddress string `json:"emailAddress" yaml:"emailAddress"`
+	Result       string `json:"result" yaml:"result"`
+}
+
+func SyncCmd() *cobra.Command {
+	var (
+		input    string
+		prune    bool
+		password string
+		output   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Reconcile users with a YAML or JSON file",
+		Long: `Reconcile Bitbucket users with a YAML or JSON file that is the source of truth
+(for example an HR directory export).
+
+Users are matched by name (case-insensitive):
+ - create: users in the file that do not exist in Bitbucket (requires --user-password)
+ - update: users whose display name or email address differ (empty values in the file are ignored)
+ - delete: users in Bitbucket missing from the file; only deleted with --prune,
+   otherwise they are reported as "extra"
+
+A summary table of all changes is printed at the end. Use --dry-run to only print it.
+
+Note: If you encounter a 401 Unauthorized error, please use your username and password for authentication instead of a token.
+This is required for older Bitbucket versions that do not support token-based operations.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun := config.GlobalCfg.DryRun
+			if input == "" {
+				return fmt.Errorf("--input is required")
+			}
+
+			var parsed models.UserYaml
+			if err := utils.ParseFile(input, &parsed); err != nil {
+				return fmt.Errorf("failed to parse file %s: %w", input, err)
+			}
+			if len(parsed.Users) == 0 {
+				return fmt.Errorf("no users found in file %s", input)
+			}
+			for i, user := range parsed.Users {
+				if user.Name == "" {
+					return fmt.Errorf("user at index %d is missing required field 'name'", i)
+				}
+			}
+
+			client, err := bitbucket.NewClient()
+			if err != nil {
+				return err
+			}
+
+			live, err := client.GetAllUsers(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to fetch users: %w", err)
+			}
+			diff := state.DiffUsers(live, parsed.Users)
+
+			if len(diff.Create) > 0 && password == "" && !dryRun {
+				return fmt.Errorf("--user-password is required to create %d users", len(diff.Create))
+			}
+			var pruned []string
+			if prune {
+				for _, u := range diff.Delete {
+					pruned = append(pruned, u.Name)
+				}
+				if err := utils.ConfirmDelete(config.KindUser, pruned); err != nil {
+					return err
+				}
+			}
+
+			var rows []userSyncRow
+			var failed int
+			result := func(done map[string]bool, name string) string {
+				switch {
+				case dryRun:
+					return "planned"
+				case !done[strings.ToLower(name)]:
+					failed++
+					return "failed (see log)"
+				default:
+					return "done"
+				}
+			}
+			names := func(users []openapi.RestApplicationUser) map[string]bool {
+				m := make(map[string]bool, len(users))
+				for _, u := range users {
+					if u.Name != nil {
+						m[strings.ToLower(*u.Name)] = true
+					}
+				}
+				return m
+			}
+
+			// Create
+			var created []openapi.RestApplicationUser
+			if len(diff.Create) > 0 && !dryRun {
+				users := state.ToRestUsers(diff.Create)
+				passwords := make([]string, len(users))
+				for i := range passwords {
+					passwords[i] = password
+				}
+				// errors are logged per user; results hold only created users
+				created, _ = client.CreateUsers(cmd.Context(), users, passwords)
+			}
+			createdNames := names(created)
+			for _, u := range diff.Create {
+				rows = append(rows, userSyncRow{Action: "create", Name: u.Name, DisplayName: u.DisplayName, EmailAddress: u.EmailAddress, Result: result(createdNames, u.Name)})
+			}
+
+			// Update
+			var updated []openapi.RestApplicationUser
+			if len(diff.Update) > 0 && !dryRun {
+				updated, _ = client.UpdateUsers(cmd.Context(), state.ToRestUsers(diff.Update))
+			}
+			updatedNames := names(updated)
+			for _, u := range diff.Update {
+				rows = append(rows, userSyncRow{Action: "update", Name: u.Name, DisplayName: u.DisplayName, EmailAddress: u.EmailAddress, Result: result(updatedNames, u.Name)})
+			}
+
+			// Delete or report extras
+			if !prune {
+				for _, u := range diff.Delete {
+					rows = append(rows, userSyncRow{Action: "extra", Name: u.Name, DisplayName: u.DisplayName, EmailAddress: u.EmailAddress, Result: "kept (use --prune to delete)"})
+				}
+			} else {
+				var deleted []openapi.RestApplicationUser
+				if len(pruned) > 0 && !dryRun {
+					deleted, _ = client.DeleteUsers(cmd.Context(), pruned)
+				}
+				deletedNames := names(deleted)
+				for _, u := range diff.Delete {
+					rows = append(rows, userSyncRow{Action: "delete", Name: u.Name, DisplayName: u.DisplayName, EmailAddress: u.EmailAddress, Result: result(deletedNames, u.Name)})
+				}
+			}
+
+			client.Logger.Info("User sync summary", "create", len(diff.Create), "update", len(diff.Update), "extra", len(diff.Delete), "prune", prune, "dryRun", dryRun)
+			if len(rows) == 0 {
+				client.Logger.Info("No changes, users are in sync")
+				return nil
+			}
+			if err := utils.PrintStructured("changes", rows, output, "action,name,displayName,emailAddress,result"); err != nil {
+				return fmt.Errorf("failed to print output: %w", err)
+			}
+			if failed > 0 {
+				return fmt.Errorf("user sync finished with %d failed changes", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&input, "input", "i", "", `Path to YAML or JSON file with all users, or "-" to read from stdin.
+Example file content:
+  users:
+    - name: user1
+      displayName: "User One"
+      emailAddress: user1@example.com`)
+	cmd.Flags().BoolVar(&prune, "prune", false, "Delete users that exist in Bitbucket but are missing from the file")
+	cmd.Flags().StringVar(&password, "user-password", "", "Initial password for users that have to be created")
+	cmd.Flags().StringVarP(
+		&output,
+		"output",
+		"o",
+		"plain",
+		`Output format of the summary: plain|yaml|json.`,
+	)
+
+	return cmd
+}
@@ -1,7 +1,6 @@
 package user
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -33,7 +32,7 @@ This is required for older Bitbucket versions that do not support token-based op
 				return fmt.Errorf("either --name or --input must be specified, but not both")
 			}
 
-			client, err := bitbucket.NewClient(context.Background())
+			client, err := bitbucket.NewClient()
 			if err != nil {
 				return err
 			}
@@ -94,7 +93,7 @@ This is required for older Bitbucket versions that do not support token-based op
 			}
 
 			// Update users
-			updatedUsers, err := client.UpdateUsers(users)
+			updatedUsers, err := client.UpdateUsers(cmd.Context(), users)
 			if err != nil {
 				client.Logger.Error(err.Error())
 				return nil
@@ -16,6 +16,7 @@ func UserCmd() *cobra.Command {
 		CreateCmd(),
 		UpdateCmd(),
 		DeleteCmd(),
+		SyncCmd(),
 	)
 
 	return cmd
@@ -3,9 +3,14 @@ package bitbucket
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/vinisman/bbctl/internal/config"
@@ -13,12 +18,12 @@ import (
 )
 
 type Client struct {
-	api     *openapi.APIClient
-	logger  *slog.Logger
-	client  *http.Client
-	authCtx context.Context
-	config  *config.Config
-	Logger  *slog.Logger
+	api       *openapi.APIClient
+	logger    *slog.Logger
+	client    *http.Client
+	basicAuth *openapi.BasicAuth
+	config    *config.Config
+	Logger    *slog.Logger
 }
 
 // API exposes the underlying Bitbucket API client (read-only usage outside)
@@ -26,18 +31,53 @@ func (c *Client) API() *openapi.APIClient {
 	return c.api
 }
 
-// Context exposes the auth context for reuse by sibling clients (read-only)
-func (c *Client) Context() context.Context {
-	return c.authCtx
+// HTTPClient exposes the shared HTTP client for sibling clients and raw requests
+func (c *Client) HTTPClient() *http.Client {
+	return c.client
 }
 
-func NewClient(ctx context.Context) (*Client, error) {
+// AuthContext adds the credentials to a request context, for reuse by sibling clients
+func (c *Client) AuthContext(ctx context.Context) context.Context {
+	return c.authCtx(ctx)
+}
+
+// authCtx returns the request context with basic auth credentials if they are used;
+// the bearer token is sent as a default header instead
+func (c *Client) authCtx(ctx context.Context) context.Context {
+	if c.basicAuth != nil {
+		return context.WithValue(ctx, openapi.ContextBasicAuth, *c.basicAuth)
+	}
+	return ctx
+}
+
+// notRun returns an error when the context is done so workers stop picking new jobs.
+// The skipped item is logged with the given attributes.
+func (c *Client) notRun(ctx context.Context, args ...any) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	c.logger.Warn("Skipped, operation interrupted", args...)
+	return fmt.Errorf("%w: %w", ErrNotRun, ctx.Err())
+}
+
+// NewClient creates a client for the global configuration. Requests are made
+// with the context passed to each method, so they can be cancelled.
+func NewClient() (*Client, error) {
+	return NewClientFor(config.GlobalCfg)
+}
+
+// NewClientFor creates a client for the given configuration, e.g. one loaded
+// with config.LoadContext to talk to another instance
+func NewClientFor(cfg *config.Config) (*Client, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config is nil")
+	}
 	config.GlobalLogger.Debug("Initializing Bitbucket client")
 
 	config.GlobalLogger.Debug("Preparing OpenAPI configuration")
 	cfgOpenAPI := openapi.NewConfiguration()
-	cfgOpenAPI.Servers = openapi.ServerConfigurations{{URL: config.GlobalCfg.BaseURL}}
-	config.GlobalLogger.Debug("Server URL set to", "url", config.GlobalCfg.BaseURL)
+	cfgOpenAPI.Servers = openapi.ServerConfigurations{{URL: cfg.BaseURL}}
+	config.GlobalLogger.Debug("Server URL set to", "url", cfg.BaseURL)
 	cfgOpenAPI.AddDefaultHeader("User-Agent", "bbctl/1.0")
 	config.GlobalLogger.Debug("Added default header", "header", "User-Agent", "value", "bbctl/1.0")
 	cfgOpenAPI.AddDefaultHeader("X-Content-Type-Options", "nosniff")
@@ -47,47 +87,180 @@ func NewClient(ctx context.Context) (*Client, error) {
 	cfgOpenAPI.AddDefaultHeader("X-Atlassian-Token", "no-check")
 	config.GlobalLogger.Debug("Added default header", "header", "X-Atlassian-Token", "value", "no-check")
 
-	if config.GlobalCfg == nil {
-		return nil, fmt.Errorf("config is nil")
-	}
 	if config.GlobalLogger == nil {
 		return nil, fmt.Errorf("logger is nil")
 	}
 
 	config.GlobalLogger.Debug("Checking authentication configuration")
-	authCtx := ctx
-	if config.GlobalCfg.Username != "" && config.GlobalCfg.Password != "" {
-		config.GlobalLogger.Debug("Using username/password for basic auth", "username", config.GlobalCfg.Username)
-		authCtx = context.WithValue(ctx, openapi.ContextBasicAuth, openapi.BasicAuth{
-			UserName: config.GlobalCfg.Username,
-			Password: config.GlobalCfg.Password,
-		})
+	var basicAuth *openapi.BasicAuth
+	// Recordings are redacted, so replaying them needs no credentials
+	if cfg.ReplayDir == "" {
+		if err := cfg.CheckCredentials(); err != nil {
+			config.GlobalLogger.Error("No valid authentication credentials provided")
+			return nil, err
+		}
+	}
+	if cfg.UseBasicAuth() {
+		config.GlobalLogger.Debug("Using username/password for basic auth", "username", cfg.Username)
+		basicAuth = &openapi.BasicAuth{
+			UserName: cfg.Username,
+			Password: cfg.Password,
+		}
 		config.GlobalLogger.Debug("Using Basic Auth")
-	} else if config.GlobalCfg.Token != "" {
+	} else if cfg.Token != "" {
 		config.GlobalLogger.Debug("Using Bearer token authentication")
-		cfgOpenAPI.AddDefaultHeader("Authorization", "Bearer "+config.GlobalCfg.Token)
-	} else {
-		config.GlobalLogger.Error("No valid authentication credentials provided")
-		return nil, fmt.Errorf("either token or username/password must be provided")
+		cfgOpenAPI.AddDefaultHeader("Authorization", "Bearer "+cfg.Token)
 	}
 
-	// Configure HTTP client with optional TLS skip verify
-	httpClient := &http.Client{Timeout: 30 * time.Second}
-	if config.GlobalCfg.Insecure {
-		config.GlobalLogger.Debug("Insecure mode enabled - skipping TLS certificate verification")
-		transport := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	// Configure HTTP client with TLS settings and proxy,
+	// rate limited and retrying transient failures; the timeout applies to each attempt
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	tlsCfg, err := tlsConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if tlsCfg != nil {
+		transport.TLSClientConfig = tlsCfg
+	}
+	if cfg.Proxy != "" {
+		proxy, err := proxyFunc(cfg.Proxy, cfg.NoProxy)
+		if err != nil {
+			return nil, err
 		}
-		httpClient.Transport = transport
+		config.GlobalLogger.Debug("Using proxy", "proxy", cfg.Proxy, "noProxy", cfg.NoProxy)
+		transport.Proxy = proxy
 	}
+	var base http.RoundTripper = transport
+	switch {
+	case cfg.ReplayDir != "":
+		replay, err := newReplayTransport(cfg.ReplayDir, config.GlobalLogger)
+		if err != nil {
+			return nil, err
+		}
+		base = replay
+	case cfg.RecordDir != "":
+		config.GlobalLogger.Debug("Recording requests", "dir", cfg.RecordDir)
+		record, err := newRecordTransport(transport, cfg.RecordDir, config.GlobalLogger)
+		if err != nil {
+			return nil, err
+		}
+		base = record
+	}
+	if limiter := getRateLimiter(cfg); limiter != nil {
+		config.GlobalLogger.Debug("Rate limiting enabled", "rate", cfg.RateLimit, "burst", cfg.RateBurst)
+		base = &rateLimitTransport{next: base, limiter: limiter}
+	}
+	httpClient := &http.Client{
+		Transport: newRetryTransport(base, cfg.Retries, cfg.RetryMaxWait, 30*time.Second, config.GlobalLogger),
+	}
+	if cfg.DryRun {
+		config.GlobalLogger.Debug("Dry run enabled - mutating requests are not sent")
+		httpClient.Transport = &dryRunTransport{next: httpClient.Transport, logger: config.GlobalLogger}
+	}
+	cfgOpenAPI.HTTPClient = httpClient
 
 	config.GlobalLogger.Debug("Bitbucket client successfully initialized")
 	return &Client{
-		api:     openapi.NewAPIClient(cfgOpenAPI),
-		logger:  config.GlobalLogger,
-		client:  httpClient,
-		authCtx: authCtx,
-		config:  config.GlobalCfg,
-		Logger:  config.GlobalLogger,
+		api:       openapi.NewAPIClient(cfgOpenAPI),
+		logger:    config.GlobalLogger,
+		client:    httpClient,
+		basicAuth: basicAuth,
+		config:    cfg,
+		Logger:    config.GlobalLogger,
+	}, nil
+}
+
+// tlsConfig builds the TLS settings from the config, nil when defaults are fine
+func tlsConfig(cfg *config.Config) (*tls.Config, error) {
+	if !cfg.Insecure && cfg.CAFile == "" && cfg.ClientCert == "" {
+		return nil, nil
+	}
+	tlsCfg := &tls.Config{}
+	if cfg.Insecure {
+		config.GlobalLogger.Debug("Insecure mode enabled - skipping TLS certificate verification")
+		tlsCfg.InsecureSkipVerify = true
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+		config.GlobalLogger.Debug("Using custom CA file", "file", cfg.CAFile)
+		tlsCfg.RootCAs = pool
+	}
+	if cfg.ClientCert != "" {
+		key := cfg.ClientKey
+		if key == "" {
+			// certificate and key in one PEM file
+			key = cfg.ClientCert
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.GlobalLogger.Debug("Using client certificate", "file", cfg.ClientCert)
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return tlsCfg, nil
+}
+
+// proxyFunc returns a proxy selector that sends requests through proxyURL,
+// except for hosts matching noProxy (NO_PROXY syntax)
+func proxyFunc(proxyURL, noProxy string) (func(*http.Request) (*url.URL, error), error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", proxyURL)
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL, noProxy) {
+			return nil, nil
+		}
+		return u, nil
 	}, nil
 }
+
+// bypassProxy reports whether the host matches a NO_PROXY entry: "*", a host name
+// (also matching its subdomains, a leading dot is optional), an IP, a CIDR, each
+// optionally with a port
+func bypassProxy(target *url.URL, noProxy string) bool {
+	host, port := strings.ToLower(target.Hostname()), target.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[target.Scheme]
+	}
+	ip := net.ParseIP(host)
+
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		entryHost, entryPort := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			entryHost, entryPort = h, p
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+		entryHost = strings.TrimPrefix(entryHost, ".")
+		if host == entryHost || strings.HasSuffix(host, "."+entryHost) {
+			return true
+		}
+	}
+	return false
+}
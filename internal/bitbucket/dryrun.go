@@ -0,0 +1,168 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/vinisman/bbctl/internal/models"
+)
+
+// ErrDryRun is returned for a mutating request that reaches the transport in dry-run mode
+var ErrDryRun = errors.New("request not sent in dry-run mode")
+
+// dryRunTransport refuses every request that could change Bitbucket, so a dry run
+// never modifies anything, also for operations without a preview of their own
+type dryRunTransport struct {
+	next   http.RoundTripper
+	logger *slog.Logger
+}
+
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.next.RoundTrip(req)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	t.logger.Warn("Dry run, request not sent", "method", req.Method, "path", req.URL.Path)
+	return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Path, ErrDryRun)
+}
+
+// DryRun reports whether mutating requests are replaced by a preview
+func (c *Client) DryRun() bool {
+	return c.config.DryRun
+}
+
+// preview stands in for a mutating request in dry-run mode. It logs the change that
+// would be made, or returns the error of the read-only lookup of its target so the
+// item fails as the real request would.
+func (c *Client) preview(op, kind, item string, lookupErr error) error {
+	if lookupErr != nil {
+		c.logger.Warn("Dry run, "+op+" "+kind+" would fail", "item", item, "error", lookupErr)
+		return lookupErr
+	}
+	c.logger.Info("Dry run, would "+op+" "+kind, "item", item)
+	return nil
+}
+
+// PreviewRepoChange stands in for a change of repository settings made by a
+// sibling client, such as Workzone, in dry-run mode
+func (c *Client) PreviewRepoChange(ctx context.Context, op, kind string, repo models.ExtendedRepository) error {
+	return c.preview(op, kind, repo.ProjectKey+"/"+repo.RepositorySlug, c.lookupRepo(ctx, repo.ProjectKey, repo.RepositorySlug))
+}
+
+// found turns the result of a lookup into an error naming the missing target
+func found(target string, httpResp *http.Response, err error) error {
+	if err == nil {
+		return nil
+	}
+	if httpResp != nil && httpResp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s not found: %w", target, err)
+	}
+	return fmt.Errorf("failed to look up %s: %w", target, err)
+}
+
+// absent returns an error when the lookup found the target that is to be created
+func absent(target string, httpResp *http.Response, err error) error {
+	if err == nil {
+		return fmt.Errorf("%s already exists", target)
+	}
+	if httpResp != nil && httpResp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return fmt.Errorf("failed to look up %s: %w", target, err)
+}
+
+func (c *Client) lookupProject(ctx context.Context, key string) error {
+	_, httpResp, err := c.api.ProjectAPI.GetProject(c.authCtx(ctx), key).Execute()
+	return found("project "+key, httpResp, err)
+}
+
+// lookupNewProject returns an error when a project with that key already exists
+func (c *Client) lookupNewProject(ctx context.Context, key string) error {
+	_, httpResp, err := c.api.ProjectAPI.GetProject(c.authCtx(ctx), key).Execute()
+	return absent("project "+key, httpResp, err)
+}
+
+func (c *Client) lookupRepo(ctx context.Context, projectKey, slug string) error {
+	_, httpResp, err := c.api.ProjectAPI.GetRepository(c.authCtx(ctx), projectKey, slug).Execute()
+	return found("repository "+projectKey+"/"+slug, httpResp, err)
+}
+
+var slugInvalid = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// lookupNewRepo returns an error when the repository a create of name would make
+// already exists; the slug is derived from the name like Bitbucket does
+func (c *Client) lookupNewRepo(ctx context.Context, projectKey, name string) error {
+	slug := strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	_, httpResp, err := c.api.ProjectAPI.GetRepository(c.authCtx(ctx), projectKey, slug).Execute()
+	return absent("repository "+projectKey+"/"+slug, httpResp, err)
+}
+
+func (c *Client) lookupWebhook(ctx context.Context, repo models.ExtendedRepository, id string) error {
+	_, httpResp, err := c.api.RepositoryAPI.GetWebhook1(c.authCtx(ctx), repo.ProjectKey, id, repo.RepositorySlug).Execute()
+	return found("webhook "+id+" in "+repo.ProjectKey+"/"+repo.RepositorySlug, httpResp, err)
+}
+
+func (c *Client) lookupBranchPermission(ctx context.Context, repo models.ExtendedRepository, id string) error {
+	_, httpResp, err := c.api.RepositoryAPI.GetRestriction1(c.authCtx(ctx), repo.ProjectKey, id, repo.RepositorySlug).Execute()
+	return found("branch permission "+id+" in "+repo.ProjectKey+"/"+repo.RepositorySlug, httpResp, err)
+}
+
+func (c *Client) lookupReviewerGroup(ctx context.Context, repo models.ExtendedRepository, id string) error {
+	_, httpResp, err := c.api.PullRequestsAPI.GetReviewerGroup1(c.authCtx(ctx), repo.ProjectKey, id, repo.RepositorySlug).Execute()
+	return found("reviewer group "+id+" in "+repo.ProjectKey+"/"+repo.RepositorySlug, httpResp, err)
+}
+
+// lookupRequiredBuild searches the conditions of the repository, they cannot be fetched by ID
+func (c *Client) lookupRequiredBuild(ctx context.Context, repo models.ExtendedRepository, id int64) error {
+	target := "required build " + strconv.FormatInt(id, 10) + " in " + repo.ProjectKey + "/" + repo.RepositorySlug
+	resp, httpResp, err := c.api.BuildsAndDeploymentsAPI.
+		GetPageOfRequiredBuildsMergeChecks(c.authCtx(ctx), repo.ProjectKey, repo.RepositorySlug).
+		Execute()
+	if err != nil {
+		return found(target, httpResp, err)
+	}
+	for _, rb := range resp.GetValues() {
+		if rb.Id != nil && *rb.Id == id {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s not found", target)
+}
+
+func (c *Client) lookupUser(ctx context.Context, name string) error {
+	_, httpResp, err := c.api.SystemMaintenanceAPI.GetUser(c.authCtx(ctx), name).Execute()
+	return found("user "+name, httpResp, err)
+}
+
+// lookupNewUser returns an error when a user with that name already exists
+func (c *Client) lookupNewUser(ctx context.Context, name string) error {
+	_, httpResp, err := c.api.SystemMaintenanceAPI.GetUser(c.authCtx(ctx), name).Execute()
+	return absent("user "+name, httpResp, err)
+}
+
+func (c *Client) lookupGroup(ctx context.Context, name string) error {
+	_, httpResp, err := c.api.PermissionManagementAPI.FindUsersInGroup(c.authCtx(ctx)).Context(name).Limit(1).Execute()
+	return found("group "+name, httpResp, err)
+}
+
+// lookupNewGroup returns an error when a group with that name already exists
+func (c *Client) lookupNewGroup(ctx context.Context, name string) error {
+	_, httpResp, err := c.api.PermissionManagementAPI.FindUsersInGroup(c.authCtx(ctx)).Context(name).Limit(1).Execute()
+	return absent("group "+name, httpResp, err)
+}
+
+// logChanged logs a completed change; in dry-run mode preview has logged it already
+func (c *Client) logChanged(msg string, args ...any) {
+	if !c.config.DryRun {
+		c.logger.Info(msg, args...)
+	}
+}
@@ -0,0 +1,298 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/models"
+	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
+)
+
+// getGroupMembers fetches all usernames of a group with pagination
+func (c *Client) getGroupMembers(ctx context.Context, groupName string) ([]string, error) {
+	members := []string{}
+	var start float32 = 0
+
+	for {
+		resp, httpResp, err := c.api.PermissionManagementAPI.FindUsersInGroup(c.authCtx(ctx)).
+			Context(groupName).
+			Start(start).
+			Limit(float32(c.config.PageSize)).
+			Execute()
+		if err != nil {
+			if httpResp != nil {
+				c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
+			}
+			return nil, fmt.Errorf("failed to get members of group %s: %w", groupName, err)
+		}
+
+		for _, u := range resp.Values {
+			if u.Name != nil {
+				members = append(members, *u.Name)
+			}
+		}
+
+		if resp.IsLastPage == nil || *resp.IsLastPage || resp.NextPageStart == nil {
+			break
+		}
+		start = float32(*resp.NextPageStart)
+	}
+
+	return members, nil
+}
+
+// GetGroupMembers retrieves members of multiple groups in parallel
+func (c *Client) GetGroupMembers(ctx context.Context, groupNames []string) ([]models.Group, error) {
+	if len(groupNames) == 0 {
+		return []models.Group{}, nil
+	}
+
+	c.logger.Info("Getting group members", "count", len(groupNames))
+
+	type result struct {
+		index   int
+		members []string
+		err     error
+	}
+
+	resultsCh := make(chan result, len(groupNames))
+	jobs := make(chan int, len(groupNames))
+
+	maxWorkers := config.GlobalMaxWorkers
+	var wg sync.WaitGroup
+
+	for range maxWorkers {
+		wg.Go(func() {
+			for i := range jobs {
+				if err := c.notRun(ctx, "group", groupNames[i]); err != nil {
+					resultsCh <- result{index: i, err: err}
+					continue
+				}
+				members, err := c.getGroupMembers(ctx, groupNames[i])
+				resultsCh <- result{index: i, members: members, err: err}
+			}
+		})
+	}
+
+	for i := range groupNames {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+	close(resultsCh)
+
+	results := make([]*models.Group, len(groupNames))
+	var errorsCount int
+	for res := range resultsCh {
+		if res.err != nil {
+			c.logger.Error("Failed to get group members", "group", groupNames[res.index], "error", res.err)
+			errorsCount++
+			continue
+		}
+		results[res.index] = &models.Group{Name: groupNames[res.index], Members: res.members}
+	}
+
+	groups := make([]models.Group, 0, len(groupNames))
+	for _, g := range results {
+		if g != nil {
+			groups = append(groups, *g)
+		}
+	}
+
+	if errorsCount > 0 {
+		return groups, fmt.Errorf("failed to get members of %d out of %d groups", errorsCount, len(groupNames))
+	}
+	return groups, nil
+}
+
+// AddGroupMembers adds the listed members to each group, one request per group.
+// Returns the groups whose members were added.
+func (c *Client) AddGroupMembers(ctx context.Context, groups []models.Group) ([]models.Group, error) {
+	if len(groups) == 0 {
+		return []models.Group{}, nil
+	}
+
+	c.logger.Info("Adding group members", "groups", len(groups))
+
+	type result struct {
+		index int
+		err   error
+	}
+
+	resultsCh := make(chan result, len(groups))
+	jobs := make(chan int, len(groups))
+
+	maxWorkers := config.GlobalMaxWorkers
+	var wg sync.WaitGroup
+
+	for range maxWorkers {
+		wg.Go(func() {
+			for i := range jobs {
+				if err := c.notRun(ctx, "group", groups[i].Name); err != nil {
+					resultsCh <- result{index: i, err: err}
+					continue
+				}
+				g := groups[i]
+				if c.config.DryRun {
+					err := c.lookupGroup(ctx, g.Name)
+					for _, u := range g.Members {
+						if err != nil {
+							break
+						}
+						err = c.lookupUser(ctx, u)
+					}
+					resultsCh <- result{index: i, err: c.preview("add", "group members", g.Name, err)}
+					continue
+				}
+				httpResp, err := c.api.PermissionManagementAPI.AddUsersToGroup(c.authCtx(ctx)).
+					GroupAndUsers(openapi.GroupAndUsers{Group: &g.Name, Users: g.Members}).
+					Execute()
+				if err != nil {
+					if httpResp != nil {
+						c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
+					}
+					err = fmt.Errorf("failed to add members to group %s: %w", g.Name, err)
+				}
+				resultsCh <- result{index: i, err: err}
+			}
+		})
+	}
+
+	for i, g := range groups {
+		if len(g.Members) == 0 {
+			resultsCh <- result{index: i}
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+	close(resultsCh)
+
+	added := make([]bool, len(groups))
+	var errorsCount int
+	for res := range resultsCh {
+		c.Record("add", "group members", groups[res.index].Name, groups[res.index], res.err)
+		if res.err != nil {
+			c.logger.Error("Failed to add group members", "error", res.err)
+			errorsCount++
+			continue
+		}
+		added[res.index] = true
+		if len(groups[res.index].Members) > 0 {
+			c.logChanged("Added group members", "group", groups[res.index].Name, "count", len(groups[res.index].Members))
+		}
+	}
+
+	var out []models.Group
+	for i, ok := range added {
+		if ok && len(groups[i].Members) > 0 {
+			out = append(out, groups[i])
+		}
+	}
+
+	if errorsCount > 0 {
+		return out, fmt.Errorf("failed to add members to %d out of %d groups", errorsCount, len(groups))
+	}
+	return out, nil
+}
+
+// RemoveGroupMembers removes the listed members from each group, one request per member.
+// Returns the groups with the members that were removed.
+func (c *Client) RemoveGroupMembers(ctx context.Context, groups []models.Group) ([]models.Group, error) {
+	type job struct {
+		group int
+		user  string
+	}
+	var all []job
+	for i, g := range groups {
+		for _, u := range g.Members {
+			all = append(all, job{group: i, user: u})
+		}
+	}
+	if len(all) == 0 {
+		return []models.Group{}, nil
+	}
+
+	c.logger.Info("Removing group members", "count", len(all))
+
+	type result struct {
+		job job
+		err error
+	}
+
+	resultsCh := make(chan result, len(all))
+	jobs := make(chan job, len(all))
+
+	maxWorkers := config.GlobalMaxWorkers
+	var wg sync.WaitGroup
+
+	for range maxWorkers {
+		wg.Go(func() {
+			for j := range jobs {
+				if err := c.notRun(ctx, "group", groups[j.group].Name, "user", j.user); err != nil {
+					resultsCh <- result{job: j, err: err}
+					continue
+				}
+				groupName := groups[j.group].Name
+				if c.config.DryRun {
+					err := c.lookupGroup(ctx, groupName)
+					if err == nil {
+						err = c.lookupUser(ctx, j.user)
+					}
+					resultsCh <- result{job: j, err: c.preview("remove", "group member", groupName+" "+j.user, err)}
+					continue
+				}
+				httpResp, err := c.api.PermissionManagementAPI.RemoveUserFromGroup(c.authCtx(ctx)).
+					UserPickerContext(openapi.UserPickerContext{Context: &groupName, ItemName: &j.user}).
+					Execute()
+				if err != nil {
+					if httpResp != nil {
+						c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
+					}
+					err = fmt.Errorf("failed to remove %s from group %s: %w", j.user, groupName, err)
+				}
+				resultsCh <- result{job: j, err: err}
+			}
+		})
+	}
+
+	for _, j := range all {
+		jobs <- j
+	}
+	close(jobs)
+
+	wg.Wait()
+	close(resultsCh)
+
+	removed := make([][]string, len(groups))
+	var errorsCount int
+	for res := range resultsCh {
+		c.Record("remove", "group member", groups[res.job.group].Name+" "+res.job.user, models.Group{Name: groups[res.job.group].Name, Members: []string{res.job.user}}, res.err)
+		if res.err != nil {
+			c.logger.Error("Failed to remove group member", "error", res.err)
+			errorsCount++
+			continue
+		}
+		c.logChanged("Removed group member", "group", groups[res.job.group].Name, "username", res.job.user)
+		removed[res.job.group] = append(removed[res.job.group], res.job.user)
+	}
+
+	var out []models.Group
+	for i, users := range removed {
+		if len(users) > 0 {
+			slices.Sort(users)
+			out = append(out, models.Group{Name: groups[i].Name, Members: users})
+		}
+	}
+
+	if errorsCount > 0 {
+		return out, fmt.Errorf("failed to remove %d out of %d group members", errorsCount, len(all))
+	}
+	return out, nil
+}
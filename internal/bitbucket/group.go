@@ -1,33 +1,49 @@
 package bitbucket
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
 	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
 	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
 )
 
 // GetAllGroups retrieves all groups from Bitbucket
-func (c *Client) GetAllGroups() ([]openapi.RestDetailedGroup, error) {
+func (c *Client) GetAllGroups(ctx context.Context) ([]openapi.RestDetailedGroup, error) {
 	c.logger.Info("Getting all groups from Bitbucket")
 
-	resp, httpResp, err := c.api.PermissionManagementAPI.GetGroups1(c.authCtx).Execute()
-	if err != nil {
-		if httpResp != nil {
-			c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
+	groups := []openapi.RestDetailedGroup{}
+	var start float32 = 0
+	for {
+		resp, httpResp, err := c.api.PermissionManagementAPI.GetGroups1(c.authCtx(ctx)).
+			Start(start).
+			Limit(float32(c.config.PageSize)).
+			Execute()
+		if err != nil {
+			if httpResp != nil {
+				c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
+			}
+			c.logger.Error("Failed to get all groups", "error", err)
+			return []openapi.RestDetailedGroup{}, err
 		}
-		c.logger.Error("Failed to get all groups", "error", err)
-		return []openapi.RestDetailedGroup{}, err
+
+		groups = append(groups, resp.Values...)
+
+		if resp.IsLastPage == nil || *resp.IsLastPage || resp.NextPageStart == nil {
+			break
+		}
+		start = float32(*resp.NextPageStart)
 	}
 
-	groups := resp.Values
 	c.logger.Info("Successfully retrieved all groups", "count", len(groups))
 	return groups, nil
 }
 
 // GetGroups retrieves specific groups by names
-func (c *Client) GetGroups(groupNames []string) ([]openapi.RestDetailedGroup, error) {
+func (c *Client) GetGroups(ctx context.Context, groupNames []string) ([]openapi.RestDetailedGroup, error) {
 	if len(groupNames) == 0 {
 		return []openapi.RestDetailedGroup{}, nil
 	}
@@ -35,7 +51,7 @@ func (c *Client) GetGroups(groupNames []string) ([]openapi.RestDetailedGroup, er
 	c.logger.Info("Getting groups", "count", len(groupNames))
 
 	// Get all groups first
-	allGroups, err := c.GetAllGroups()
+	allGroups, err := c.GetAllGroups(ctx)
 	if err != nil {
 		return []openapi.RestDetailedGroup{}, err
 	}
@@ -71,7 +87,7 @@ func (c *Client) GetGroups(groupNames []string) ([]openapi.RestDetailedGroup, er
 }
 
 // CreateGroups creates multiple groups
-func (c *Client) CreateGroups(groups []openapi.RestDetailedGroup) ([]openapi.RestDetailedGroup, error) {
+func (c *Client) CreateGroups(ctx context.Context, groups []openapi.RestDetailedGroup) ([]openapi.RestDetailedGroup, error) {
 	if len(groups) == 0 {
 		return []openapi.RestDetailedGroup{}, nil
 	}
@@ -99,8 +115,17 @@ func (c *Client) CreateGroups(groups []openapi.RestDetailedGroup) ([]openapi.Res
 	for range maxWorkers {
 		wg.Go(func() {
 			for j := range jobs {
+				if err := c.notRun(ctx, "group", j.group.GetName()); err != nil {
+					resultsCh <- result{index: j.index, group: &j.group, err: err}
+					continue
+				}
+				if c.config.DryRun {
+					err := c.preview("create", "group", j.group.GetName(), c.lookupNewGroup(ctx, j.group.GetName()))
+					resultsCh <- result{index: j.index, group: &j.group, err: err}
+					continue
+				}
 				// Build the request
-				req := c.api.PermissionManagementAPI.CreateGroup(c.authCtx).Name(*j.group.Name)
+				req := c.api.PermissionManagementAPI.CreateGroup(c.authCtx(ctx)).Name(*j.group.Name)
 
 				// Execute the request
 				createdGroup, httpResp, err := req.Execute()
@@ -132,12 +157,13 @@ func (c *Client) CreateGroups(groups []openapi.RestDetailedGroup) ([]openapi.Res
 	var errs []error
 
 	for res := range resultsCh {
+		c.Record("create", "group", utils.SafeValue(groups[res.index].Name), models.Group{Name: utils.SafeValue(groups[res.index].Name)}, res.err)
 		if res.err != nil {
 			errs = append(errs, res.err)
 			c.logger.Error("Failed to create group", "error", res.err)
 		} else {
 			results[res.index] = res.group
-			c.logger.Info("Successfully created group", "name", *res.group.Name)
+			c.logChanged("Successfully created group", "name", *res.group.Name)
 		}
 	}
 
@@ -153,12 +179,12 @@ func (c *Client) CreateGroups(groups []openapi.RestDetailedGroup) ([]openapi.Res
 		return createdGroups, fmt.Errorf("errors occurred creating groups: %v", errs)
 	}
 
-	c.logger.Info("Successfully created all groups", "count", len(createdGroups))
+	c.logChanged("Successfully created all groups", "count", len(createdGroups))
 	return createdGroups, nil
 }
 
 // DeleteGroups deletes multiple groups
-func (c *Client) DeleteGroups(groupNames []string) ([]openapi.RestDetailedGroup, error) {
+func (c *Client) DeleteGroups(ctx context.Context, groupNames []string) ([]openapi.RestDetailedGroup, error) {
 	if len(groupNames) == 0 {
 		return []openapi.RestDetailedGroup{}, nil
 	}
@@ -186,8 +212,17 @@ func (c *Client) DeleteGroups(groupNames []string) ([]openapi.RestDetailedGroup,
 	for range maxWorkers {
 		wg.Go(func() {
 			for j := range jobs {
+				if err := c.notRun(ctx, "group", j.groupName); err != nil {
+					resultsCh <- result{index: j.index, err: err}
+					continue
+				}
+				if c.config.DryRun {
+					err := c.preview("delete", "group", j.groupName, c.lookupGroup(ctx, j.groupName))
+					resultsCh <- result{index: j.index, group: &openapi.RestDetailedGroup{Name: &j.groupName}, err: err}
+					continue
+				}
 				// Delete group
-				deletedGroup, httpResp, err := c.api.PermissionManagementAPI.DeleteGroup(c.authCtx).Name(j.groupName).Execute()
+				deletedGroup, httpResp, err := c.api.PermissionManagementAPI.DeleteGroup(c.authCtx(ctx)).Name(j.groupName).Execute()
 				if err != nil {
 					if httpResp != nil {
 						c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
@@ -216,12 +251,13 @@ func (c *Client) DeleteGroups(groupNames []string) ([]openapi.RestDetailedGroup,
 	var errs []error
 
 	for res := range resultsCh {
+		c.Record("delete", "group", groupNames[res.index], models.Group{Name: groupNames[res.index]}, res.err)
 		if res.err != nil {
 			errs = append(errs, res.err)
 			c.logger.Error("Failed to delete group", "error", res.err)
 		} else {
 			results[res.index] = res.group
-			c.logger.Info("Successfully deleted group", "name", *res.group.Name)
+			c.logChanged("Successfully deleted group", "name", *res.group.Name)
 		}
 	}
 
@@ -237,6 +273,6 @@ func (c *Client) DeleteGroups(groupNames []string) ([]openapi.RestDetailedGroup,
 		return deletedGroups, fmt.Errorf("errors occurred deleting groups: %v", errs)
 	}
 
-	c.logger.Info("Successfully deleted all groups", "count", len(deletedGroups))
+	c.logChanged("Successfully deleted all groups", "count", len(deletedGroups))
 	return deletedGroups, nil
 }
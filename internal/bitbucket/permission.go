@@ -0,0 +1,427 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
+)
+
+// permissionOp is a single grant or revoke request
+type permissionOp struct {
+	target string // PRJ or PRJ/repo
+	kind   string // user or group
+	name   string
+	input  any // the target holding only this entry, for --failed-out
+	exec   func() (*http.Response, error)
+}
+
+// runPermissionOps executes permission requests in parallel
+func (c *Client) runPermissionOps(ctx context.Context, action string, ops []permissionOp) error {
+	if len(ops) == 0 {
+		return nil
+	}
+
+	c.logger.Info("Changing permissions", "action", action, "count", len(ops))
+
+	maxWorkers := config.GlobalMaxWorkers
+	jobs := make(chan permissionOp, len(ops))
+	errCh := make(chan error, len(ops))
+	var wg sync.WaitGroup
+
+	for range maxWorkers {
+		wg.Go(func() {
+			for op := range jobs {
+				if err := c.notRun(ctx, "target", op.target, op.kind, op.name); err != nil {
+					errCh <- c.Record(action, op.kind+" permission", op.target+" "+op.name, op.input, err)
+					continue
+				}
+				if c.config.DryRun {
+					err := c.preview(action, op.kind+" permission", op.target+" "+op.name, c.lookupPermissionOp(ctx, op))
+					if c.Record(action, op.kind+" permission", op.target+" "+op.name, op.input, err) != nil {
+						errCh <- err
+					}
+					continue
+				}
+				httpResp, err := op.exec()
+				c.Record(action, op.kind+" permission", op.target+" "+op.name, op.input, err)
+				if err != nil {
+					if httpResp != nil {
+						c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
+					}
+					c.logger.Error("Failed to "+action+" permission", "target", op.target, op.kind, op.name, "error", err)
+					errCh <- err
+					continue
+				}
+				c.logger.Info("Permission changed", "action", action, "target", op.target, op.kind, op.name)
+			}
+		})
+	}
+
+	for _, op := range ops {
+		jobs <- op
+	}
+	close(jobs)
+	wg.Wait()
+	close(errCh)
+
+	if n := len(errCh); n > 0 {
+		return fmt.Errorf("failed to %s %d out of %d permissions", action, n, len(ops))
+	}
+	return nil
+}
+
+// lookupPermissionOp checks that the target and the user or group of op exist
+func (c *Client) lookupPermissionOp(ctx context.Context, op permissionOp) error {
+	var err error
+	if pk, slug, ok := strings.Cut(op.target, "/"); ok {
+		err = c.lookupRepo(ctx, pk, slug)
+	} else {
+		err = c.lookupProject(ctx, op.target)
+	}
+	if err != nil {
+		return err
+	}
+	if op.kind == "group" {
+		return c.lookupGroup(ctx, op.name)
+	}
+	return c.lookupUser(ctx, op.name)
+}
+
+// getRepoPermissions fetches explicit user and group permissions of a repository
+func (c *Client) getRepoPermissions(ctx context.Context, projectKey, repoSlug string) (*models.Permissions, error) {
+	perms := &models.Permissions{Users: []models.PermissionEntry{}, Groups: []models.PermissionEntry{}}
+
+	var start float32 = 0
+	for {
+		resp, httpResp, err := c.api.PermissionManagementAPI.GetUsersWithAnyPermission2(c.authCtx(ctx), projectKey, repoSlug).
+			Start(start).Limit(float32(c.config.PageSize)).Execute()
+		if err != nil {
+			if httpResp != nil {
+				c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
+			}
+			return nil, fmt.Errorf("failed to get user permissions: %w", err)
+		}
+		for _, v := range resp.Values {
+			if v.User != nil {
+				perms.Users = append(perms.Users, models.PermissionEntry{Name: utils.SafeValue(v.User.Name), Permission: utils.SafeValue(v.Permission)})
+			}
+		}
+		if resp.IsLastPage == nil || *resp.IsLastPage || resp.NextPageStart == nil {
+			break
+		}
+		start = float32(*resp.NextPageStart)
+	}
+
+	start = 0
+	for {
+		resp, httpResp, err := c.api.PermissionManagementAPI.GetGroupsWithAnyPermission2(c.authCtx(ctx), projectKey, repoSlug).
+			Start(start).Limit(float32(c.config.PageSize)).Execute()
+		if err != nil {
+			if httpResp != nil {
+				c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
+			}
+			return nil, fmt.Errorf("failed to get group permissions: %w", err)
+		}
+		for _, v := range resp.Values {
+			if v.Group != nil {
+				perms.Groups = append(perms.Groups, models.PermissionEntry{Name: utils.SafeValue(v.Group.Name), Permission: utils.SafeValue(v.Permission)})
+			}
+		}
+		if resp.IsLastPage == nil || *resp.IsLastPage || resp.NextPageStart == nil {
+			break
+		}
+		start = float32(*resp.NextPageStart)
+	}
+
+	return perms, nil
+}
+
+// getProjectPermissions fetches explicit user and group permissions of a project
+func (c *Client) getProjectPermissions(ctx context.Context, projectKey string) (*models.Permissions, error) {
+	perms := &models.Permissions{Users: []models.PermissionEntry{}, Groups: []models.PermissionEntry{}}
+
+	var start float32 = 0
+	for {
+		resp, httpResp, err := c.api.ProjectAPI.GetUsersWithAnyPermission1(c.authCtx(ctx), projectKey).
+			Start(start).Limit(float32(c.config.PageSize)).Execute()
+		if err != nil {
+			if httpResp != nil {
+				c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
+			}
+			return nil, fmt.Errorf("failed to get user permissions: %w", err)
+		}
+		for _, v := range resp.Values {
+			if v.User != nil {
+				perms.Users = append(perms.Users, models.PermissionEntry{Name: utils.SafeValue(v.User.Name), Permission: utils.SafeValue(v.Permission)})
+			}
+		}
+		if resp.IsLastPage == nil || *resp.IsLastPage || resp.NextPageStart == nil {
+			break
+		}
+		start = float32(*resp.NextPageStart)
+	}
+
+	start = 0
+	for {
+		resp, httpResp, err := c.api.ProjectAPI.GetGroupsWithAnyPermission1(c.authCtx(ctx), projectKey).
+			Start(start).Limit(float32(c.config.PageSize)).Execute()
+		if err != nil {
+			if httpResp != nil {
+				c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
+			}
+			return nil, fmt.Errorf("failed to get group permissions: %w", err)
+		}
+		for _, v := range resp.Values {
+			if v.Group != nil {
+				perms.Groups = append(perms.Groups, models.PermissionEntry{Name: utils.SafeValue(v.Group.Name), Permission: utils.SafeValue(v.Permission)})
+			}
+		}
+		if resp.IsLastPage == nil || *resp.IsLastPage || resp.NextPageStart == nil {
+			break
+		}
+		start = float32(*resp.NextPageStart)
+	}
+
+	return perms, nil
+}
+
+// GetRepoPermissions fetches permissions for multiple repositories in parallel
+func (c *Client) GetRepoPermissions(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+	type result struct {
+		index int
+		perms *models.Permissions
+		err   error
+	}
+
+	maxWorkers := config.GlobalMaxWorkers
+	jobs := make(chan int, len(repos))
+	resultsCh := make(chan result, len(repos))
+	var wg sync.WaitGroup
+
+	for range maxWorkers {
+		wg.Go(func() {
+			for i := range jobs {
+				if err := c.notRun(ctx, "project", repos[i].ProjectKey, "slug", repos[i].RepositorySlug); err != nil {
+					resultsCh <- result{index: i, err: err}
+					continue
+				}
+				perms, err := c.getRepoPermissions(ctx, repos[i].ProjectKey, repos[i].RepositorySlug)
+				resultsCh <- result{index: i, perms: perms, err: err}
+			}
+		})
+	}
+
+	for i := range repos {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	close(resultsCh)
+
+	out := make([]*models.ExtendedRepository, len(repos))
+	var errorsCount int
+	for res := range resultsCh {
+		r := repos[res.index]
+		if res.err != nil {
+			c.logger.Error("Failed to get repository permissions", "project", r.ProjectKey, "slug", r.RepositorySlug, "error", res.err)
+			errorsCount++
+			continue
+		}
+		out[res.index] = &models.ExtendedRepository{ProjectKey: r.ProjectKey, RepositorySlug: r.RepositorySlug, Permissions: res.perms}
+	}
+
+	fetched := make([]models.ExtendedRepository, 0, len(repos))
+	for _, r := range out {
+		if r != nil {
+			fetched = append(fetched, *r)
+		}
+	}
+
+	if errorsCount > 0 {
+		return fetched, fmt.Errorf("failed to get permissions for %d out of %d repositories", errorsCount, len(repos))
+	}
+	return fetched, nil
+}
+
+// GetProjectPermissions fetches permissions for multiple projects in parallel
+func (c *Client) GetProjectPermissions(ctx context.Context, projects []models.ExtendedProject) ([]models.ExtendedProject, error) {
+	type result struct {
+		index int
+		perms *models.Permissions
+		err   error
+	}
+
+	maxWorkers := config.GlobalMaxWorkers
+	jobs := make(chan int, len(projects))
+	resultsCh := make(chan result, len(projects))
+	var wg sync.WaitGroup
+
+	for range maxWorkers {
+		wg.Go(func() {
+			for i := range jobs {
+				if err := c.notRun(ctx, "project", projects[i].Key); err != nil {
+					resultsCh <- result{index: i, err: err}
+					continue
+				}
+				perms, err := c.getProjectPermissions(ctx, projects[i].Key)
+				resultsCh <- result{index: i, perms: perms, err: err}
+			}
+		})
+	}
+
+	for i := range projects {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	close(resultsCh)
+
+	out := make([]*models.ExtendedProject, len(projects))
+	var errorsCount int
+	for res := range resultsCh {
+		if res.err != nil {
+			c.logger.Error("Failed to get project permissions", "project", projects[res.index].Key, "error", res.err)
+			errorsCount++
+			continue
+		}
+		out[res.index] = &models.ExtendedProject{Key: projects[res.index].Key, Permissions: res.perms}
+	}
+
+	fetched := make([]models.ExtendedProject, 0, len(projects))
+	for _, p := range out {
+		if p != nil {
+			fetched = append(fetched, *p)
+		}
+	}
+
+	if errorsCount > 0 {
+		return fetched, fmt.Errorf("failed to get permissions for %d out of %d projects", errorsCount, len(projects))
+	}
+	return fetched, nil
+}
+
+// GrantRepoPermissions sets every listed user and group permission on the repositories
+func (c *Client) GrantRepoPermissions(ctx context.Context, repos []models.ExtendedRepository) error {
+	var ops []permissionOp
+	for _, r := range repos {
+		if r.Permissions == nil {
+			continue
+		}
+		pk, slug, target := r.ProjectKey, r.RepositorySlug, r.ProjectKey+"/"+r.RepositorySlug
+		for _, e := range r.Permissions.Users {
+			ops = append(ops, permissionOp{
+				target: target, kind: "user", name: e.Name,
+				input: models.ExtendedRepository{ProjectKey: pk, RepositorySlug: slug, Permissions: &models.Permissions{Users: []models.PermissionEntry{e}}},
+				exec: func() (*http.Response, error) {
+					return c.api.PermissionManagementAPI.SetPermissionForUser(c.authCtx(ctx), pk, slug).Name([]string{e.Name}).Permission(e.Permission).Execute()
+				},
+			})
+		}
+		for _, e := range r.Permissions.Groups {
+			ops = append(ops, permissionOp{
+				target: target, kind: "group", name: e.Name,
+				input: models.ExtendedRepository{ProjectKey: pk, RepositorySlug: slug, Permissions: &models.Permissions{Groups: []models.PermissionEntry{e}}},
+				exec: func() (*http.Response, error) {
+					return c.api.PermissionManagementAPI.SetPermissionForGroup(c.authCtx(ctx), pk, slug).Name([]string{e.Name}).Permission(e.Permission).Execute()
+				},
+			})
+		}
+	}
+	return c.runPermissionOps(ctx, "grant", ops)
+}
+
+// RevokeRepoPermissions revokes all permissions of every listed user and group on the repositories
+func (c *Client) RevokeRepoPermissions(ctx context.Context, repos []models.ExtendedRepository) error {
+	var ops []permissionOp
+	for _, r := range repos {
+		if r.Permissions == nil {
+			continue
+		}
+		pk, slug, target := r.ProjectKey, r.RepositorySlug, r.ProjectKey+"/"+r.RepositorySlug
+		for _, e := range r.Permissions.Users {
+			ops = append(ops, permissionOp{
+				target: target, kind: "user", name: e.Name,
+				input: models.ExtendedRepository{ProjectKey: pk, RepositorySlug: slug, Permissions: &models.Permissions{Users: []models.PermissionEntry{e}}},
+				exec: func() (*http.Response, error) {
+					return c.api.PermissionManagementAPI.RevokePermissionsForUser2(c.authCtx(ctx), pk, slug).Name(e.Name).Execute()
+				},
+			})
+		}
+		for _, e := range r.Permissions.Groups {
+			ops = append(ops, permissionOp{
+				target: target, kind: "group", name: e.Name,
+				input: models.ExtendedRepository{ProjectKey: pk, RepositorySlug: slug, Permissions: &models.Permissions{Groups: []models.PermissionEntry{e}}},
+				exec: func() (*http.Response, error) {
+					return c.api.PermissionManagementAPI.RevokePermissionsForGroup2(c.authCtx(ctx), pk, slug).Name(e.Name).Execute()
+				},
+			})
+		}
+	}
+	return c.runPermissionOps(ctx, "revoke", ops)
+}
+
+// GrantProjectPermissions sets every listed user and group permission on the projects
+func (c *Client) GrantProjectPermissions(ctx context.Context, projects []models.ExtendedProject) error {
+	var ops []permissionOp
+	for _, p := range projects {
+		if p.Permissions == nil {
+			continue
+		}
+		key := p.Key
+		for _, e := range p.Permissions.Users {
+			ops = append(ops, permissionOp{
+				target: key, kind: "user", name: e.Name,
+				input: models.ExtendedProject{Key: key, Permissions: &models.Permissions{Users: []models.PermissionEntry{e}}},
+				exec: func() (*http.Response, error) {
+					return c.api.ProjectAPI.SetPermissionForUsers1(c.authCtx(ctx), key).Name(e.Name).Permission(e.Permission).Execute()
+				},
+			})
+		}
+		for _, e := range p.Permissions.Groups {
+			ops = append(ops, permissionOp{
+				target: key, kind: "group", name: e.Name,
+				input: models.ExtendedProject{Key: key, Permissions: &models.Permissions{Groups: []models.PermissionEntry{e}}},
+				exec: func() (*http.Response, error) {
+					return c.api.ProjectAPI.SetPermissionForGroups1(c.authCtx(ctx), key).Name(e.Name).Permission(e.Permission).Execute()
+				},
+			})
+		}
+	}
+	return c.runPermissionOps(ctx, "grant", ops)
+}
+
+// RevokeProjectPermissions revokes all permissions of every listed user and group on the projects
+func (c *Client) RevokeProjectPermissions(ctx context.Context, projects []models.ExtendedProject) error {
+	var ops []permissionOp
+	for _, p := range projects {
+		if p.Permissions == nil {
+			continue
+		}
+		key := p.Key
+		for _, e := range p.Permissions.Users {
+			ops = append(ops, permissionOp{
+				target: key, kind: "user", name: e.Name,
+				input: models.ExtendedProject{Key: key, Permissions: &models.Permissions{Users: []models.PermissionEntry{e}}},
+				exec: func() (*http.Response, error) {
+					return c.api.ProjectAPI.RevokePermissionsForUser1(c.authCtx(ctx), key).Name(e.Name).Execute()
+				},
+			})
+		}
+		for _, e := range p.Permissions.Groups {
+			ops = append(ops, permissionOp{
+				target: key, kind: "group", name: e.Name,
+				input: models.ExtendedProject{Key: key, Permissions: &models.Permissions{Groups: []models.PermissionEntry{e}}},
+				exec: func() (*http.Response, error) {
+					return c.api.ProjectAPI.RevokePermissionsForGroup1(c.authCtx(ctx), key).Name(e.Name).Execute()
+				},
+			})
+		}
+	}
+	return c.runPermissionOps(ctx, "revoke", ops)
+}
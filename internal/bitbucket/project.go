@@ -1,6 +1,7 @@
 package bitbucket
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -10,14 +11,14 @@ import (
 )
 
 // GetAllProjects fetches all projects from Bitbucket with pagination
-func GetAllProjects(c *Client) ([]openapi.RestProject, error) {
+func GetAllProjects(ctx context.Context, c *Client) ([]openapi.RestProject, error) {
 	var (
 		projects []openapi.RestProject
 		start    float32 = 0
 	)
 
 	for {
-		resp, httpResp, err := c.api.ProjectAPI.GetProjects(c.authCtx).
+		resp, httpResp, err := c.api.ProjectAPI.GetProjects(c.authCtx(ctx)).
 			Start(start).
 			Limit(float32(c.config.PageSize)).
 			Execute()
@@ -41,7 +42,7 @@ func GetAllProjects(c *Client) ([]openapi.RestProject, error) {
 }
 
 // GetProjects fetches specific projects by keys in parallel
-func (c *Client) GetProjects(keys []string) ([]openapi.RestProject, error) {
+func (c *Client) GetProjects(ctx context.Context, keys []string) ([]openapi.RestProject, error) {
 	maxWorkers := config.GlobalMaxWorkers
 
 	type job struct {
@@ -59,7 +60,11 @@ func (c *Client) GetProjects(keys []string) ([]openapi.RestProject, error) {
 	for range maxWorkers {
 		go func() {
 			for j := range jobsCh {
-				resp, httpResp, err := c.api.ProjectAPI.GetProject(c.authCtx, j.key).Execute()
+				if err := c.notRun(ctx, "key", j.key); err != nil {
+					resultsCh <- result{err: err}
+					continue
+				}
+				resp, httpResp, err := c.api.ProjectAPI.GetProject(c.authCtx(ctx), j.key).Execute()
 				if err != nil && httpResp != nil {
 					c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
 				}
@@ -98,7 +103,7 @@ func (c *Client) GetProjects(keys []string) ([]openapi.RestProject, error) {
 }
 
 // DeleteProjects deletes multiple projects by keys in parallel
-func (c *Client) DeleteProjects(keys []string) error {
+func (c *Client) DeleteProjects(ctx context.Context, keys []string) error {
 	type result struct {
 		key string
 		err error
@@ -113,7 +118,15 @@ func (c *Client) DeleteProjects(keys []string) error {
 	for range maxWorkers {
 		go func() {
 			for k := range jobsCh {
-				httpResp, err := c.api.ProjectAPI.DeleteProject(c.authCtx, k).Execute()
+				if err := c.notRun(ctx, "key", k); err != nil {
+					resultsCh <- result{key: k, err: err}
+					continue
+				}
+				if c.config.DryRun {
+					resultsCh <- result{key: k, err: c.preview("delete", "project", k, c.lookupProject(ctx, k))}
+					continue
+				}
+				httpResp, err := c.api.ProjectAPI.DeleteProject(c.authCtx(ctx), k).Execute()
 				if err != nil && httpResp != nil {
 					c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
 				}
@@ -136,11 +149,12 @@ func (c *Client) DeleteProjects(keys []string) error {
 	var errorsCount int
 	for i := 0; i < len(keys); i++ {
 		r := <-resultsCh
+		c.Record("delete", "project", r.key, openapi.RestProject{Key: &r.key}, r.err)
 		if r.err != nil {
 			c.logger.Error("Failed to delete project", "key", r.key, "error", r.err)
 			errorsCount++
 		} else {
-			c.logger.Info("Deleted project", "key", r.key)
+			c.logChanged("Deleted project", "key", r.key)
 		}
 	}
 
@@ -151,7 +165,7 @@ func (c *Client) DeleteProjects(keys []string) error {
 }
 
 // CreateProjects creates multiple projects in parallel
-func (c *Client) CreateProjects(projects []openapi.RestProject) ([]openapi.RestProject, error) {
+func (c *Client) CreateProjects(ctx context.Context, projects []openapi.RestProject) ([]openapi.RestProject, error) {
 	type result struct {
 		index   int
 		project *openapi.RestProject
@@ -175,7 +189,16 @@ func (c *Client) CreateProjects(projects []openapi.RestProject) ([]openapi.RestP
 		go func() {
 			defer wg.Done()
 			for j := range jobs {
-				created, httpResp, err := c.api.ProjectAPI.CreateProject(c.authCtx).
+				if err := c.notRun(ctx, "key", j.project.GetKey()); err != nil {
+					resultsCh <- result{index: j.index, project: &j.project, err: err}
+					continue
+				}
+				if c.config.DryRun {
+					err := c.preview("create", "project", j.project.GetKey(), c.lookupNewProject(ctx, j.project.GetKey()))
+					resultsCh <- result{index: j.index, project: &j.project, err: err}
+					continue
+				}
+				created, httpResp, err := c.api.ProjectAPI.CreateProject(c.authCtx(ctx)).
 					RestProject(j.project).
 					Execute()
 				if err != nil {
@@ -206,6 +229,7 @@ func (c *Client) CreateProjects(projects []openapi.RestProject) ([]openapi.RestP
 	createdProjects := make([]openapi.RestProject, len(projects))
 	var errorsCount int
 	for r := range resultsCh {
+		c.Record("create", "project", utils.SafeValue(projects[r.index].Key), projects[r.index], r.err)
 		if r.err != nil {
 			c.logger.Error("Failed to create project", "key", utils.SafeValue(r.project.Key), "error", r.err)
 			errorsCount++
@@ -214,7 +238,7 @@ func (c *Client) CreateProjects(projects []openapi.RestProject) ([]openapi.RestP
 				createdProjects[r.index] = *r.project
 			}
 		} else {
-			c.logger.Info("Created project", "key", utils.SafeValue(r.project.Key))
+			c.logChanged("Created project", "key", utils.SafeValue(r.project.Key))
 			createdProjects[r.index] = *r.project
 		}
 	}
@@ -226,7 +250,7 @@ func (c *Client) CreateProjects(projects []openapi.RestProject) ([]openapi.RestP
 }
 
 // UpdateProjects updates multiple projects in parallel
-func (c *Client) UpdateProjects(projects []openapi.RestProject) ([]openapi.RestProject, error) {
+func (c *Client) UpdateProjects(ctx context.Context, projects []openapi.RestProject) ([]openapi.RestProject, error) {
 	type result struct {
 		index   int
 		project *openapi.RestProject
@@ -250,12 +274,22 @@ func (c *Client) UpdateProjects(projects []openapi.RestProject) ([]openapi.RestP
 		go func() {
 			defer wg.Done()
 			for j := range jobs {
+				if err := c.notRun(ctx, "key", j.project.GetKey()); err != nil {
+					resultsCh <- result{index: j.index, project: &j.project, err: err}
+					continue
+				}
 				if j.project.Key == nil {
 					resultsCh <- result{index: j.index, project: &j.project, err: fmt.Errorf("project key is required")}
 					continue
 				}
 
-				updated, httpResp, err := c.api.ProjectAPI.UpdateProject(c.authCtx, *j.project.Key).
+				if c.config.DryRun {
+					err := c.preview("update", "project", *j.project.Key, c.lookupProject(ctx, *j.project.Key))
+					resultsCh <- result{index: j.index, project: &j.project, err: err}
+					continue
+				}
+
+				updated, httpResp, err := c.api.ProjectAPI.UpdateProject(c.authCtx(ctx), *j.project.Key).
 					RestProject(j.project).
 					Execute()
 				if err != nil && httpResp != nil {
@@ -286,6 +320,7 @@ func (c *Client) UpdateProjects(projects []openapi.RestProject) ([]openapi.RestP
 	updatedProjects := make([]openapi.RestProject, len(projects))
 	var errorsCount int
 	for r := range resultsCh {
+		c.Record("update", "project", utils.SafeValue(projects[r.index].Key), projects[r.index], r.err)
 		if r.err != nil {
 			c.logger.Error("Failed to update project", "key", utils.SafeValue(r.project.Key), "error", r.err)
 			errorsCount++
@@ -294,7 +329,7 @@ func (c *Client) UpdateProjects(projects []openapi.RestProject) ([]openapi.RestP
 				updatedProjects[r.index] = *r.project
 			}
 		} else {
-			c.logger.Info("Updated project", "key", utils.SafeValue(r.project.Key))
+			c.logChanged("Updated project", "key", utils.SafeValue(r.project.Key))
 			updatedProjects[r.index] = *r.project
 		}
 	}
@@ -304,3 +339,19 @@ func (c *Client) UpdateProjects(projects []openapi.RestProject) ([]openapi.RestP
 	}
 	return updatedProjects, nil
 }
+
+// AreProjectsEqual reports whether the fields set in desired match the live project.
+// Fields missing from desired are not managed and are ignored; missing live values
+// are treated as empty, since Bitbucket omits empty descriptions.
+func AreProjectsEqual(live, desired openapi.RestProject) bool {
+	if desired.Name != nil && utils.SafeValue(live.Name) != *desired.Name {
+		return false
+	}
+	if desired.Description != nil && utils.SafeValue(live.Description) != *desired.Description {
+		return false
+	}
+	if desired.Public != nil && utils.SafeValue(live.Public) != *desired.Public {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package bitbucket
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vinisman/bbctl/internal/config"
+)
+
+var (
+	sharedLimiter     *rateLimiter
+	sharedLimiterOnce sync.Once
+)
+
+// getRateLimiter returns the process-wide limiter, nil when rate limiting is off.
+// All clients share it so every worker pool draws from the same budget.
+func getRateLimiter(cfg *config.Config) *rateLimiter {
+	sharedLimiterOnce.Do(func() {
+		if cfg.RateLimit > 0 {
+			sharedLimiter = newRateLimiter(cfg.RateLimit, cfg.RateBurst)
+		}
+	})
+	return sharedLimiter
+}
+
+// rateLimiter is a token bucket refilled with rate tokens per second up to burst
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// reserve takes a token and returns how long to wait before using it
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token that was not used
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	l.tokens = min(l.burst, l.tokens+1)
+	l.mu.Unlock()
+}
+
+// rateLimitTransport waits for a token of the limiter before each request
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter *rateLimiter
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := t.limiter.reserve(); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			t.limiter.cancel()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,321 @@
+package bitbucket
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrNoRecording is returned in replay mode for a request that was not recorded
+var ErrNoRecording = errors.New("no recorded response")
+
+// redacted replaces credentials in recordings
+const redacted = "REDACTED"
+
+// sensitiveHeaders are redacted in recorded requests and responses
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// exchange is a recorded request and its response, stored as one JSON file
+type exchange struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type recordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// recordTransport writes every request sent over the network and its response to
+// a numbered file in dir, with credentials and secrets redacted. Each retry attempt is recorded,
+// so a replay goes through the same retries.
+type recordTransport struct {
+	next   http.RoundTripper
+	dir    string
+	logger *slog.Logger
+
+	mu  sync.Mutex
+	seq int
+}
+
+// newRecordTransport creates dir if needed; numbering continues after the
+// recordings already in it
+func newRecordTransport(next http.RoundTripper, dir string, logger *slog.Logger) (*recordTransport, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create record directory: %w", err)
+	}
+	files, err := recordings(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &recordTransport{next: next, dir: dir, logger: logger, seq: len(files)}, nil
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	out := req.Clone(req.Context())
+	if body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := t.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	ex := exchange{
+		Request: recordedRequest{
+			Method: req.Method,
+			URL:    redactURL(req.URL).String(),
+			Header: redactHeader(out.Header),
+			Body:   redactBody(body),
+		},
+		Response: recordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     redactHeader(resp.Header),
+			Body:       redactBody(respBody),
+		},
+	}
+	if err := t.save(ex); err != nil {
+		t.logger.Warn("Failed to record request", "method", req.Method, "url", req.URL.Redacted(), "error", err)
+	}
+	return resp, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// save writes the exchange to the next numbered file, named after the request
+func (t *recordTransport) save(ex exchange) error {
+	t.mu.Lock()
+	t.seq++
+	n := t.seq
+	t.mu.Unlock()
+
+	u, _ := url.Parse(ex.Request.URL)
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	name := strings.Join(segments[max(len(segments)-3, 0):], "_")
+	name = unsafeFileChars.ReplaceAllString(name, "_")
+	if len(name) > 60 {
+		name = name[:60]
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(ex); err != nil {
+		return err
+	}
+	file := filepath.Join(t.dir, fmt.Sprintf("%05d-%s-%s.json", n, ex.Request.Method, name))
+	t.logger.Debug("Recording request", "method", ex.Request.Method, "url", ex.Request.URL, "file", file)
+	return os.WriteFile(file, buf.Bytes(), 0o600)
+}
+
+// replayTransport answers requests with the responses recorded in a directory
+// instead of sending them. Requests are matched by method, path, query and body,
+// the host is ignored. Identical requests get their responses in recorded order,
+// the last one is served again once they are used up.
+type replayTransport struct {
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	responses map[string][]recordedResponse // by requestKey
+}
+
+func newReplayTransport(dir string, logger *slog.Logger) (*replayTransport, error) {
+	files, err := recordings(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recordings found in %s", dir)
+	}
+
+	t := &replayTransport{logger: logger, responses: map[string][]recordedResponse{}}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recording: %w", err)
+		}
+		var ex exchange
+		if err := json.Unmarshal(data, &ex); err != nil {
+			return nil, fmt.Errorf("invalid recording %s: %w", file, err)
+		}
+		u, err := url.Parse(ex.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recording %s: %w", file, err)
+		}
+		key := requestKey(ex.Request.Method, u, ex.Request.Body)
+		t.responses[key] = append(t.responses[key], ex.Response)
+	}
+	logger.Debug("Replaying recorded requests", "dir", dir, "recordings", len(files))
+	return t, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	key := requestKey(req.Method, redactURL(req.URL), redactBody(body))
+
+	t.mu.Lock()
+	queue := t.responses[key]
+	if len(queue) > 1 {
+		t.responses[key] = queue[1:]
+	}
+	t.mu.Unlock()
+	if len(queue) == 0 {
+		return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.RequestURI(), ErrNoRecording)
+	}
+
+	rec := queue[0]
+	t.logger.Debug("Replaying request", "method", req.Method, "url", req.URL.Redacted(), "status", rec.StatusCode)
+	header := rec.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+		StatusCode:    rec.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(rec.Body)),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}, nil
+}
+
+// recordings returns the recording files of dir in recorded order
+func recordings(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recordings: %w", err)
+	}
+	return files, nil
+}
+
+// requestKey identifies a request for replay; u and body must be redacted
+func requestKey(method string, u *url.URL, body string) string {
+	return method + " " + u.EscapedPath() + "?" + u.RawQuery + "\n" + body
+}
+
+// readBody reads and closes the request body, nil when there is none
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
+
+// secretNames are parts of the names of query parameters and JSON fields whose
+// values are redacted, e.g. password, configuration.secret of webhooks and
+// token of access tokens
+var secretNames = []string{"password", "passwd", "secret", "token", "credential", "apikey", "api_key", "privatekey", "private_key"}
+
+// isSecret reports whether a query parameter or JSON field holds a secret
+func isSecret(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range secretNames {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for _, k := range sensitiveHeaders {
+		if out.Get(k) != "" {
+			out.Set(k, redacted)
+		}
+	}
+	return out
+}
+
+// redactURL returns the URL without user info and with secret parameters
+// redacted; the query is sorted so it can be compared
+func redactURL(u *url.URL) *url.URL {
+	out := *u
+	out.User = nil
+	q := out.Query()
+	for k := range q {
+		if isSecret(k) {
+			q[k] = []string{redacted}
+		}
+	}
+	out.RawQuery = q.Encode()
+	return &out
+}
+
+// redactBody returns the body with secret fields of JSON redacted,
+// other bodies as they are
+func redactBody(body []byte) string {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || !redactJSON(v) {
+		return string(body)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return string(body)
+	}
+	return string(out)
+}
+
+// redactJSON redacts secret fields in place and reports whether it found any.
+// Only strings are replaced, objects such as the credentials of a webhook are
+// redacted field by field so a replayed response still decodes.
+func redactJSON(v any) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]any:
+		for k, field := range v {
+			if s, ok := field.(string); ok && s != "" && isSecret(k) {
+				v[k] = redacted
+				changed = true
+			} else if redactJSON(field) {
+				changed = true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if redactJSON(item) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
@@ -0,0 +1,136 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestIsSecret(t *testing.T) {
+	for name, want := range map[string]bool{
+		"password":         true,
+		"newPassword":      true,
+		"secret":           true,
+		"token":            true,
+		"accessToken":      true,
+		"credentials":      true,
+		"apiKey":           true,
+		"private_key":      true,
+		"name":             false,
+		"projectKey":       false,
+		"url":              false,
+		"sslVerification":  false,
+		"scopeType":        false,
+		"repositorySlug":   false,
+		"emailAddress":     false,
+		"requiredApproval": false,
+	} {
+		if got := isSecret(name); got != want {
+			t.Errorf("isSecret(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	body := `{"name":"ci","url":"https://ci/hook?a=1&b=2","configuration":{"secret":"s3cret"},` +
+		`"credentials":{"username":"bot","password":"p4ss"},"token":"t0ken","empty":{"token":""},"values":[{"apiKey":"k3y"}]}`
+	got := redactBody([]byte(body))
+	for _, secret := range []string{"s3cret", "p4ss", "t0ken", "k3y"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("redacted body %s contains %s", got, secret)
+		}
+	}
+
+	var v struct {
+		Name        string            `json:"name"`
+		URL         string            `json:"url"`
+		Credentials map[string]string `json:"credentials"`
+		Empty       map[string]string `json:"empty"`
+	}
+	if err := json.Unmarshal([]byte(got), &v); err != nil {
+		t.Fatalf("redacted body does not decode: %v", err)
+	}
+	if v.Name != "ci" || v.URL != "https://ci/hook?a=1&b=2" || v.Credentials["username"] != "bot" || v.Credentials["password"] != redacted {
+		t.Errorf("redacted body = %s, want only secrets replaced", got)
+	}
+	if v.Empty["token"] != "" {
+		t.Errorf("empty token redacted: %s", got)
+	}
+
+	if got := redactBody([]byte("not json")); got != "not json" {
+		t.Errorf("non-JSON body = %q, want it unchanged", got)
+	}
+}
+
+func TestRecordRedactsAndReplays(t *testing.T) {
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	response := `{"id":1,"name":"ci","configuration":{"secret":"hook-s3cret"},"token":"resp-t0ken"}`
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusCreated,
+			Header:     http.Header{"Set-Cookie": {"session=c00kie"}},
+			Body:       io.NopCloser(strings.NewReader(response)),
+			Request:    req,
+		}, nil
+	})
+	rec, err := newRecordTransport(next, dir, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := `{"name":"ci","configuration":{"secret":"hook-s3cret"}}`
+	newRequest := func() *http.Request {
+		u, _ := url.Parse("https://admin:pw@bitbucket.example.com/rest/api/latest/projects/PRJ/repos/r/webhooks?password=qu3ry")
+		req, _ := http.NewRequest(http.MethodPost, u.String(), strings.NewReader(request))
+		req.Header.Set("Authorization", "Bearer b34rer")
+		return req
+	}
+	resp, err := rec.RoundTrip(newRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != response {
+		t.Errorf("recording changed the response to %s", body)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("got %d recordings, want 1", len(files))
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"hook-s3cret", "resp-t0ken", "c00kie", "b34rer", "qu3ry", "admin:pw"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("recording contains %s:\n%s", secret, data)
+		}
+	}
+
+	replay, err := newReplayTransport(dir, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = replay.RoundTrip(newRequest())
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	var got map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusCreated || got["name"] != "ci" || got["token"] != redacted {
+		t.Errorf("replayed %d %v", resp.StatusCode, got)
+	}
+}
@@ -1,14 +1,13 @@
 package bitbucket
 
 import (
-	"crypto/tls"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/vinisman/bbctl/internal/config"
 	"github.com/vinisman/bbctl/internal/models"
@@ -19,14 +18,14 @@ import (
 
 // GetAllReposForProject fetches all repositories for a single project with pagination
 // and optionally fills DefaultBranch and Webhooks for each repository
-func (c *Client) GetAllReposForProject(projectKey string, options models.RepositoryOptions) ([]models.ExtendedRepository, error) {
+func (c *Client) GetAllReposForProject(ctx context.Context, projectKey string, options models.RepositoryOptions) ([]models.ExtendedRepository, error) {
 	var (
 		repos []models.ExtendedRepository
 		start float32 = 0
 	)
 
 	for {
-		resp, httpResp, err := c.api.ProjectAPI.GetRepositories(c.authCtx, projectKey).
+		resp, httpResp, err := c.api.ProjectAPI.GetRepositories(c.authCtx(ctx), projectKey).
 			Start(start).
 			Limit(float32(c.config.PageSize)).
 			Execute()
@@ -78,7 +77,11 @@ func (c *Client) GetAllReposForProject(projectKey string, options models.Reposit
 		for w := 0; w < maxWorkers; w++ {
 			go func() {
 				for i := range jobsCh {
-					r, err := c.enrichRepository(repos[i], projectKey, options)
+					if err := c.notRun(ctx, "project", repos[i].ProjectKey, "slug", repos[i].RepositorySlug); err != nil {
+						resultsCh <- enrichResult{idx: i, repo: repos[i], err: err}
+						continue
+					}
+					r, err := c.enrichRepository(ctx, repos[i], projectKey, options)
 					resultsCh <- enrichResult{idx: i, repo: r, err: err}
 				}
 			}()
@@ -107,7 +110,7 @@ func (c *Client) GetAllReposForProject(projectKey string, options models.Reposit
 }
 
 // GetReposBySlugs fetches specific repositories by project key and slugs in parallel
-func (c *Client) GetReposBySlugs(projectKey string, slugs []string, options models.RepositoryOptions) ([]models.ExtendedRepository, error) {
+func (c *Client) GetReposBySlugs(ctx context.Context, projectKey string, slugs []string, options models.RepositoryOptions) ([]models.ExtendedRepository, error) {
 	type result struct {
 		repo models.ExtendedRepository
 		err  error
@@ -122,8 +125,12 @@ func (c *Client) GetReposBySlugs(projectKey string, slugs []string, options mode
 	for w := 0; w < maxWorkers; w++ {
 		go func() {
 			for slug := range jobsCh {
+				if err := c.notRun(ctx, "project", projectKey, "slug", slug); err != nil {
+					resultsCh <- result{err: err}
+					continue
+				}
 				// Always fetch repository to verify it exists and belongs to the correct project.
-				resp, httpResp, err := c.api.ProjectAPI.GetRepository(c.authCtx, projectKey, slug).Execute()
+				resp, httpResp, err := c.api.ProjectAPI.GetRepository(c.authCtx(ctx), projectKey, slug).Execute()
 				if err != nil && httpResp != nil {
 					c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
 				}
@@ -154,7 +161,7 @@ func (c *Client) GetReposBySlugs(projectKey string, slugs []string, options mode
 					}
 				}
 
-				enriched, err := c.enrichRepository(r, projectKey, options)
+				enriched, err := c.enrichRepository(ctx, r, projectKey, options)
 				resultsCh <- result{repo: enriched, err: err}
 			}
 		}()
@@ -186,7 +193,7 @@ func (c *Client) GetReposBySlugs(projectKey string, slugs []string, options mode
 }
 
 // GetAllRepos fetches all repositories for multiple projects in parallel with worker pool
-func (c *Client) GetAllRepos(projectKeys []string, options models.RepositoryOptions) ([]models.ExtendedRepository, error) {
+func (c *Client) GetAllRepos(ctx context.Context, projectKeys []string, options models.RepositoryOptions) ([]models.ExtendedRepository, error) {
 	type result struct {
 		repos []models.ExtendedRepository
 		err   error
@@ -201,7 +208,11 @@ func (c *Client) GetAllRepos(projectKeys []string, options models.RepositoryOpti
 	for range maxWorkers {
 		go func() {
 			for pk := range jobsCh {
-				repos, err := c.GetAllReposForProject(pk, options)
+				if err := c.notRun(ctx, "project", pk); err != nil {
+					resultsCh <- result{err: err}
+					continue
+				}
+				repos, err := c.GetAllReposForProject(ctx, pk, options)
 				if err != nil {
 					c.logger.Error("Failed fetching repositories for project", "project", pk, "error", err)
 					resultsCh <- result{err: err}
@@ -240,13 +251,13 @@ func (c *Client) GetAllRepos(projectKeys []string, options models.RepositoryOpti
 }
 
 // GetDefaultBranch fetches the default branch for a repository
-func (c *Client) GetDefaultBranch(projectKey, repoSlug string) (string, error) {
+func (c *Client) GetDefaultBranch(ctx context.Context, projectKey, repoSlug string) (string, error) {
 	if projectKey == "" || repoSlug == "" {
 		return "", fmt.Errorf("projectKey and repoSlug must be provided")
 	}
 
 	resp, httpResp, err := c.api.ProjectAPI.
-		GetDefaultBranch2(c.authCtx, projectKey, repoSlug).
+		GetDefaultBranch2(c.authCtx(ctx), projectKey, repoSlug).
 		Execute()
 	if err != nil && httpResp != nil {
 		c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
@@ -265,7 +276,7 @@ func (c *Client) GetDefaultBranch(projectKey, repoSlug string) (string, error) {
 	return "", nil
 }
 
-func (c *Client) GetManifest(projectKey, repoSlug, filePath string) (map[string]any, error) {
+func (c *Client) GetManifest(ctx context.Context, projectKey, repoSlug, filePath string) (map[string]any, error) {
 	if projectKey == "" || repoSlug == "" || filePath == "" {
 		return nil, fmt.Errorf("projectKey, repoSlug and filePath must be provided")
 	}
@@ -274,7 +285,7 @@ func (c *Client) GetManifest(projectKey, repoSlug, filePath string) (map[string]
 	url := fmt.Sprintf("%s/api/1.0/projects/%s/repos/%s/raw/%s",
 		strings.TrimRight(baseURL, "/"), projectKey, repoSlug, filePath)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -284,20 +295,8 @@ func (c *Client) GetManifest(projectKey, repoSlug, filePath string) (map[string]
 		req.Header.Set(k, v)
 	}
 
-	// Ensure httpClient is set and has a reasonable timeout
-	if c.api.GetConfig().HTTPClient == nil {
-		timeout := time.Duration(15 * 1e9) // 15 seconds
-		httpClient := &http.Client{Timeout: timeout}
-		if config.GlobalCfg.Insecure {
-			transport := &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			}
-			httpClient.Transport = transport
-		}
-		c.api.GetConfig().HTTPClient = httpClient
-	}
-
-	resp, err := c.api.GetConfig().HTTPClient.Do(req)
+	// Use the shared client so TLS, proxy, retries and rate limit apply
+	resp, err := c.client.Do(req)
 	if err != nil {
 		c.logger.Error("Failed to fetch file",
 			"projectKey", projectKey,
@@ -336,7 +335,7 @@ func (c *Client) GetManifest(projectKey, repoSlug, filePath string) (map[string]
 }
 
 // DeleteRepos deletes multiple repositories by project + slug in parallel
-func (c *Client) DeleteRepos(refs []models.ExtendedRepository) error {
+func (c *Client) DeleteRepos(ctx context.Context, refs []models.ExtendedRepository) error {
 	type result struct {
 		project string
 		slug    string
@@ -352,7 +351,16 @@ func (c *Client) DeleteRepos(refs []models.ExtendedRepository) error {
 	for range maxWorkers {
 		go func() {
 			for ref := range jobsCh {
-				httpResp, err := c.api.ProjectAPI.DeleteRepository(c.authCtx, ref.ProjectKey, ref.RepositorySlug).Execute()
+				if err := c.notRun(ctx, "project", ref.ProjectKey, "slug", ref.RepositorySlug); err != nil {
+					resultsCh <- result{project: ref.ProjectKey, slug: ref.RepositorySlug, err: err}
+					continue
+				}
+				if c.config.DryRun {
+					err := c.preview("delete", "repository", ref.ProjectKey+"/"+ref.RepositorySlug, c.lookupRepo(ctx, ref.ProjectKey, ref.RepositorySlug))
+					resultsCh <- result{project: ref.ProjectKey, slug: ref.RepositorySlug, err: err}
+					continue
+				}
+				httpResp, err := c.api.ProjectAPI.DeleteRepository(c.authCtx(ctx), ref.ProjectKey, ref.RepositorySlug).Execute()
 				if err != nil && httpResp != nil {
 					c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
 				}
@@ -374,6 +382,7 @@ func (c *Client) DeleteRepos(refs []models.ExtendedRepository) error {
 	var errorsCount int
 	for i := 0; i < len(refs); i++ {
 		r := <-resultsCh
+		c.Record("delete", "repository", r.project+"/"+r.slug, models.ExtendedRepository{ProjectKey: r.project, RepositorySlug: r.slug}, r.err)
 		if r.err != nil {
 			c.logger.Error("Failed to delete repository",
 				"project", r.project,
@@ -381,7 +390,7 @@ func (c *Client) DeleteRepos(refs []models.ExtendedRepository) error {
 				"error", r.err)
 			errorsCount++
 		} else {
-			c.logger.Info("Deleted repository",
+			c.logChanged("Deleted repository",
 				"project", r.project,
 				"slug", r.slug)
 		}
@@ -395,7 +404,7 @@ func (c *Client) DeleteRepos(refs []models.ExtendedRepository) error {
 }
 
 // CreateRepos creates multiple repositories in parallel
-func (c *Client) CreateRepos(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) CreateRepos(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	type result struct {
 		index int
 		repo  models.ExtendedRepository
@@ -408,12 +417,26 @@ func (c *Client) CreateRepos(repos []models.ExtendedRepository) ([]models.Extend
 	for i, r := range repos {
 		go func(index int, repo models.ExtendedRepository) {
 			// Validate required fields
-			if repo.RestRepository.Name == nil || repo.ProjectKey == "" {
+			if repo.RestRepository == nil || repo.RestRepository.Name == nil || repo.ProjectKey == "" {
 				resultsCh <- result{index: index, repo: repo, err: fmt.Errorf("name and projectKey are required")}
 				return
 			}
+			if err := c.notRun(ctx, "project", repo.ProjectKey, "name", *repo.RestRepository.Name); err != nil {
+				resultsCh <- result{index: index, repo: repo, err: err}
+				return
+			}
 
-			created, httpResp, err := c.api.ProjectAPI.CreateRepository(c.authCtx, repo.ProjectKey).
+			if c.config.DryRun {
+				err := c.lookupProject(ctx, repo.ProjectKey)
+				if err == nil {
+					err = c.lookupNewRepo(ctx, repo.ProjectKey, *repo.RestRepository.Name)
+				}
+				err = c.preview("create", "repository", repo.ProjectKey+"/"+*repo.RestRepository.Name, err)
+				resultsCh <- result{index: index, repo: repo, err: err}
+				return
+			}
+
+			created, httpResp, err := c.api.ProjectAPI.CreateRepository(c.authCtx(ctx), repo.ProjectKey).
 				RestRepository(*repo.RestRepository).
 				Execute()
 			if err != nil && httpResp != nil {
@@ -434,13 +457,14 @@ func (c *Client) CreateRepos(repos []models.ExtendedRepository) ([]models.Extend
 	var errorsCount int
 	for i := 0; i < len(repos); i++ {
 		r := <-resultsCh
+		c.Record("create", "repository", r.repo.ProjectKey+"/"+inputRepoName(r.repo), r.repo, r.err)
 		if r.err != nil {
-			c.logger.Error("Failed to create repository", "slug", utils.SafeValue(r.repo.RestRepository.Slug), "name", utils.SafeValue(r.repo.RestRepository.Name), "error", r.err)
+			c.logger.Error("Failed to create repository", "project", r.repo.ProjectKey, "name", inputRepoName(r.repo), "error", r.err)
 			errorsCount++
 			// Keep original repository in case of error
 			createdRepos[r.index] = r.repo
 		} else {
-			c.logger.Info("Created repository", "slug", utils.SafeValue(r.repo.RestRepository.Slug), "name", utils.SafeValue(r.repo.RestRepository.Name))
+			c.logChanged("Created repository", "slug", utils.SafeValue(r.repo.RestRepository.Slug), "name", utils.SafeValue(r.repo.RestRepository.Name))
 			createdRepos[r.index] = r.repo
 		}
 	}
@@ -451,8 +475,16 @@ func (c *Client) CreateRepos(repos []models.ExtendedRepository) ([]models.Extend
 	return createdRepos, nil
 }
 
+// inputRepoName returns restRepository.name of an input entry, empty if it has none
+func inputRepoName(r models.ExtendedRepository) string {
+	if r.RestRepository == nil {
+		return ""
+	}
+	return utils.SafeValue(r.RestRepository.Name)
+}
+
 // UpdateRepos updates multiple repositories in parallel by project.key + slug
-func (c *Client) UpdateRepos(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) UpdateRepos(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	type result struct {
 		index int
 		repo  models.ExtendedRepository
@@ -476,13 +508,23 @@ func (c *Client) UpdateRepos(repos []models.ExtendedRepository) ([]models.Extend
 		go func() {
 			defer wg.Done()
 			for j := range jobs {
+				if err := c.notRun(ctx, "project", j.repo.ProjectKey, "slug", j.repo.RepositorySlug); err != nil {
+					resultsCh <- result{index: j.index, repo: j.repo, err: err}
+					continue
+				}
 				// Validate required fields for update
-				if j.repo.ProjectKey == "" || j.repo.RepositorySlug == "" {
-					resultsCh <- result{index: j.index, repo: j.repo, err: fmt.Errorf("project.key and slug are required for update")}
+				if j.repo.ProjectKey == "" || j.repo.RepositorySlug == "" || j.repo.RestRepository == nil {
+					resultsCh <- result{index: j.index, repo: j.repo, err: fmt.Errorf("project.key, slug and restRepository are required for update")}
+					continue
+				}
+
+				if c.config.DryRun {
+					err := c.preview("update", "repository", j.repo.ProjectKey+"/"+j.repo.RepositorySlug, c.lookupRepo(ctx, j.repo.ProjectKey, j.repo.RepositorySlug))
+					resultsCh <- result{index: j.index, repo: j.repo, err: err}
 					continue
 				}
 
-				updated, httpResp, err := c.api.ProjectAPI.UpdateRepository(c.authCtx, j.repo.ProjectKey, j.repo.RepositorySlug).
+				updated, httpResp, err := c.api.ProjectAPI.UpdateRepository(c.authCtx(ctx), j.repo.ProjectKey, j.repo.RepositorySlug).
 					RestRepository(*j.repo.RestRepository).
 					Execute()
 				if err != nil && httpResp != nil {
@@ -513,13 +555,14 @@ func (c *Client) UpdateRepos(repos []models.ExtendedRepository) ([]models.Extend
 	updatedRepos := make([]models.ExtendedRepository, len(repos))
 	var errorsCount int
 	for r := range resultsCh {
+		c.Record("update", "repository", r.repo.ProjectKey+"/"+r.repo.RepositorySlug, r.repo, r.err)
 		if r.err != nil {
 			c.logger.Error("Failed to update repository", "slug", r.repo.RepositorySlug, "error", r.err)
 			errorsCount++
 			// Keep original repository in case of error
 			updatedRepos[r.index] = r.repo
 		} else {
-			c.logger.Info("Updated repository", "slug", r.repo.RepositorySlug)
+			c.logChanged("Updated repository", "slug", r.repo.RepositorySlug)
 			updatedRepos[r.index] = r.repo
 		}
 	}
@@ -531,7 +574,7 @@ func (c *Client) UpdateRepos(repos []models.ExtendedRepository) ([]models.Extend
 }
 
 // ForkRepos forks multiple repositories in parallel
-func (c *Client) ForkRepos(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) ForkRepos(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	type result struct {
 		index int
 		repo  models.ExtendedRepository
@@ -555,6 +598,10 @@ func (c *Client) ForkRepos(repos []models.ExtendedRepository) ([]models.Extended
 		go func() {
 			defer wg.Done()
 			for j := range jobs {
+				if err := c.notRun(ctx, "project", j.repo.ProjectKey, "slug", j.repo.RepositorySlug); err != nil {
+					resultsCh <- result{index: j.index, repo: j.repo, err: err}
+					continue
+				}
 				if j.repo.ProjectKey == "" || j.repo.RepositorySlug == "" {
 					resultsCh <- result{
 						index: j.index,
@@ -564,7 +611,13 @@ func (c *Client) ForkRepos(repos []models.ExtendedRepository) ([]models.Extended
 					continue
 				}
 
-				createdFork, httpResp, err := c.api.ProjectAPI.ForkRepository(c.authCtx, j.repo.ProjectKey, j.repo.RepositorySlug).
+				if c.config.DryRun {
+					err := c.preview("fork", "repository", j.repo.ProjectKey+"/"+j.repo.RepositorySlug, c.lookupRepo(ctx, j.repo.ProjectKey, j.repo.RepositorySlug))
+					resultsCh <- result{index: j.index, repo: j.repo, err: err}
+					continue
+				}
+
+				createdFork, httpResp, err := c.api.ProjectAPI.ForkRepository(c.authCtx(ctx), j.repo.ProjectKey, j.repo.RepositorySlug).
 					RestRepository(*j.repo.RestRepository).
 					Execute()
 				if err != nil && httpResp != nil {
@@ -595,6 +648,7 @@ func (c *Client) ForkRepos(repos []models.ExtendedRepository) ([]models.Extended
 	forkedRepos := make([]models.ExtendedRepository, len(repos))
 	var errorsCount int
 	for r := range resultsCh {
+		c.Record("fork", "repository", r.repo.ProjectKey+"/"+r.repo.RepositorySlug, r.repo, r.err)
 		if r.err != nil {
 			c.logger.Error("Failed to fork repository",
 				"sourceProject", r.repo.ProjectKey,
@@ -605,7 +659,7 @@ func (c *Client) ForkRepos(repos []models.ExtendedRepository) ([]models.Extended
 			// Keep original repository in case of error
 			forkedRepos[r.index] = r.repo
 		} else {
-			c.logger.Info("Forked repository",
+			c.logChanged("Forked repository",
 				"sourceProject", r.repo.ProjectKey,
 				"sourceSlug", r.repo.RepositorySlug,
 				"forkName", utils.SafeValue(r.repo.RestRepository.Name))
@@ -620,10 +674,10 @@ func (c *Client) ForkRepos(repos []models.ExtendedRepository) ([]models.Extended
 }
 
 // enrichRepository enriches the given ExtendedRepository with additional data according to options.
-func (c *Client) enrichRepository(r models.ExtendedRepository, projectKey string, options models.RepositoryOptions) (models.ExtendedRepository, error) {
+func (c *Client) enrichRepository(ctx context.Context, r models.ExtendedRepository, projectKey string, options models.RepositoryOptions) (models.ExtendedRepository, error) {
 	// Default branch
 	if options.DefaultBranch && r.RepositorySlug != "" {
-		b, err := c.GetDefaultBranch(projectKey, r.RepositorySlug)
+		b, err := c.GetDefaultBranch(ctx, projectKey, r.RepositorySlug)
 		if err == nil {
 			// write both flat field and nested restRepository.defaultBranch if repository requested
 			r.DefaultBranch = b
@@ -636,7 +690,7 @@ func (c *Client) enrichRepository(r models.ExtendedRepository, projectKey string
 	}
 	// Webhooks
 	if options.Webhooks && r.RepositorySlug != "" {
-		updated, err := c.GetWebhooks([]models.ExtendedRepository{r})
+		updated, err := c.GetWebhooks(ctx, []models.ExtendedRepository{r})
 		if err == nil && len(updated) > 0 {
 			r.Webhooks = updated[0].Webhooks
 		} else if err != nil {
@@ -645,7 +699,7 @@ func (c *Client) enrichRepository(r models.ExtendedRepository, projectKey string
 	}
 	// Required builds only
 	if options.RequiredBuilds && r.RepositorySlug != "" {
-		rbList, err := c.GetRequiredBuilds([]models.ExtendedRepository{r})
+		rbList, err := c.GetRequiredBuilds(ctx, []models.ExtendedRepository{r})
 		if err == nil && len(rbList) > 0 {
 			r.RequiredBuilds = rbList[0].RequiredBuilds
 		} else if err != nil {
@@ -654,7 +708,7 @@ func (c *Client) enrichRepository(r models.ExtendedRepository, projectKey string
 	}
 	// Get manifest content
 	if options.Manifest && r.RepositorySlug != "" && options.ManifestPath != nil {
-		manifest, err := c.GetManifest(projectKey, r.RepositorySlug, *options.ManifestPath)
+		manifest, err := c.GetManifest(ctx, projectKey, r.RepositorySlug, *options.ManifestPath)
 		if err == nil {
 			r.Manifest = &manifest
 		} else {
@@ -671,7 +725,7 @@ func (c *Client) enrichRepository(r models.ExtendedRepository, projectKey string
 	if options.ConfigFiles && r.RepositorySlug != "" && len(options.ConfigFileMap) > 0 {
 		configs := make(map[string]any, len(options.ConfigFileMap))
 		for key, configPath := range options.ConfigFileMap {
-			cfg, err := c.GetManifest(projectKey, r.RepositorySlug, configPath)
+			cfg, err := c.GetManifest(ctx, projectKey, r.RepositorySlug, configPath)
 			if err == nil {
 				configs[key] = cfg
 			} else {
@@ -692,12 +746,12 @@ func (c *Client) enrichRepository(r models.ExtendedRepository, projectKey string
 }
 
 // GetBranchPermissions fetches all branch permissions for multiple repositories
-func (c *Client) GetBranchPermissions(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) GetBranchPermissions(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	var errs []string
 
 	for i := range repos {
 		resp, httpResp, err := c.api.RepositoryAPI.
-			GetRestrictions1(c.authCtx, repos[i].ProjectKey, repos[i].RepositorySlug).
+			GetRestrictions1(c.authCtx(ctx), repos[i].ProjectKey, repos[i].RepositorySlug).
 			Execute()
 		if err != nil && httpResp != nil {
 			c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
@@ -717,8 +771,32 @@ func (c *Client) GetBranchPermissions(repos []models.ExtendedRepository) ([]mode
 	return repos, nil
 }
 
+// branchPermissionItem returns the report name of a branch permission and its
+// repository entry in the create/update input format, with users as names
+func branchPermissionItem(repo models.ExtendedRepository, p openapi.RestRefRestriction) (string, models.RepositoryInput) {
+	bp := openapi.RestRefRestrictionCreate{Id: p.Id, Type: p.Type, Matcher: p.Matcher, Scope: p.Scope, Groups: p.Groups, AccessKeys: p.AccessKeys}
+	for _, u := range p.Users {
+		if u.Name != nil {
+			bp.Users = append(bp.Users, *u.Name)
+		}
+	}
+	name := utils.SafeValue(p.Type)
+	if p.Matcher != nil {
+		name += " " + utils.SafeValue(p.Matcher.DisplayId)
+	}
+	if p.Id != nil {
+		name = "id " + utils.Int32PtrToString(p.Id)
+	}
+	input := models.RepositoryInput{
+		ProjectKey:        repo.ProjectKey,
+		RepositorySlug:    repo.RepositorySlug,
+		BranchPermissions: &[]openapi.RestRefRestrictionCreate{bp},
+	}
+	return repo.ProjectKey + "/" + repo.RepositorySlug + " " + name, input
+}
+
 // CreateBranchPermissions creates new branch permissions concurrently for multiple repositories
-func (c *Client) CreateBranchPermissions(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) CreateBranchPermissions(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	maxWorkers := config.GlobalMaxWorkers
 
 	type job struct {
@@ -753,6 +831,11 @@ func (c *Client) CreateBranchPermissions(repos []models.ExtendedRepository) ([]m
 	worker := func() {
 		defer wg.Done()
 		for j := range jobs {
+			item, input := branchPermissionItem(j.repo, j.permission)
+			if err := c.notRun(ctx, "project", j.repo.ProjectKey, "slug", j.repo.RepositorySlug); err != nil {
+				errCh <- c.Record("create", "branch permission", item, input, err)
+				continue
+			}
 			// Convert RestRefRestriction to RestRefRestrictionCreate
 			restriction := openapi.RestRefRestrictionCreate{
 				Type:    j.permission.Type,
@@ -767,8 +850,18 @@ func (c *Client) CreateBranchPermissions(repos []models.ExtendedRepository) ([]m
 				}
 			}
 
+			if c.config.DryRun {
+				if err := c.preview("create", "branch permission", item, c.lookupRepo(ctx, j.repo.ProjectKey, j.repo.RepositorySlug)); err != nil {
+					errCh <- c.Record("create", "branch permission", item, input, err)
+					continue
+				}
+				c.Record("create", "branch permission", item, input, nil)
+				resultsCh <- result{repoIndex: j.repoIndex, permission: j.permission}
+				continue
+			}
+
 			created, httpResp, err := c.api.RepositoryAPI.
-				CreateRestrictions1WithUserNames(c.authCtx, j.repo.ProjectKey, j.repo.RepositorySlug, []openapi.RestRefRestrictionCreate{restriction})
+				CreateRestrictions1WithUserNames(c.authCtx(ctx), j.repo.ProjectKey, j.repo.RepositorySlug, []openapi.RestRefRestrictionCreate{restriction})
 
 			if err != nil {
 				c.logger.Error("failed to create branch permission",
@@ -779,10 +872,12 @@ func (c *Client) CreateBranchPermissions(repos []models.ExtendedRepository) ([]m
 				if httpResp != nil {
 					c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
 				}
-				errCh <- err
+				errCh <- c.Record("create", "branch permission", item, input, err)
 				continue
 			}
 
+			c.Record("create", "branch permission", item, input, nil)
+
 			// created is []RestRefRestriction, take first one
 			if len(created) > 0 {
 				resultsCh <- result{repoIndex: j.repoIndex, permission: created[0]}
@@ -849,7 +944,7 @@ func (c *Client) CreateBranchPermissions(repos []models.ExtendedRepository) ([]m
 
 // UpdateBranchPermissions updates existing branch permissions concurrently
 // Uses the same CreateRestrictions1WithUserNames API as create (Bitbucket upsert behavior)
-func (c *Client) UpdateBranchPermissions(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) UpdateBranchPermissions(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	maxWorkers := config.GlobalMaxWorkers
 
 	type job struct {
@@ -884,8 +979,13 @@ func (c *Client) UpdateBranchPermissions(repos []models.ExtendedRepository) ([]m
 	worker := func() {
 		defer wg.Done()
 		for j := range jobs {
+			item, input := branchPermissionItem(j.repo, j.permission)
+			if err := c.notRun(ctx, "project", j.repo.ProjectKey, "slug", j.repo.RepositorySlug); err != nil {
+				errCh <- c.Record("update", "branch permission", item, input, err)
+				continue
+			}
 			if j.permission.Id == nil {
-				errCh <- fmt.Errorf("permission ID is required for update in %s/%s", j.repo.ProjectKey, j.repo.RepositorySlug)
+				errCh <- c.Record("update", "branch permission", item, input, fmt.Errorf("permission ID is required for update in %s/%s", j.repo.ProjectKey, j.repo.RepositorySlug))
 				continue
 			}
 
@@ -904,8 +1004,18 @@ func (c *Client) UpdateBranchPermissions(repos []models.ExtendedRepository) ([]m
 				}
 			}
 
+			if c.config.DryRun {
+				if err := c.preview("update", "branch permission", item, c.lookupBranchPermission(ctx, j.repo, utils.Int32PtrToString(j.permission.Id))); err != nil {
+					errCh <- c.Record("update", "branch permission", item, input, err)
+					continue
+				}
+				c.Record("update", "branch permission", item, input, nil)
+				resultsCh <- result{repoIndex: j.repoIndex, permission: j.permission}
+				continue
+			}
+
 			updated, httpResp, err := c.api.RepositoryAPI.
-				CreateRestrictions1WithUserNames(c.authCtx, j.repo.ProjectKey, j.repo.RepositorySlug, []openapi.RestRefRestrictionCreate{restriction})
+				CreateRestrictions1WithUserNames(c.authCtx(ctx), j.repo.ProjectKey, j.repo.RepositorySlug, []openapi.RestRefRestrictionCreate{restriction})
 
 			if err != nil {
 				if httpResp != nil {
@@ -915,13 +1025,16 @@ func (c *Client) UpdateBranchPermissions(repos []models.ExtendedRepository) ([]m
 							"project", j.repo.ProjectKey,
 							"repo", j.repo.RepositorySlug,
 							"id", utils.Int32PtrToString(j.permission.Id))
+						errCh <- c.Record("update", "branch permission", item, input, fmt.Errorf("branch permission %s not found in %s/%s, not updated: %w", utils.Int32PtrToString(j.permission.Id), j.repo.ProjectKey, j.repo.RepositorySlug, err))
 						continue
 					}
 				}
-				errCh <- fmt.Errorf("failed to update branch permission %s in %s/%s: %w", utils.Int32PtrToString(j.permission.Id), j.repo.ProjectKey, j.repo.RepositorySlug, err)
+				errCh <- c.Record("update", "branch permission", item, input, fmt.Errorf("failed to update branch permission %s in %s/%s: %w", utils.Int32PtrToString(j.permission.Id), j.repo.ProjectKey, j.repo.RepositorySlug, err))
 				continue
 			}
 
+			c.Record("update", "branch permission", item, input, nil)
+
 			// updated is []RestRefRestriction, take first one
 			if len(updated) > 0 {
 				resultsCh <- result{repoIndex: j.repoIndex, permission: updated[0]}
@@ -988,7 +1101,7 @@ func (c *Client) UpdateBranchPermissions(repos []models.ExtendedRepository) ([]m
 }
 
 // DeleteBranchPermissions deletes branch permissions concurrently by ID
-func (c *Client) DeleteBranchPermissions(repos []models.ExtendedRepository) error {
+func (c *Client) DeleteBranchPermissions(ctx context.Context, repos []models.ExtendedRepository) error {
 	maxWorkers := config.GlobalMaxWorkers
 
 	type job struct {
@@ -1012,18 +1125,34 @@ func (c *Client) DeleteBranchPermissions(repos []models.ExtendedRepository) erro
 	worker := func() {
 		defer wg.Done()
 		for j := range jobs {
+			item, input := repoItem(j.repo, "id "+utils.Int32PtrToString(j.permission.Id), func(r *models.ExtendedRepository) {
+				r.BranchPermissions = &[]openapi.RestRefRestriction{j.permission}
+			})
+			if err := c.notRun(ctx, "project", j.repo.ProjectKey, "slug", j.repo.RepositorySlug); err != nil {
+				errCh <- c.Record("delete", "branch permission", item, input, err)
+				continue
+			}
 			if j.permission.Id == nil {
 				c.logger.Error("Delete branch permission failed",
 					"project", j.repo.ProjectKey,
 					"repo", j.repo.RepositorySlug,
 					"id", nil,
 					"error", "permission ID is required")
-				errCh <- fmt.Errorf("delete permission failed: missing id")
+				errCh <- c.Record("delete", "branch permission", item, input, fmt.Errorf("delete permission failed: missing id"))
+				continue
+			}
+
+			if c.config.DryRun {
+				if err := c.preview("delete", "branch permission", item, c.lookupBranchPermission(ctx, j.repo, utils.Int32PtrToString(j.permission.Id))); err != nil {
+					errCh <- c.Record("delete", "branch permission", item, input, err)
+					continue
+				}
+				c.Record("delete", "branch permission", item, input, nil)
 				continue
 			}
 
 			httpResp, err := c.api.RepositoryAPI.
-				DeleteRestriction1(c.authCtx, j.repo.ProjectKey, utils.Int32PtrToString(j.permission.Id), j.repo.RepositorySlug).
+				DeleteRestriction1(c.authCtx(ctx), j.repo.ProjectKey, utils.Int32PtrToString(j.permission.Id), j.repo.RepositorySlug).
 				Execute()
 			if err != nil {
 				if httpResp != nil {
@@ -1033,6 +1162,7 @@ func (c *Client) DeleteBranchPermissions(repos []models.ExtendedRepository) erro
 							"project", j.repo.ProjectKey,
 							"repo", j.repo.RepositorySlug,
 							"id", utils.Int32PtrToString(j.permission.Id))
+						c.Record("delete", "branch permission", item, input, nil)
 						continue
 					}
 				}
@@ -1041,10 +1171,11 @@ func (c *Client) DeleteBranchPermissions(repos []models.ExtendedRepository) erro
 					"repo", j.repo.RepositorySlug,
 					"id", utils.Int32PtrToString(j.permission.Id),
 					"error", err)
-				errCh <- err
+				errCh <- c.Record("delete", "branch permission", item, input, err)
 				continue
 			}
 
+			c.Record("delete", "branch permission", item, input, nil)
 			c.logger.Info("Deleted branch permission (or was not present)",
 				"project", j.repo.ProjectKey,
 				"repo", j.repo.RepositorySlug,
@@ -1081,3 +1212,25 @@ func (c *Client) DeleteBranchPermissions(repos []models.ExtendedRepository) erro
 	}
 	return nil
 }
+
+// AreBranchPermissionsEqual compares two branch restrictions by relevant fields.
+// Users and groups are compared as sets because Bitbucket does not preserve their order.
+func AreBranchPermissionsEqual(a, b openapi.RestRefRestriction) bool {
+	if !equalStringPtr(a.Type, b.Type) {
+		return false
+	}
+	if !equalRefMatcher(a.Matcher, b.Matcher) {
+		return false
+	}
+	if !equalStringSets(a.Groups, b.Groups) {
+		return false
+	}
+	var usersA, usersB []string
+	for _, u := range a.Users {
+		usersA = append(usersA, utils.SafeValue(u.Name))
+	}
+	for _, u := range b.Users {
+		usersB = append(usersB, utils.SafeValue(u.Name))
+	}
+	return equalStringSets(usersA, usersB)
+}
@@ -0,0 +1,312 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vinisman/bbctl/internal/models"
+	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
+	"gopkg.in/yaml.v3"
+)
+
+// Item statuses of a report
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped" // not run because the command was interrupted
+	StatusPlanned   = "planned" // would be run, the command is a dry run
+)
+
+// ErrNotRun marks items skipped after the context was cancelled
+var ErrNotRun = errors.New("not run")
+
+// Report collects the per-item results of bulk operations
+type Report struct {
+	mu     sync.Mutex
+	items  []models.ItemResult
+	inputs []any // each item in the input file format of its command
+}
+
+// GlobalReport collects the results of all clients of the running command
+var GlobalReport = &Report{}
+
+// Record adds the result of one item to GlobalReport and returns err unchanged,
+// so workers can wrap the error they send. input is the item in the input file
+// format of the command and is written to --failed-out when the item did not succeed.
+func (c *Client) Record(op, kind, item string, input any, err error) error {
+	GlobalReport.add(op, kind, item, input, err, c.config.DryRun)
+	return err
+}
+
+func (r *Report) add(op, kind, item string, input any, err error, dryRun bool) {
+	res := models.ItemResult{Operation: op, Kind: kind, Item: item, Status: StatusSucceeded}
+	switch {
+	case dryRun && err == nil:
+		res.Status = StatusPlanned
+	case errors.Is(err, ErrNotRun):
+		res.Status = StatusSkipped
+	case err != nil:
+		res.Status = StatusFailed
+		res.HTTPCode, res.Error = describeError(err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items = append(r.items, res)
+	r.inputs = append(r.inputs, input)
+}
+
+// Summary returns the recorded results with their counts
+func (r *Report) Summary() models.ItemReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := models.ItemReport{Items: append([]models.ItemResult{}, r.items...)}
+	for _, it := range r.items {
+		switch it.Status {
+		case StatusSucceeded:
+			out.Succeeded++
+		case StatusFailed:
+			out.Failed++
+		case StatusSkipped:
+			out.Skipped++
+		case StatusPlanned:
+			out.Planned++
+		}
+	}
+	return out
+}
+
+// WriteReport writes the summary to path as JSON (.json) or YAML
+func (r *Report) WriteReport(path string) error {
+	return writeDocument(path, r.Summary())
+}
+
+// WriteFailed writes the failed and skipped items to path in the input file format
+// of their command, e.g. under `repositories:` or `users:`, so the file can be
+// passed back with --input. Items of the same repository, project or group are merged.
+// It returns the number of items written; nothing is written when there are none.
+func (r *Report) WriteFailed(path string) (int, error) {
+	r.mu.Lock()
+	var repos []models.ExtendedRepository
+	var repoInputs []models.RepositoryInput
+	var projects []openapi.RestProject
+	var extProjects []models.ExtendedProject
+	var users []models.User
+	var groups []models.Group
+	count := 0
+	for i, it := range r.items {
+		if it.Status == StatusSucceeded || it.Status == StatusPlanned || r.inputs[i] == nil {
+			continue
+		}
+		count++
+		switch v := r.inputs[i].(type) {
+		case models.ExtendedRepository:
+			repos = append(repos, v)
+		case models.RepositoryInput:
+			repoInputs = append(repoInputs, v)
+		case openapi.RestProject:
+			projects = append(projects, v)
+		case models.ExtendedProject:
+			extProjects = append(extProjects, v)
+		case models.User:
+			users = append(users, v)
+		case models.Group:
+			groups = append(groups, v)
+		default:
+			count--
+		}
+	}
+	r.mu.Unlock()
+
+	if count == 0 {
+		return 0, nil
+	}
+
+	doc := map[string]any{}
+	if len(repos) > 0 || len(repoInputs) > 0 {
+		var list []any
+		for _, v := range mergeRepositories(repos) {
+			list = append(list, v)
+		}
+		for _, v := range mergeRepositoryInputs(repoInputs) {
+			list = append(list, v)
+		}
+		doc["repositories"] = list
+	}
+	if len(projects) > 0 || len(extProjects) > 0 {
+		var list []any
+		for _, v := range projects {
+			list = append(list, v)
+		}
+		for _, v := range mergeProjects(extProjects) {
+			list = append(list, v)
+		}
+		doc["projects"] = list
+	}
+	if len(users) > 0 {
+		doc["users"] = users
+	}
+	if len(groups) > 0 {
+		doc["groups"] = mergeGroups(groups)
+	}
+	return count, writeDocument(path, doc)
+}
+
+// describeError returns the HTTP status code of a failed API call, if any,
+// and the error message including the messages returned by Bitbucket
+func describeError(err error) (int, string) {
+	msg := err.Error()
+	var apiErr interface {
+		error
+		Body() []byte
+	}
+	if !errors.As(err, &apiErr) {
+		return 0, msg
+	}
+
+	code := 0
+	if fields := strings.Fields(apiErr.Error()); len(fields) > 0 {
+		code, _ = strconv.Atoi(fields[0])
+	}
+
+	var body struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(apiErr.Body(), &body) == nil {
+		var details []string
+		for _, e := range body.Errors {
+			if e.Message != "" {
+				details = append(details, e.Message)
+			}
+		}
+		if len(details) > 0 {
+			msg += ": " + strings.Join(details, "; ")
+		}
+	}
+	return code, msg
+}
+
+// writeDocument writes v as JSON when path ends with .json, as YAML otherwise
+func writeDocument(path string, v any) error {
+	var data []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err = json.MarshalIndent(v, "", "  ")
+	} else {
+		data, err = yaml.Marshal(v)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// mergeRepositories merges the nested items of entries for the same repository
+func mergeRepositories(repos []models.ExtendedRepository) []models.ExtendedRepository {
+	var out []models.ExtendedRepository
+	index := map[string]int{}
+	for _, r := range repos {
+		key := r.ProjectKey + "/" + r.RepositorySlug
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(out)
+			out = append(out, r)
+			continue
+		}
+		e := &out[i]
+		e.Webhooks = appendItems(e.Webhooks, r.Webhooks)
+		e.RequiredBuilds = appendItems(e.RequiredBuilds, r.RequiredBuilds)
+		e.BranchPermissions = appendItems(e.BranchPermissions, r.BranchPermissions)
+		e.ReviewerGroups = appendItems(e.ReviewerGroups, r.ReviewerGroups)
+		e.Permissions = mergePermissions(e.Permissions, r.Permissions)
+	}
+	return out
+}
+
+func mergeRepositoryInputs(repos []models.RepositoryInput) []models.RepositoryInput {
+	var out []models.RepositoryInput
+	index := map[string]int{}
+	for _, r := range repos {
+		key := r.ProjectKey + "/" + r.RepositorySlug
+		if i, ok := index[key]; ok {
+			out[i].BranchPermissions = appendItems(out[i].BranchPermissions, r.BranchPermissions)
+			continue
+		}
+		index[key] = len(out)
+		out = append(out, r)
+	}
+	return out
+}
+
+func mergeProjects(projects []models.ExtendedProject) []models.ExtendedProject {
+	var out []models.ExtendedProject
+	index := map[string]int{}
+	for _, p := range projects {
+		if i, ok := index[p.Key]; ok {
+			out[i].Permissions = mergePermissions(out[i].Permissions, p.Permissions)
+			continue
+		}
+		index[p.Key] = len(out)
+		out = append(out, p)
+	}
+	return out
+}
+
+func mergeGroups(groups []models.Group) []models.Group {
+	var out []models.Group
+	index := map[string]int{}
+	for _, g := range groups {
+		if i, ok := index[g.Name]; ok {
+			out[i].Members = append(append([]string{}, out[i].Members...), g.Members...)
+			continue
+		}
+		index[g.Name] = len(out)
+		out = append(out, g)
+	}
+	return out
+}
+
+func mergePermissions(a, b *models.Permissions) *models.Permissions {
+	if a == nil || b == nil {
+		if a == nil {
+			return b
+		}
+		return a
+	}
+	return &models.Permissions{
+		Users:  append(append([]models.PermissionEntry{}, a.Users...), b.Users...),
+		Groups: append(append([]models.PermissionEntry{}, a.Groups...), b.Groups...),
+	}
+}
+
+// appendItems returns a new slice with the items of a and b
+func appendItems[T any](a, b *[]T) *[]T {
+	if a == nil || b == nil {
+		if a == nil {
+			return b
+		}
+		return a
+	}
+	merged := append(append([]T{}, *a...), *b...)
+	return &merged
+}
+
+// repoItem returns the report name of an item nested in a repository and the
+// repository entry holding only that item, which set adds to a bare copy
+func repoItem(repo models.ExtendedRepository, name string, set func(*models.ExtendedRepository)) (string, models.ExtendedRepository) {
+	input := models.ExtendedRepository{ProjectKey: repo.ProjectKey, RepositorySlug: repo.RepositorySlug}
+	set(&input)
+	return repo.ProjectKey + "/" + repo.RepositorySlug + " " + name, input
+}
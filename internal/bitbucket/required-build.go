@@ -1,7 +1,9 @@
 package bitbucket
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 
@@ -12,12 +14,13 @@ import (
 )
 
 // CreateRequiredBuilds creates required build merge checks for multiple repositories in parallel
-func (c *Client) CreateRequiredBuilds(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) CreateRequiredBuilds(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	maxWorkers := config.GlobalMaxWorkers
 
 	type job struct {
-		repo models.ExtendedRepository
-		req  openapi.RestRequiredBuildConditionSetRequest
+		repo  models.ExtendedRepository
+		build openapi.RestRequiredBuildCondition
+		req   openapi.RestRequiredBuildConditionSetRequest
 	}
 
 	// count the total number
@@ -37,22 +40,42 @@ func (c *Client) CreateRequiredBuilds(repos []models.ExtendedRepository) ([]mode
 	worker := func() {
 		defer wg.Done()
 		for j := range jobs {
+			item, input := requiredBuildItem(j.repo, j.build)
+			if err := c.notRun(ctx, "project", j.repo.ProjectKey, "slug", j.repo.RepositorySlug); err != nil {
+				errCh <- c.Record("create", "required build", item, input, err)
+				continue
+			}
+			if c.config.DryRun {
+				if err := c.preview("create", "required build", item, c.lookupRepo(ctx, j.repo.ProjectKey, j.repo.RepositorySlug)); err != nil {
+					errCh <- c.Record("create", "required build", item, input, err)
+					continue
+				}
+				c.Record("create", "required build", item, input, nil)
+				createdBuildsMu.Lock()
+				createdBuildsMap[j.repo.ProjectKey+"/"+j.repo.RepositorySlug] = append(createdBuildsMap[j.repo.ProjectKey+"/"+j.repo.RepositorySlug], j.build)
+				createdBuildsMu.Unlock()
+				continue
+			}
+
 			created, httpResp, err := c.api.BuildsAndDeploymentsAPI.
-				CreateRequiredBuildsMergeCheck(c.authCtx, j.repo.ProjectKey, j.repo.RepositorySlug).
+				CreateRequiredBuildsMergeCheck(c.authCtx(ctx), j.repo.ProjectKey, j.repo.RepositorySlug).
 				RestRequiredBuildConditionSetRequest(j.req).
 				Execute()
 
-			if err != nil && httpResp != nil {
-				c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
+			if err != nil {
+				if httpResp != nil {
+					c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
+				}
 				c.logger.Error("Failed to create required-build",
 					"project", j.repo.ProjectKey,
 					"slug", j.repo.RepositorySlug,
 					"buildParentKeys", j.req.BuildParentKeys,
 					"error", err)
-				errCh <- err
+				errCh <- c.Record("create", "required build", item, input, err)
 				continue
 			}
 
+			c.Record("create", "required build", item, input, nil)
 			// Log with build key/ID from returned RestRequiredBuildCondition
 			var buildKey interface{}
 			if created != nil {
@@ -86,7 +109,7 @@ func (c *Client) CreateRequiredBuilds(repos []models.ExtendedRepository) ([]mode
 	for _, r := range repos {
 		for _, wh := range *r.RequiredBuilds {
 			req := toSetRequest(wh)
-			jobs <- job{repo: r, req: req}
+			jobs <- job{repo: r, build: wh, req: req}
 		}
 	}
 	close(jobs)
@@ -116,7 +139,7 @@ func (c *Client) CreateRequiredBuilds(repos []models.ExtendedRepository) ([]mode
 	return createdRepos, nil
 }
 
-func (c *Client) UpdateRequiredBuilds(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) UpdateRequiredBuilds(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	maxWorkers := config.GlobalMaxWorkers
 
 	updatedRepos := make([]models.ExtendedRepository, len(repos))
@@ -129,6 +152,7 @@ func (c *Client) UpdateRequiredBuilds(repos []models.ExtendedRepository) ([]mode
 	type job struct {
 		repoIndex int
 		repo      models.ExtendedRepository
+		build     openapi.RestRequiredBuildCondition
 		req       openapi.RestRequiredBuildConditionSetRequest
 		id        int64
 	}
@@ -148,27 +172,48 @@ func (c *Client) UpdateRequiredBuilds(repos []models.ExtendedRepository) ([]mode
 	worker := func() {
 		defer wg.Done()
 		for j := range jobs {
+			item, input := requiredBuildItem(j.repo, j.build)
+			if err := c.notRun(ctx, "project", j.repo.ProjectKey, "slug", j.repo.RepositorySlug); err != nil {
+				errCh <- c.Record("update", "required build", item, input, err)
+				continue
+			}
+			if c.config.DryRun {
+				if err := c.preview("update", "required build", item, c.lookupRequiredBuild(ctx, j.repo, j.id)); err != nil {
+					errCh <- c.Record("update", "required build", item, input, err)
+					continue
+				}
+				c.Record("update", "required build", item, input, nil)
+				mu.Lock()
+				*updatedRepos[j.repoIndex].RequiredBuilds = append(*updatedRepos[j.repoIndex].RequiredBuilds, j.build)
+				mu.Unlock()
+				continue
+			}
+
 			updated, httpResp, err := c.api.BuildsAndDeploymentsAPI.
-				UpdateRequiredBuildsMergeCheck(c.authCtx, j.repo.ProjectKey, j.id, j.repo.RepositorySlug).
+				UpdateRequiredBuildsMergeCheck(c.authCtx(ctx), j.repo.ProjectKey, j.id, j.repo.RepositorySlug).
 				RestRequiredBuildConditionSetRequest(j.req).
 				Execute()
 
-			if err != nil && httpResp != nil {
-				c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
-				if httpResp.StatusCode == 404 {
-					// Skip missing items: treat as no-op
+			if err != nil {
+				if httpResp != nil {
+					c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
+				}
+				if httpResp != nil && httpResp.StatusCode == 404 {
+					// Skip missing items, they are reported as failed
 					c.logger.Info("Required build not found during update, skipping",
 						"project", j.repo.ProjectKey,
 						"slug", j.repo.RepositorySlug,
 						"id", j.id)
+					errCh <- c.Record("update", "required build", item, input, fmt.Errorf("required-build %v not found in %s/%s, not updated: %w", j.id, j.repo.ProjectKey, j.repo.RepositorySlug, err))
 					continue
 				}
-				errCh <- fmt.Errorf("failed to update required-build %v in %s/%s: %w",
+				errCh <- c.Record("update", "required build", item, input, fmt.Errorf("failed to update required-build %v in %s/%s: %w",
 					j.req.BuildParentKeys,
-					j.repo.ProjectKey, j.repo.RepositorySlug, err)
+					j.repo.ProjectKey, j.repo.RepositorySlug, err))
 				continue
 			}
 
+			c.Record("update", "required build", item, input, nil)
 			// Log with build key/ID from returned RestRequiredBuildCondition
 			var buildKey interface{}
 			if updated != nil {
@@ -202,7 +247,7 @@ func (c *Client) UpdateRequiredBuilds(repos []models.ExtendedRepository) ([]mode
 		for _, wh := range *r.RequiredBuilds {
 			if wh.Id != nil {
 				req := toSetRequest(wh)
-				jobs <- job{repoIndex: i, repo: r, req: req, id: *wh.Id}
+				jobs <- job{repoIndex: i, repo: r, build: wh, req: req, id: *wh.Id}
 			}
 		}
 	}
@@ -230,12 +275,13 @@ func (c *Client) UpdateRequiredBuilds(repos []models.ExtendedRepository) ([]mode
 	return filteredRepos, firstErr
 }
 
-func (c *Client) DeleteRequiredBuilds(repos []models.ExtendedRepository) error {
+func (c *Client) DeleteRequiredBuilds(ctx context.Context, repos []models.ExtendedRepository) error {
 	maxWorkers := config.GlobalMaxWorkers
 
 	type job struct {
-		repo models.ExtendedRepository
-		id   int64
+		repo  models.ExtendedRepository
+		build openapi.RestRequiredBuildCondition
+		id    int64
 	}
 
 	// count the total number
@@ -252,19 +298,34 @@ func (c *Client) DeleteRequiredBuilds(repos []models.ExtendedRepository) error {
 	worker := func() {
 		defer wg.Done()
 		for j := range jobs {
+			item, input := requiredBuildItem(j.repo, j.build)
+			if err := c.notRun(ctx, "project", j.repo.ProjectKey, "slug", j.repo.RepositorySlug); err != nil {
+				errCh <- c.Record("delete", "required build", item, input, err)
+				continue
+			}
+			if c.config.DryRun {
+				if err := c.preview("delete", "required build", item, c.lookupRequiredBuild(ctx, j.repo, j.id)); err != nil {
+					errCh <- c.Record("delete", "required build", item, input, err)
+					continue
+				}
+				c.Record("delete", "required build", item, input, nil)
+				continue
+			}
+
 			httpResp, err := c.api.BuildsAndDeploymentsAPI.
-				DeleteRequiredBuildsMergeCheck(c.authCtx, j.repo.ProjectKey, j.id, j.repo.RepositorySlug).
+				DeleteRequiredBuildsMergeCheck(c.authCtx(ctx), j.repo.ProjectKey, j.id, j.repo.RepositorySlug).
 				Execute()
 
 			if err != nil {
 				if httpResp != nil {
 					c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
 					if httpResp.StatusCode == 404 {
-						// Skip missing items: treat as no-op
+						// Already absent, treated as done
 						c.logger.Info("Required build was already absent (404), skipping",
 							"project", j.repo.ProjectKey,
 							"slug", j.repo.RepositorySlug,
 							"buildId", j.id)
+						c.Record("delete", "required build", item, input, nil)
 						continue
 					}
 				}
@@ -273,10 +334,11 @@ func (c *Client) DeleteRequiredBuilds(repos []models.ExtendedRepository) error {
 					"slug", j.repo.RepositorySlug,
 					"buildId", j.id,
 					"error", err)
-				errCh <- err
+				errCh <- c.Record("delete", "required build", item, input, err)
 				continue
 			}
 
+			c.Record("delete", "required build", item, input, nil)
 			c.logger.Info("Required builds was successfully deleted, or was never present",
 				"project", j.repo.ProjectKey,
 				"slug", j.repo.RepositorySlug,
@@ -294,7 +356,7 @@ func (c *Client) DeleteRequiredBuilds(repos []models.ExtendedRepository) error {
 	for _, r := range repos {
 		for _, wh := range *r.RequiredBuilds {
 			if wh.Id != nil {
-				jobs <- job{repo: r, id: *wh.Id}
+				jobs <- job{repo: r, build: wh, id: *wh.Id}
 			}
 		}
 	}
@@ -331,7 +393,7 @@ func toSetRequest(cond openapi.RestRequiredBuildCondition) openapi.RestRequiredB
 	return req
 }
 
-func (c *Client) GetRequiredBuilds(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) GetRequiredBuilds(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	maxWorkers := config.GlobalMaxWorkers
 
 	type job struct {
@@ -347,8 +409,12 @@ func (c *Client) GetRequiredBuilds(repos []models.ExtendedRepository) ([]models.
 	worker := func() {
 		defer wg.Done()
 		for j := range jobs {
+			if err := c.notRun(ctx, "project", j.repo.ProjectKey, "slug", j.repo.RepositorySlug); err != nil {
+				errCh <- err
+				continue
+			}
 			resp, httpResp, err := c.api.BuildsAndDeploymentsAPI.
-				GetPageOfRequiredBuildsMergeChecks(c.authCtx, j.repo.ProjectKey, j.repo.RepositorySlug).
+				GetPageOfRequiredBuildsMergeChecks(c.authCtx(ctx), j.repo.ProjectKey, j.repo.RepositorySlug).
 				Execute()
 			if err != nil {
 				if httpResp != nil {
@@ -420,6 +486,18 @@ func equalStringSlices(a, b []string) bool {
 	return true
 }
 
+// equalStringSets compares two string slices ignoring order
+func equalStringSets(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sa := slices.Clone(a)
+	sb := slices.Clone(b)
+	slices.Sort(sa)
+	slices.Sort(sb)
+	return slices.Equal(sa, sb)
+}
+
 func equalStringPtr(a, b *string) bool {
 	if a == nil && b == nil {
 		return true
@@ -459,3 +537,14 @@ func equalRefMatcher(a, b *openapi.UpdatePullRequestCondition1RequestSourceMatch
 	}
 	return true
 }
+
+// requiredBuildItem returns the report name of a required build and its repository entry
+func requiredBuildItem(repo models.ExtendedRepository, rb openapi.RestRequiredBuildCondition) (string, models.ExtendedRepository) {
+	name := strings.Join(rb.BuildParentKeys, ",")
+	if rb.Id != nil {
+		name += " (id " + utils.Int64PtrToString(rb.Id) + ")"
+	}
+	return repoItem(repo, name, func(r *models.ExtendedRepository) {
+		r.RequiredBuilds = &[]openapi.RestRequiredBuildCondition{rb}
+	})
+}
@@ -1,22 +1,24 @@
 package bitbucket
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
 
 	"github.com/vinisman/bbctl/internal/config"
 	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
 	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
 )
 
 // GetReviewerGroups fetches all reviewer groups for given repositories
-func (c *Client) GetReviewerGroups(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) GetReviewerGroups(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	var errs []string
 
 	for i := range repos {
 		resp, httpResp, err := c.api.PullRequestsAPI.GetReviewerGroups1(
-			c.authCtx,
+			c.authCtx(ctx),
 			repos[i].ProjectKey,
 			repos[i].RepositorySlug,
 		).Execute()
@@ -46,7 +48,7 @@ func (c *Client) GetReviewerGroups(repos []models.ExtendedRepository) ([]models.
 }
 
 // CreateReviewerGroups creates new reviewer groups concurrently for multiple repositories
-func (c *Client) CreateReviewerGroups(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) CreateReviewerGroups(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	maxWorkers := config.GlobalMaxWorkers
 
 	type job struct {
@@ -82,7 +84,7 @@ func (c *Client) CreateReviewerGroups(repos []models.ExtendedRepository) ([]mode
 					if user.Name == nil || *user.Name == "" {
 						continue
 					}
-					userResp, httpResp, err := c.api.PermissionManagementAPI.GetUsers1(c.authCtx).Filter(*user.Name).Execute()
+					userResp, httpResp, err := c.api.PermissionManagementAPI.GetUsers1(c.authCtx(ctx)).Filter(*user.Name).Execute()
 					if err != nil {
 						c.logger.Warn("Failed to get user ID", "username", *user.Name, "error", err)
 						if httpResp != nil {
@@ -123,14 +125,29 @@ func (c *Client) CreateReviewerGroups(repos []models.ExtendedRepository) ([]mode
 	worker := func() {
 		defer wg.Done()
 		for j := range jobs {
+			item, input := reviewerGroupItem(j.repo, j.reviewerGroup)
+			if err := c.notRun(ctx, "project", j.repo.ProjectKey, "slug", j.repo.RepositorySlug); err != nil {
+				errCh <- c.Record("create", "reviewer group", item, input, err)
+				continue
+			}
 			c.logger.Debug("Creating reviewer group",
 				"project", j.repo.ProjectKey,
 				"repo", j.repo.RepositorySlug,
 				"name", *j.reviewerGroup.Name,
 				"users_count", len(j.reviewerGroup.Users))
 
+			if c.config.DryRun {
+				if err := c.preview("create", "reviewer group", item, c.lookupRepo(ctx, j.repo.ProjectKey, j.repo.RepositorySlug)); err != nil {
+					errCh <- c.Record("create", "reviewer group", item, input, err)
+					continue
+				}
+				c.Record("create", "reviewer group", item, input, nil)
+				resultsCh <- result{repoIndex: j.repoIndex, reviewerGroup: j.reviewerGroup}
+				continue
+			}
+
 			created, httpResp, err := c.api.PullRequestsAPI.
-				Create2(c.authCtx, j.repo.ProjectKey, j.repo.RepositorySlug).
+				Create2(c.authCtx(ctx), j.repo.ProjectKey, j.repo.RepositorySlug).
 				RestReviewerGroup(j.reviewerGroup).
 				Execute()
 
@@ -144,11 +161,12 @@ func (c *Client) CreateReviewerGroups(repos []models.ExtendedRepository) ([]mode
 				if httpResp != nil {
 					c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
 				}
-				errCh <- fmt.Errorf("failed to create reviewer group %s in %s/%s: %w",
-					*j.reviewerGroup.Name, j.repo.ProjectKey, j.repo.RepositorySlug, err)
+				errCh <- c.Record("create", "reviewer group", item, input, fmt.Errorf("failed to create reviewer group %s in %s/%s: %w",
+					*j.reviewerGroup.Name, j.repo.ProjectKey, j.repo.RepositorySlug, err))
 				continue
 			}
 
+			c.Record("create", "reviewer group", item, input, nil)
 			resultsCh <- result{repoIndex: j.repoIndex, reviewerGroup: *created}
 
 			c.logger.Info("Created reviewer group",
@@ -207,12 +225,12 @@ func (c *Client) CreateReviewerGroups(repos []models.ExtendedRepository) ([]mode
 		return newRepos, fmt.Errorf("errors occurred creating reviewer groups: %v", errs)
 	}
 
-	c.logger.Info("Successfully created all reviewer groups")
+	c.logChanged("Successfully created all reviewer groups")
 	return newRepos, nil
 }
 
 // UpdateReviewerGroups updates existing reviewer groups concurrently for multiple repositories
-func (c *Client) UpdateReviewerGroups(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) UpdateReviewerGroups(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	maxWorkers := config.GlobalMaxWorkers
 
 	type job struct {
@@ -247,7 +265,7 @@ func (c *Client) UpdateReviewerGroups(repos []models.ExtendedRepository) ([]mode
 					if user.Name == nil || *user.Name == "" {
 						continue
 					}
-					userResp, httpResp, err := c.api.PermissionManagementAPI.GetUsers1(c.authCtx).Filter(*user.Name).Execute()
+					userResp, httpResp, err := c.api.PermissionManagementAPI.GetUsers1(c.authCtx(ctx)).Filter(*user.Name).Execute()
 					if err != nil {
 						c.logger.Warn("Failed to get user ID", "username", *user.Name, "error", err)
 						if httpResp != nil {
@@ -288,18 +306,33 @@ func (c *Client) UpdateReviewerGroups(repos []models.ExtendedRepository) ([]mode
 	worker := func() {
 		defer wg.Done()
 		for j := range jobs {
+			item, input := reviewerGroupItem(j.repo, j.reviewerGroup)
+			if err := c.notRun(ctx, "project", j.repo.ProjectKey, "slug", j.repo.RepositorySlug); err != nil {
+				errCh <- c.Record("update", "reviewer group", item, input, err)
+				continue
+			}
 			if j.reviewerGroup.Id == nil {
 				groupName := "unknown"
 				if j.reviewerGroup.Name != nil {
 					groupName = *j.reviewerGroup.Name
 				}
-				errCh <- fmt.Errorf("reviewer group %s in %s/%s is missing ID",
-					groupName, j.repo.ProjectKey, j.repo.RepositorySlug)
+				errCh <- c.Record("update", "reviewer group", item, input, fmt.Errorf("reviewer group %s in %s/%s is missing ID",
+					groupName, j.repo.ProjectKey, j.repo.RepositorySlug))
+				continue
+			}
+
+			if c.config.DryRun {
+				if err := c.preview("update", "reviewer group", item, c.lookupReviewerGroup(ctx, j.repo, fmt.Sprintf("%d", *j.reviewerGroup.Id))); err != nil {
+					errCh <- c.Record("update", "reviewer group", item, input, err)
+					continue
+				}
+				c.Record("update", "reviewer group", item, input, nil)
+				resultsCh <- result{repoIndex: j.repoIndex, reviewerGroup: j.reviewerGroup}
 				continue
 			}
 
 			updated, httpResp, err := c.api.PullRequestsAPI.
-				Update2(c.authCtx, j.repo.ProjectKey, fmt.Sprintf("%d", *j.reviewerGroup.Id), j.repo.RepositorySlug).
+				Update2(c.authCtx(ctx), j.repo.ProjectKey, fmt.Sprintf("%d", *j.reviewerGroup.Id), j.repo.RepositorySlug).
 				RestReviewerGroup(j.reviewerGroup).
 				Execute()
 
@@ -318,11 +351,12 @@ func (c *Client) UpdateReviewerGroups(repos []models.ExtendedRepository) ([]mode
 				if httpResp != nil {
 					c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
 				}
-				errCh <- fmt.Errorf("failed to update reviewer group %s (ID: %d) in %s/%s: %w",
-					groupName, *j.reviewerGroup.Id, j.repo.ProjectKey, j.repo.RepositorySlug, err)
+				errCh <- c.Record("update", "reviewer group", item, input, fmt.Errorf("failed to update reviewer group %s (ID: %d) in %s/%s: %w",
+					groupName, *j.reviewerGroup.Id, j.repo.ProjectKey, j.repo.RepositorySlug, err))
 				continue
 			}
 
+			c.Record("update", "reviewer group", item, input, nil)
 			resultsCh <- result{repoIndex: j.repoIndex, reviewerGroup: *updated}
 
 			updatedName := "unknown"
@@ -386,12 +420,12 @@ func (c *Client) UpdateReviewerGroups(repos []models.ExtendedRepository) ([]mode
 		return newRepos, fmt.Errorf("errors occurred updating reviewer groups: %v", errs)
 	}
 
-	c.logger.Info("Successfully updated all reviewer groups")
+	c.logChanged("Successfully updated all reviewer groups")
 	return newRepos, nil
 }
 
 // DeleteReviewerGroups deletes reviewer groups concurrently for multiple repositories
-func (c *Client) DeleteReviewerGroups(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) DeleteReviewerGroups(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	maxWorkers := config.GlobalMaxWorkers
 
 	type job struct {
@@ -426,18 +460,33 @@ func (c *Client) DeleteReviewerGroups(repos []models.ExtendedRepository) ([]mode
 	worker := func() {
 		defer wg.Done()
 		for j := range jobs {
+			item, input := reviewerGroupItem(j.repo, j.reviewerGroup)
+			if err := c.notRun(ctx, "project", j.repo.ProjectKey, "slug", j.repo.RepositorySlug); err != nil {
+				errCh <- c.Record("delete", "reviewer group", item, input, err)
+				continue
+			}
 			if j.reviewerGroup.Id == nil {
 				groupName := "unknown"
 				if j.reviewerGroup.Name != nil {
 					groupName = *j.reviewerGroup.Name
 				}
-				errCh <- fmt.Errorf("reviewer group %s in %s/%s is missing ID",
-					groupName, j.repo.ProjectKey, j.repo.RepositorySlug)
+				errCh <- c.Record("delete", "reviewer group", item, input, fmt.Errorf("reviewer group %s in %s/%s is missing ID",
+					groupName, j.repo.ProjectKey, j.repo.RepositorySlug))
+				continue
+			}
+
+			if c.config.DryRun {
+				if err := c.preview("delete", "reviewer group", item, c.lookupReviewerGroup(ctx, j.repo, fmt.Sprintf("%d", *j.reviewerGroup.Id))); err != nil {
+					errCh <- c.Record("delete", "reviewer group", item, input, err)
+					continue
+				}
+				c.Record("delete", "reviewer group", item, input, nil)
+				resultsCh <- result{repoIndex: j.repoIndex, reviewerGroup: j.reviewerGroup}
 				continue
 			}
 
 			httpResp, err := c.api.PullRequestsAPI.
-				Delete7(c.authCtx, j.repo.ProjectKey, fmt.Sprintf("%d", *j.reviewerGroup.Id), j.repo.RepositorySlug).
+				Delete7(c.authCtx(ctx), j.repo.ProjectKey, fmt.Sprintf("%d", *j.reviewerGroup.Id), j.repo.RepositorySlug).
 				Execute()
 
 			groupName := "unknown"
@@ -455,11 +504,12 @@ func (c *Client) DeleteReviewerGroups(repos []models.ExtendedRepository) ([]mode
 				if httpResp != nil {
 					c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
 				}
-				errCh <- fmt.Errorf("failed to delete reviewer group %s (ID: %d) in %s/%s: %w",
-					groupName, *j.reviewerGroup.Id, j.repo.ProjectKey, j.repo.RepositorySlug, err)
+				errCh <- c.Record("delete", "reviewer group", item, input, fmt.Errorf("failed to delete reviewer group %s (ID: %d) in %s/%s: %w",
+					groupName, *j.reviewerGroup.Id, j.repo.ProjectKey, j.repo.RepositorySlug, err))
 				continue
 			}
 
+			c.Record("delete", "reviewer group", item, input, nil)
 			resultsCh <- result{repoIndex: j.repoIndex, reviewerGroup: j.reviewerGroup}
 
 			c.logger.Info("Deleted reviewer group",
@@ -518,6 +568,32 @@ func (c *Client) DeleteReviewerGroups(repos []models.ExtendedRepository) ([]mode
 		return newRepos, fmt.Errorf("errors occurred deleting reviewer groups: %v", errs)
 	}
 
-	c.logger.Info("Successfully deleted all reviewer groups")
+	c.logChanged("Successfully deleted all reviewer groups")
 	return newRepos, nil
 }
+
+// AreReviewerGroupsEqual compares two reviewer groups by name, description and users.
+// Users are matched by name only since ids are filled in by the server.
+func AreReviewerGroupsEqual(a, b openapi.RestReviewerGroup) bool {
+	if !equalStringPtr(a.Name, b.Name) {
+		return false
+	}
+	if !equalStringPtr(a.Description, b.Description) {
+		return false
+	}
+	var usersA, usersB []string
+	for _, u := range a.Users {
+		usersA = append(usersA, utils.SafeValue(u.Name))
+	}
+	for _, u := range b.Users {
+		usersB = append(usersB, utils.SafeValue(u.Name))
+	}
+	return equalStringSets(usersA, usersB)
+}
+
+// reviewerGroupItem returns the report name of a reviewer group and its repository entry
+func reviewerGroupItem(repo models.ExtendedRepository, rg openapi.RestReviewerGroup) (string, models.ExtendedRepository) {
+	return repoItem(repo, utils.SafeValue(rg.Name), func(r *models.ExtendedRepository) {
+		r.ReviewerGroups = &[]openapi.RestReviewerGroup{rg}
+	})
+}
@@ -1,16 +1,18 @@
 package bitbucket
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
 	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/models"
 	"github.com/vinisman/bbctl/utils"
 	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
 )
 
 // getMultipleUsers retrieves multiple users by usernames
-func (c *Client) getMultipleUsers(usernames []string) ([]openapi.RestApplicationUser, error) {
+func (c *Client) getMultipleUsers(ctx context.Context, usernames []string) ([]openapi.RestApplicationUser, error) {
 	if len(usernames) == 0 {
 		return []openapi.RestApplicationUser{}, nil
 	}
@@ -27,7 +29,7 @@ func (c *Client) getMultipleUsers(usernames []string) ([]openapi.RestApplication
 		wg.Add(1)
 		go func(u string) {
 			defer wg.Done()
-			user, err := c.getSingleUser(u)
+			user, err := c.getSingleUser(ctx, u)
 			if err != nil {
 				errorChan <- fmt.Errorf("failed to get user %s: %w", u, err)
 				c.logger.Error("Failed to get user", "username", u, "error", err)
@@ -68,14 +70,14 @@ func (c *Client) getMultipleUsers(usernames []string) ([]openapi.RestApplication
 }
 
 // getSingleUser retrieves a single user by username
-func (c *Client) getSingleUser(username string) (*openapi.RestApplicationUser, error) {
+func (c *Client) getSingleUser(ctx context.Context, username string) (*openapi.RestApplicationUser, error) {
 	if username == "" {
 		return nil, fmt.Errorf("username is required")
 	}
 
 	c.logger.Debug("Getting user", "username", username)
 
-	resp, httpResp, err := c.api.SystemMaintenanceAPI.GetUser(c.authCtx, username).Execute()
+	resp, httpResp, err := c.api.SystemMaintenanceAPI.GetUser(c.authCtx(ctx), username).Execute()
 	if err != nil {
 		if httpResp != nil {
 			c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
@@ -89,32 +91,43 @@ func (c *Client) getSingleUser(username string) (*openapi.RestApplicationUser, e
 }
 
 // getAllUsers retrieves all users from Bitbucket
-func (c *Client) getAllUsers() ([]openapi.RestApplicationUser, error) {
+func (c *Client) getAllUsers(ctx context.Context) ([]openapi.RestApplicationUser, error) {
 	c.logger.Info("Getting all users from Bitbucket")
 
-	resp, httpResp, err := c.api.PermissionManagementAPI.GetUsers1(c.authCtx).Execute()
-	if err != nil {
-		if httpResp != nil {
-			c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
+	users := []openapi.RestApplicationUser{}
+	var start float32 = 0
+	for {
+		resp, httpResp, err := c.api.PermissionManagementAPI.GetUsers1(c.authCtx(ctx)).
+			Start(start).
+			Limit(float32(c.config.PageSize)).
+			Execute()
+		if err != nil {
+			if httpResp != nil {
+				c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
+			}
+			c.logger.Error("Failed to get all users", "error", err)
+			return []openapi.RestApplicationUser{}, err
+		}
+
+		// Convert RestDetailedUser to RestApplicationUser
+		for _, detailedUser := range resp.Values {
+			users = append(users, openapi.RestApplicationUser{
+				Name:         detailedUser.Name,
+				DisplayName:  detailedUser.DisplayName,
+				EmailAddress: detailedUser.EmailAddress,
+				Active:       detailedUser.Active,
+				Id:           detailedUser.Id,
+				Slug:         detailedUser.Slug,
+				Type:         detailedUser.Type,
+				AvatarUrl:    detailedUser.AvatarUrl,
+				Links:        detailedUser.Links,
+			})
 		}
-		c.logger.Error("Failed to get all users", "error", err)
-		return []openapi.RestApplicationUser{}, err
-	}
 
-	// Convert RestDetailedUser to RestApplicationUser
-	users := make([]openapi.RestApplicationUser, len(resp.Values))
-	for i, detailedUser := range resp.Values {
-		users[i] = openapi.RestApplicationUser{
-			Name:         detailedUser.Name,
-			DisplayName:  detailedUser.DisplayName,
-			EmailAddress: detailedUser.EmailAddress,
-			Active:       detailedUser.Active,
-			Id:           detailedUser.Id,
-			Slug:         detailedUser.Slug,
-			Type:         detailedUser.Type,
-			AvatarUrl:    detailedUser.AvatarUrl,
-			Links:        detailedUser.Links,
+		if resp.IsLastPage == nil || *resp.IsLastPage || resp.NextPageStart == nil {
+			break
 		}
+		start = float32(*resp.NextPageStart)
 	}
 
 	c.logger.Info("Successfully retrieved all users", "count", len(users))
@@ -122,13 +135,13 @@ func (c *Client) getAllUsers() ([]openapi.RestApplicationUser, error) {
 }
 
 // GetUsers retrieves multiple users by usernames (public method)
-func (c *Client) GetUsers(usernames []string) ([]openapi.RestApplicationUser, error) {
-	return c.getMultipleUsers(usernames)
+func (c *Client) GetUsers(ctx context.Context, usernames []string) ([]openapi.RestApplicationUser, error) {
+	return c.getMultipleUsers(ctx, usernames)
 }
 
 // GetAllUsers retrieves all users from Bitbucket (public method)
-func (c *Client) GetAllUsers() ([]openapi.RestApplicationUser, error) {
-	return c.getAllUsers()
+func (c *Client) GetAllUsers(ctx context.Context) ([]openapi.RestApplicationUser, error) {
+	return c.getAllUsers(ctx)
 }
 
 // UserWithPassword represents a user with password for creation
@@ -137,8 +150,9 @@ type UserWithPassword struct {
 	Password string
 }
 
-// CreateUsers creates multiple users
-func (c *Client) CreateUsers(users []openapi.RestApplicationUser, passwords []string) ([]openapi.RestApplicationUser, error) {
+// CreateUsers creates multiple users. Users that could not be created are
+// left empty in the result.
+func (c *Client) CreateUsers(ctx context.Context, users []openapi.RestApplicationUser, passwords []string) ([]openapi.RestApplicationUser, error) {
 	if len(users) == 0 {
 		return []openapi.RestApplicationUser{}, nil
 	}
@@ -167,8 +181,17 @@ func (c *Client) CreateUsers(users []openapi.RestApplicationUser, passwords []st
 	for range maxWorkers {
 		wg.Go(func() {
 			for j := range jobs {
+				if err := c.notRun(ctx, "user", j.user.GetName()); err != nil {
+					resultsCh <- result{index: j.index, user: &j.user, err: err}
+					continue
+				}
+				if c.config.DryRun {
+					err := c.preview("create", "user", j.user.GetName(), c.lookupNewUser(ctx, j.user.GetName()))
+					resultsCh <- result{index: j.index, user: &j.user, err: err}
+					continue
+				}
 				// Build the request using the fluent API
-				req := c.api.PermissionManagementAPI.CreateUser(c.authCtx).Name(*j.user.Name)
+				req := c.api.PermissionManagementAPI.CreateUser(c.authCtx(ctx)).Name(*j.user.Name)
 
 				if j.user.DisplayName != nil {
 					req = req.DisplayName(*j.user.DisplayName)
@@ -214,15 +237,12 @@ func (c *Client) CreateUsers(users []openapi.RestApplicationUser, passwords []st
 	createdUsers := make([]openapi.RestApplicationUser, len(users))
 	var errorsCount int
 	for r := range resultsCh {
+		c.Record("create", "user", utils.SafeValue(users[r.index].Name), userInput(users[r.index]), r.err)
 		if r.err != nil {
-			c.logger.Error("Failed to create user", "username", utils.SafeValue(r.user.Name), "error", r.err)
+			c.logger.Error("Failed to create user", "username", utils.SafeValue(users[r.index].Name), "error", r.err)
 			errorsCount++
-			// Keep original user in case of error
-			if r.user != nil {
-				createdUsers[r.index] = *r.user
-			}
 		} else {
-			c.logger.Info("Created user", "username", utils.SafeValue(r.user.Name))
+			c.logChanged("Created user", "username", utils.SafeValue(r.user.Name))
 			createdUsers[r.index] = *r.user
 		}
 	}
@@ -235,7 +255,7 @@ func (c *Client) CreateUsers(users []openapi.RestApplicationUser, passwords []st
 }
 
 // DeleteUsers deletes multiple users by usernames
-func (c *Client) DeleteUsers(usernames []string) ([]openapi.RestApplicationUser, error) {
+func (c *Client) DeleteUsers(ctx context.Context, usernames []string) ([]openapi.RestApplicationUser, error) {
 	if len(usernames) == 0 {
 		return []openapi.RestApplicationUser{}, nil
 	}
@@ -263,8 +283,17 @@ func (c *Client) DeleteUsers(usernames []string) ([]openapi.RestApplicationUser,
 	for range maxWorkers {
 		wg.Go(func() {
 			for j := range jobs {
+				if err := c.notRun(ctx, "user", j.username); err != nil {
+					resultsCh <- result{index: j.index, err: err}
+					continue
+				}
+				if c.config.DryRun {
+					err := c.preview("delete", "user", j.username, c.lookupUser(ctx, j.username))
+					resultsCh <- result{index: j.index, user: &openapi.RestApplicationUser{Name: &j.username}, err: err}
+					continue
+				}
 				// Delete user
-				_, httpResp, err := c.api.PermissionManagementAPI.DeleteUser(c.authCtx).Name(j.username).Execute()
+				_, httpResp, err := c.api.PermissionManagementAPI.DeleteUser(c.authCtx(ctx)).Name(j.username).Execute()
 				if err != nil {
 					if httpResp != nil {
 						c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
@@ -297,11 +326,12 @@ func (c *Client) DeleteUsers(usernames []string) ([]openapi.RestApplicationUser,
 	deletedUsers := make([]openapi.RestApplicationUser, len(usernames))
 	var errorsCount int
 	for r := range resultsCh {
+		c.Record("delete", "user", usernames[r.index], models.User{Name: usernames[r.index]}, r.err)
 		if r.err != nil {
 			c.logger.Error("Failed to delete user", "username", usernames[r.index], "error", r.err)
 			errorsCount++
 		} else {
-			c.logger.Info("Deleted user", "username", utils.SafeValue(r.user.Name))
+			c.logChanged("Deleted user", "username", utils.SafeValue(r.user.Name))
 			deletedUsers[r.index] = *r.user
 		}
 	}
@@ -313,8 +343,9 @@ func (c *Client) DeleteUsers(usernames []string) ([]openapi.RestApplicationUser,
 	return deletedUsers, nil
 }
 
-// UpdateUsers updates multiple users
-func (c *Client) UpdateUsers(users []openapi.RestApplicationUser) ([]openapi.RestApplicationUser, error) {
+// UpdateUsers updates multiple users. Users that could not be updated are
+// left empty in the result.
+func (c *Client) UpdateUsers(ctx context.Context, users []openapi.RestApplicationUser) ([]openapi.RestApplicationUser, error) {
 	if len(users) == 0 {
 		return []openapi.RestApplicationUser{}, nil
 	}
@@ -342,8 +373,12 @@ func (c *Client) UpdateUsers(users []openapi.RestApplicationUser) ([]openapi.Res
 	for range maxWorkers {
 		wg.Go(func() {
 			for j := range jobs {
+				if err := c.notRun(ctx, "user", j.user.GetName()); err != nil {
+					resultsCh <- result{index: j.index, err: err}
+					continue
+				}
 				// Get current user data first
-				currentUser, err := c.getSingleUser(*j.user.Name)
+				currentUser, err := c.getSingleUser(ctx, *j.user.Name)
 				if err != nil {
 					resultsCh <- result{index: j.index, user: nil, err: err}
 					continue
@@ -358,8 +393,14 @@ func (c *Client) UpdateUsers(users []openapi.RestApplicationUser) ([]openapi.Res
 					updatedUser.EmailAddress = j.user.EmailAddress
 				}
 
+				if c.config.DryRun {
+					c.preview("update", "user", *j.user.Name, nil)
+					resultsCh <- result{index: j.index, user: &updatedUser}
+					continue
+				}
+
 				// Update user via API
-				_, httpResp, err := c.api.PermissionManagementAPI.UpdateUserDetails(c.authCtx).UserUpdate(openapi.UserUpdate{
+				_, httpResp, err := c.api.PermissionManagementAPI.UpdateUserDetails(c.authCtx(ctx)).UserUpdate(openapi.UserUpdate{
 					Name:        updatedUser.Name,
 					DisplayName: updatedUser.DisplayName,
 					Email:       updatedUser.EmailAddress,
@@ -392,6 +433,7 @@ func (c *Client) UpdateUsers(users []openapi.RestApplicationUser) ([]openapi.Res
 	updatedUsers := make([]openapi.RestApplicationUser, len(users))
 	var errorsCount int
 	for r := range resultsCh {
+		c.Record("update", "user", utils.SafeValue(users[r.index].Name), userInput(users[r.index]), r.err)
 		if r.err != nil {
 			username := "unknown"
 			if r.user != nil {
@@ -402,12 +444,8 @@ func (c *Client) UpdateUsers(users []openapi.RestApplicationUser) ([]openapi.Res
 			}
 			c.logger.Error("Failed to update user", "username", username, "error", r.err)
 			errorsCount++
-			// Keep original user in case of error
-			if r.user != nil {
-				updatedUsers[r.index] = *r.user
-			}
 		} else {
-			c.logger.Info("Updated user", "username", utils.SafeValue(r.user.Name))
+			c.logChanged("Updated user", "username", utils.SafeValue(r.user.Name))
 			updatedUsers[r.index] = *r.user
 		}
 	}
@@ -418,3 +456,12 @@ func (c *Client) UpdateUsers(users []openapi.RestApplicationUser) ([]openapi.Res
 
 	return updatedUsers, nil
 }
+
+// userInput converts a user to the input file format of the user commands
+func userInput(u openapi.RestApplicationUser) models.User {
+	return models.User{
+		Name:         utils.SafeValue(u.Name),
+		DisplayName:  utils.SafeValue(u.DisplayName),
+		EmailAddress: utils.SafeValue(u.EmailAddress),
+	}
+}
@@ -1,6 +1,7 @@
 package bitbucket
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -16,11 +17,11 @@ import (
 
 // Find webhooks fetches all webhooks for a given project and repository
 
-func (c *Client) GetWebhooks(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) GetWebhooks(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	var errs []string
 
 	for i := range repos {
-		httpResp, err := c.api.RepositoryAPI.FindWebhooks1(c.authCtx, repos[i].ProjectKey, repos[i].RepositorySlug).Execute()
+		httpResp, err := c.api.RepositoryAPI.FindWebhooks1(c.authCtx(ctx), repos[i].ProjectKey, repos[i].RepositorySlug).Execute()
 		if err != nil {
 			errs = append(errs, fmt.Sprintf("failed to call FindWebhooks1 for %s/%s: %v", repos[i].ProjectKey, repos[i].RepositorySlug, err))
 			continue
@@ -63,7 +64,7 @@ func (c *Client) GetWebhooks(repos []models.ExtendedRepository) ([]models.Extend
 }
 
 // CreateWebhook creates new webhooks concurrently for multiple repositories
-func (c *Client) CreateWebhooks(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) CreateWebhooks(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	maxWorkers := config.GlobalMaxWorkers
 
 	type job struct {
@@ -96,8 +97,23 @@ func (c *Client) CreateWebhooks(repos []models.ExtendedRepository) ([]models.Ext
 	worker := func() {
 		defer wg.Done()
 		for j := range jobs {
+			item, input := webhookItem(j.repo, j.webhook)
+			if err := c.notRun(ctx, "project", j.repo.ProjectKey, "slug", j.repo.RepositorySlug); err != nil {
+				errCh <- c.Record("create", "webhook", item, input, err)
+				continue
+			}
+			if c.config.DryRun {
+				if err := c.preview("create", "webhook", item, c.lookupRepo(ctx, j.repo.ProjectKey, j.repo.RepositorySlug)); err != nil {
+					errCh <- c.Record("create", "webhook", item, input, err)
+					continue
+				}
+				c.Record("create", "webhook", item, input, nil)
+				resultsCh <- result{repoIndex: j.repoIndex, webhook: j.webhook}
+				continue
+			}
+
 			created, httpResp, err := c.api.RepositoryAPI.
-				CreateWebhook1(c.authCtx, j.repo.ProjectKey, j.repo.RepositorySlug).
+				CreateWebhook1(c.authCtx(ctx), j.repo.ProjectKey, j.repo.RepositorySlug).
 				RestWebhook(j.webhook).
 				Execute()
 
@@ -110,10 +126,11 @@ func (c *Client) CreateWebhooks(repos []models.ExtendedRepository) ([]models.Ext
 				if httpResp != nil {
 					c.logger.Debug("HTTP response", "status", httpResp.StatusCode, "body", httpResp.Body)
 				}
-				errCh <- err
+				errCh <- c.Record("create", "webhook", item, input, err)
 				continue
 			}
 
+			c.Record("create", "webhook", item, input, nil)
 			resultsCh <- result{repoIndex: j.repoIndex, webhook: *created}
 
 			c.logger.Info("Created webhook",
@@ -174,7 +191,7 @@ func (c *Client) CreateWebhooks(repos []models.ExtendedRepository) ([]models.Ext
 }
 
 // UpdateWebhook updates existing webhooks concurrently by updating all webhooks listed in repos.Webhooks
-func (c *Client) UpdateWebhooks(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) UpdateWebhooks(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	maxWorkers := config.GlobalMaxWorkers
 
 	type job struct {
@@ -207,12 +224,27 @@ func (c *Client) UpdateWebhooks(repos []models.ExtendedRepository) ([]models.Ext
 	worker := func() {
 		defer wg.Done()
 		for j := range jobs {
+			item, input := webhookItem(j.repo, j.webhook)
+			if err := c.notRun(ctx, "project", j.repo.ProjectKey, "slug", j.repo.RepositorySlug); err != nil {
+				errCh <- c.Record("update", "webhook", item, input, err)
+				continue
+			}
 			if j.webhook.Id == nil {
-				errCh <- fmt.Errorf("webhook ID is required for update in %s/%s", j.repo.ProjectKey, j.repo.RepositorySlug)
+				errCh <- c.Record("update", "webhook", item, input, fmt.Errorf("webhook ID is required for update in %s/%s", j.repo.ProjectKey, j.repo.RepositorySlug))
+				continue
+			}
+			if c.config.DryRun {
+				if err := c.preview("update", "webhook", item, c.lookupWebhook(ctx, j.repo, utils.Int32PtrToString(j.webhook.Id))); err != nil {
+					errCh <- c.Record("update", "webhook", item, input, err)
+					continue
+				}
+				c.Record("update", "webhook", item, input, nil)
+				resultsCh <- result{repoIndex: j.repoIndex, webhook: j.webhook}
 				continue
 			}
+
 			updated, httpResp, err := c.api.RepositoryAPI.
-				UpdateWebhook1(c.authCtx, j.repo.ProjectKey, utils.Int32PtrToString(j.webhook.Id), j.repo.RepositorySlug).
+				UpdateWebhook1(c.authCtx(ctx), j.repo.ProjectKey, utils.Int32PtrToString(j.webhook.Id), j.repo.RepositorySlug).
 				RestWebhook(j.webhook).
 				Execute()
 
@@ -224,13 +256,15 @@ func (c *Client) UpdateWebhooks(repos []models.ExtendedRepository) ([]models.Ext
 							"project", j.repo.ProjectKey,
 							"repo", j.repo.RepositorySlug,
 							"id", utils.Int32PtrToString(j.webhook.Id))
+						errCh <- c.Record("update", "webhook", item, input, fmt.Errorf("webhook %s not found in %s/%s, not updated: %w", utils.Int32PtrToString(j.webhook.Id), j.repo.ProjectKey, j.repo.RepositorySlug, err))
 						continue
 					}
 				}
-				errCh <- fmt.Errorf("failed to update webhook %s in %s/%s: %w", utils.Int32PtrToString(j.webhook.Id), j.repo.ProjectKey, j.repo.RepositorySlug, err)
+				errCh <- c.Record("update", "webhook", item, input, fmt.Errorf("failed to update webhook %s in %s/%s: %w", utils.Int32PtrToString(j.webhook.Id), j.repo.ProjectKey, j.repo.RepositorySlug, err))
 				continue
 			}
 
+			c.Record("update", "webhook", item, input, nil)
 			resultsCh <- result{repoIndex: j.repoIndex, webhook: *updated}
 
 			c.logger.Info("Updated webhook",
@@ -293,7 +327,7 @@ func (c *Client) UpdateWebhooks(repos []models.ExtendedRepository) ([]models.Ext
 }
 
 // DeleteWebhook deletes all webhooks listed in repos.Webhooks concurrently.
-func (c *Client) DeleteWebhooks(repos []models.ExtendedRepository) error {
+func (c *Client) DeleteWebhooks(ctx context.Context, repos []models.ExtendedRepository) error {
 	maxWorkers := config.GlobalMaxWorkers
 
 	type job struct {
@@ -315,18 +349,32 @@ func (c *Client) DeleteWebhooks(repos []models.ExtendedRepository) error {
 	worker := func() {
 		defer wg.Done()
 		for j := range jobs {
+			item, input := webhookItem(j.repo, j.webhook)
+			if err := c.notRun(ctx, "project", j.repo.ProjectKey, "slug", j.repo.RepositorySlug); err != nil {
+				errCh <- c.Record("delete", "webhook", item, input, err)
+				continue
+			}
 			if j.webhook.Id == nil {
 				c.logger.Error("Delete webhook failed",
 					"project", j.repo.ProjectKey,
 					"repo", j.repo.RepositorySlug,
 					"id", nil,
 					"error", "webhook ID is required")
-				errCh <- fmt.Errorf("delete webhook failed: missing id")
+				errCh <- c.Record("delete", "webhook", item, input, fmt.Errorf("delete webhook failed: missing id"))
+				continue
+			}
+
+			if c.config.DryRun {
+				if err := c.preview("delete", "webhook", item, c.lookupWebhook(ctx, j.repo, utils.Int32PtrToString(j.webhook.Id))); err != nil {
+					errCh <- c.Record("delete", "webhook", item, input, err)
+					continue
+				}
+				c.Record("delete", "webhook", item, input, nil)
 				continue
 			}
 
 			httpResp, err := c.api.RepositoryAPI.
-				DeleteWebhook1(c.authCtx, j.repo.ProjectKey, utils.Int32PtrToString(j.webhook.Id), j.repo.RepositorySlug).
+				DeleteWebhook1(c.authCtx(ctx), j.repo.ProjectKey, utils.Int32PtrToString(j.webhook.Id), j.repo.RepositorySlug).
 				Execute()
 			if err != nil {
 				if httpResp != nil {
@@ -336,6 +384,7 @@ func (c *Client) DeleteWebhooks(repos []models.ExtendedRepository) error {
 							"project", j.repo.ProjectKey,
 							"repo", j.repo.RepositorySlug,
 							"id", utils.Int32PtrToString(j.webhook.Id))
+						c.Record("delete", "webhook", item, input, nil)
 						continue
 					}
 				}
@@ -344,10 +393,11 @@ func (c *Client) DeleteWebhooks(repos []models.ExtendedRepository) error {
 					"repo", j.repo.RepositorySlug,
 					"id", utils.Int32PtrToString(j.webhook.Id),
 					"error", err)
-				errCh <- err
+				errCh <- c.Record("delete", "webhook", item, input, err)
 				continue
 			}
 
+			c.Record("delete", "webhook", item, input, nil)
 			c.logger.Info("Deleted webhook (or was not present)",
 				"project", j.repo.ProjectKey,
 				"repo", j.repo.RepositorySlug,
@@ -441,3 +491,14 @@ func equalBoolPtr(a, b *bool) bool {
 // 	// 3. when not found
 // 	return c.CreateWebhook(w)
 // }
+
+// webhookItem returns the report name of a webhook and its repository entry
+func webhookItem(repo models.ExtendedRepository, wh openapi.RestWebhook) (string, models.ExtendedRepository) {
+	name := utils.SafeValue(wh.Name)
+	if wh.Id != nil {
+		name += " (id " + utils.Int32PtrToString(wh.Id) + ")"
+	}
+	return repoItem(repo, name, func(r *models.ExtendedRepository) {
+		r.Webhooks = &[]openapi.RestWebhook{wh}
+	})
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds global CLI configuration
@@ -15,6 +18,34 @@ type Config struct {
 	PageSize         int
 	GlobalMaxWorkers int
 	Insecure         bool
+	AuthMethod       string
+	CAFile           string
+	ClientCert       string
+	ClientKey        string
+	Proxy            string
+	NoProxy          string
+	Context          string
+	Retries          int
+	RetryMaxWait     time.Duration
+	RateLimit        float64 // requests per second, 0 means unlimited
+	RateBurst        int
+	DryRun           bool // preview changes, mutating requests are not sent
+
+	// HTTP exchanges are written to RecordDir, or served from ReplayDir instead of the network
+	RecordDir string
+	ReplayDir string
+
+	// Safeguards of delete commands
+	Protected      []string // patterns of resources that are not deleted without ForceProtected
+	ForceProtected bool
+	AssumeYes      bool // do not ask for confirmation
+	MaxDeletes     int  // maximum number of targets of one delete command, 0 means unlimited
+	BackupDir      string
+	NoBackup       bool
+
+	// Credential sources used when token/password are not given directly
+	TokenFile         string
+	CredentialProcess string
 }
 
 var (
@@ -23,39 +54,224 @@ var (
 	GlobalMaxWorkers int
 )
 
-// LoadConfig loads configuration from environment variables
-func LoadConfig() (*Config, error) {
-	pageSize := 50
+// LoadConfig loads configuration from the selected context of the config file
+// and overrides it with environment variables. An empty contextName falls back
+// to BBCTL_CONTEXT and then to the current context of the file.
+func LoadConfig(contextName string) (*Config, error) {
+	ctx, err := resolveContext(contextName)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := contextConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	if val := os.Getenv("BITBUCKET_PAGE_SIZE"); val != "" {
 		if ps, err := strconv.Atoi(val); err == nil {
-			pageSize = ps
+			cfg.PageSize = ps
 		}
 	}
-
-	maxWorkers := 5
 	if val := os.Getenv("BITBUCKET_MAX_WORKERS"); val != "" {
 		if mw, err := strconv.Atoi(val); err == nil {
-			maxWorkers = mw
+			cfg.GlobalMaxWorkers = mw
+		}
+	}
+	GlobalMaxWorkers = cfg.GlobalMaxWorkers
+	if val := os.Getenv("BITBUCKET_RETRIES"); val != "" {
+		if r, err := strconv.Atoi(val); err == nil && r >= 0 {
+			cfg.Retries = r
+		}
+	}
+	if val := os.Getenv("BITBUCKET_RATE_LIMIT"); val != "" {
+		r, err := ParseRateLimit(val)
+		if err != nil {
+			return nil, fmt.Errorf("BITBUCKET_RATE_LIMIT: %w", err)
+		}
+		cfg.RateLimit = r
+	}
+	if val := os.Getenv("BITBUCKET_RATE_BURST"); val != "" {
+		if b, err := strconv.Atoi(val); err == nil {
+			cfg.RateBurst = b
+		}
+	}
+	if val := os.Getenv("BITBUCKET_RETRY_MAX_WAIT"); val != "" {
+		if d, err := time.ParseDuration(val); err == nil {
+			cfg.RetryMaxWait = d
 		}
 	}
-	GlobalMaxWorkers = maxWorkers
-
-	baseURL := os.Getenv("BITBUCKET_BASE_URL")
-	token := os.Getenv("BITBUCKET_TOKEN")
-	username := os.Getenv("BITBUCKET_USERNAME")
-	password := os.Getenv("BITBUCKET_PASSWORD")
-	insecure := os.Getenv("BITBUCKET_INSECURE") == "true"
 
-	cfg := &Config{
-		BaseURL:          baseURL,
-		Token:            token,
-		Username:         username,
-		Password:         password,
-		PageSize:         pageSize,
-		GlobalMaxWorkers: maxWorkers,
-		Insecure:         insecure,
+	if val := os.Getenv("BITBUCKET_BASE_URL"); val != "" {
+		cfg.BaseURL = val
+	}
+	if val := os.Getenv("BITBUCKET_USERNAME"); val != "" {
+		cfg.Username = val
+	}
+	if val := os.Getenv("BITBUCKET_INSECURE"); val != "" {
+		cfg.Insecure = val == "true"
+	}
+	for env, field := range map[string]*string{
+		"BITBUCKET_CA_FILE":     &cfg.CAFile,
+		"BITBUCKET_CLIENT_CERT": &cfg.ClientCert,
+		"BITBUCKET_CLIENT_KEY":  &cfg.ClientKey,
+		"BITBUCKET_PROXY":       &cfg.Proxy,
+		"NO_PROXY":              &cfg.NoProxy,
+	} {
+		if val := os.Getenv(env); val != "" {
+			*field = val
+		}
+	}
+	if cfg.NoProxy == "" {
+		cfg.NoProxy = os.Getenv("no_proxy")
+	}
+	if val := os.Getenv("BITBUCKET_TOKEN_FILE"); val != "" {
+		cfg.TokenFile = val
+	}
+	if val := os.Getenv("BITBUCKET_CREDENTIAL_PROCESS"); val != "" {
+		cfg.CredentialProcess = val
 	}
+	if val := os.Getenv("BITBUCKET_BACKUP_DIR"); val != "" {
+		cfg.BackupDir = val
+	}
+	if val := os.Getenv("BITBUCKET_PROTECTED"); val != "" {
+		cfg.Protected = SplitList(val)
+	}
+	cfg.Token = os.Getenv("BITBUCKET_TOKEN")
+	cfg.Password = os.Getenv("BITBUCKET_PASSWORD")
+
 	GlobalCfg = cfg
 
 	return cfg, nil
 }
+
+// LoadContext loads the configuration of a named context without environment
+// overrides and resolves its credentials. It does not change GlobalCfg and is
+// used by commands talking to more than one instance.
+func LoadContext(name string) (*Config, error) {
+	if name == "" {
+		return nil, fmt.Errorf("context name must not be empty")
+	}
+	ctx, err := resolveContext(name)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := contextConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.ResolveCredentials(); err != nil {
+		return nil, fmt.Errorf("context %s: %w", name, err)
+	}
+	if err := cfg.CheckCredentials(); err != nil {
+		return nil, fmt.Errorf("context %s: %w", name, err)
+	}
+	return cfg, nil
+}
+
+// contextConfig returns the defaults overridden by the values of ctx, if any
+func contextConfig(ctx *Context) (*Config, error) {
+	cfg := &Config{
+		PageSize:         50,
+		GlobalMaxWorkers: 5,
+		Retries:          3,
+		RetryMaxWait:     30 * time.Second,
+	}
+	if ctx == nil {
+		return cfg, nil
+	}
+	cfg.Context = ctx.Name
+	cfg.BaseURL = ctx.BaseURL
+	cfg.AuthMethod = ctx.Auth
+	cfg.Username = ctx.Username
+	cfg.Insecure = ctx.Insecure
+	cfg.CAFile = ctx.CAFile
+	cfg.ClientCert = ctx.ClientCert
+	cfg.ClientKey = ctx.ClientKey
+	cfg.Proxy = ctx.Proxy
+	cfg.NoProxy = ctx.NoProxy
+	cfg.TokenFile = ctx.TokenFile
+	cfg.CredentialProcess = ctx.CredentialProcess
+	cfg.Protected = ctx.Protected
+	if ctx.PageSize > 0 {
+		cfg.PageSize = ctx.PageSize
+	}
+	if ctx.MaxWorkers > 0 {
+		cfg.GlobalMaxWorkers = ctx.MaxWorkers
+	}
+	if ctx.Retries != nil {
+		cfg.Retries = *ctx.Retries
+	}
+	if ctx.RateLimit != "" {
+		r, err := ParseRateLimit(ctx.RateLimit)
+		if err != nil {
+			return nil, fmt.Errorf("context %s: %w", ctx.Name, err)
+		}
+		cfg.RateLimit = r
+	}
+	if ctx.RateBurst > 0 {
+		cfg.RateBurst = ctx.RateBurst
+	}
+	if ctx.RetryMaxWait != "" {
+		d, err := time.ParseDuration(ctx.RetryMaxWait)
+		if err != nil {
+			return nil, fmt.Errorf("context %s: invalid retryMaxWait: %w", ctx.Name, err)
+		}
+		cfg.RetryMaxWait = d
+	}
+	return cfg, nil
+}
+
+// UseBasicAuth reports whether username/password should be used instead of the token
+func (c *Config) UseBasicAuth() bool {
+	switch c.AuthMethod {
+	case AuthBasic:
+		return true
+	case AuthToken:
+		return false
+	default:
+		return c.Username != "" && c.Password != ""
+	}
+}
+
+// CheckCredentials verifies that the credentials required by the auth method are set
+func (c *Config) CheckCredentials() error {
+	switch c.AuthMethod {
+	case AuthBasic:
+		if c.Username == "" || c.Password == "" {
+			return fmt.Errorf("username and password must be provided for basic auth")
+		}
+	case AuthToken:
+		if c.Token == "" {
+			return fmt.Errorf("token must be provided for token auth")
+		}
+	default:
+		if c.Token == "" && (c.Username == "" || c.Password == "") {
+			return fmt.Errorf("either token or username/password must be provided")
+		}
+	}
+	return nil
+}
+
+// ParseRateLimit parses a request rate like "10/s", "600/m" or "10" (per second)
+// and returns requests per second
+func ParseRateLimit(v string) (float64, error) {
+	v = strings.TrimSpace(v)
+	per := time.Second
+	if n, unit, ok := strings.Cut(v, "/"); ok {
+		switch unit {
+		case "s":
+		case "m":
+			per = time.Minute
+		case "h":
+			per = time.Hour
+		default:
+			return 0, fmt.Errorf("invalid rate limit %q, expected N/s, N/m or N/h", v)
+		}
+		v = n
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid rate limit %q, expected N/s, N/m or N/h", v)
+	}
+	return n / per.Seconds(), nil
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Context is a named connection profile stored in the config file.
+// Secrets are not stored here; a context may point to a token file or a
+// credential process instead.
+type Context struct {
+	Name       string `json:"name" yaml:"name"`
+	BaseURL    string `json:"baseUrl,omitempty" yaml:"baseUrl,omitempty"`
+	Auth       string `json:"auth,omitempty" yaml:"auth,omitempty"` // token or basic
+	Username   string `json:"username,omitempty" yaml:"username,omitempty"`
+	PageSize   int    `json:"pageSize,omitempty" yaml:"pageSize,omitempty"`
+	MaxWorkers int    `json:"maxWorkers,omitempty" yaml:"maxWorkers,omitempty"`
+	Insecure   bool   `json:"insecure,omitempty" yaml:"insecure,omitempty"`
+	CAFile     string `json:"caFile,omitempty" yaml:"caFile,omitempty"`
+	ClientCert string `json:"clientCert,omitempty" yaml:"clientCert,omitempty"`
+	ClientKey  string `json:"clientKey,omitempty" yaml:"clientKey,omitempty"`
+	Proxy      string `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	NoProxy    string `json:"noProxy,omitempty" yaml:"noProxy,omitempty"`
+
+	Retries      *int   `json:"retries,omitempty" yaml:"retries,omitempty"`
+	RetryMaxWait string `json:"retryMaxWait,omitempty" yaml:"retryMaxWait,omitempty"` // e.g. 30s
+	RateLimit    string `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`       // e.g. 10/s
+	RateBurst    int    `json:"rateBurst,omitempty" yaml:"rateBurst,omitempty"`
+
+	TokenFile         string `json:"tokenFile,omitempty" yaml:"tokenFile,omitempty"`
+	CredentialProcess string `json:"credentialProcess,omitempty" yaml:"credentialProcess,omitempty"`
+
+	// Protected lists resources that delete commands refuse to delete without --force-protected
+	Protected []string `json:"protected,omitempty" yaml:"protected,omitempty"`
+}
+
+// File is the content of ~/.config/bbctl/config.yaml
+type File struct {
+	CurrentContext string    `json:"currentContext,omitempty" yaml:"currentContext,omitempty"`
+	Contexts       []Context `json:"contexts" yaml:"contexts"`
+}
+
+// Auth methods of a context
+const (
+	AuthToken = "token"
+	AuthBasic = "basic"
+)
+
+// ConfigFilePath returns the config file location: BBCTL_CONFIG if set,
+// otherwise $XDG_CONFIG_HOME/bbctl/config.yaml or ~/.config/bbctl/config.yaml
+func ConfigFilePath() (string, error) {
+	if p := os.Getenv("BBCTL_CONFIG"); p != "" {
+		return p, nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find home directory: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "bbctl", "config.yaml"), nil
+}
+
+// DefaultBackupDir returns the directory of pre-delete backups, backups/ next to the config file
+func DefaultBackupDir() (string, error) {
+	path, err := ConfigFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "backups"), nil
+}
+
+// LoadFile reads the config file. A missing file results in an empty config.
+func LoadFile(path string) (*File, error) {
+	f := &File{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	for i, c := range f.Contexts {
+		if c.Name == "" {
+			return nil, fmt.Errorf("context at index %d in %s is missing required field 'name'", i, path)
+		}
+		if err := ValidateAuth(c.Auth); err != nil {
+			return nil, fmt.Errorf("context %s: %w", c.Name, err)
+		}
+	}
+	return f, nil
+}
+
+// Save writes the config file, creating its directory if needed
+func (f *File) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// GetContext returns the context with the given name
+func (f *File) GetContext(name string) (*Context, bool) {
+	for i := range f.Contexts {
+		if f.Contexts[i].Name == name {
+			return &f.Contexts[i], true
+		}
+	}
+	return nil, false
+}
+
+// SetContext adds the context or replaces the one with the same name
+func (f *File) SetContext(c Context) {
+	if existing, ok := f.GetContext(c.Name); ok {
+		*existing = c
+		return
+	}
+	f.Contexts = append(f.Contexts, c)
+}
+
+// ValidateAuth checks an auth method, empty means detect from the credentials
+func ValidateAuth(auth string) error {
+	switch auth {
+	case "", AuthToken, AuthBasic:
+		return nil
+	default:
+		return fmt.Errorf("invalid auth method %q, allowed values: %s, %s", auth, AuthToken, AuthBasic)
+	}
+}
+
+// resolveContext selects the context by name, BBCTL_CONTEXT or the current context of the file
+func resolveContext(name string) (*Context, error) {
+	if name == "" {
+		name = os.Getenv("BBCTL_CONTEXT")
+	}
+
+	path, err := ConfigFilePath()
+	if err != nil {
+		if name != "" {
+			return nil, err
+		}
+		return nil, nil
+	}
+	f, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = f.CurrentContext
+	}
+	if name == "" {
+		return nil, nil
+	}
+	c, ok := f.GetContext(name)
+	if !ok {
+		return nil, fmt.Errorf("context %q not found in %s", name, path)
+	}
+	return c, nil
+}
@@ -0,0 +1,246 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Credentials returned by a credential source; empty fields are not set
+type Credentials struct {
+	Token    string
+	Username string
+	Password string
+}
+
+// CredentialSource provides credentials when they are not given directly
+// with BITBUCKET_TOKEN/BITBUCKET_PASSWORD or --token/--password
+type CredentialSource interface {
+	// Name is used in error and log messages
+	Name() string
+	// Credentials returns nil when the source has nothing for this instance
+	Credentials(cfg *Config) (*Credentials, error)
+}
+
+// TokenFileSource reads the token from a file, e.g. one mounted by a secret manager
+type TokenFileSource struct {
+	Path string
+}
+
+func (s TokenFileSource) Name() string { return "token file " + s.Path }
+
+func (s TokenFileSource) Credentials(cfg *Config) (*Credentials, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return nil, fmt.Errorf("file is empty")
+	}
+	return &Credentials{Token: token}, nil
+}
+
+// ProcessSource runs an external command and uses its trimmed stdout as the token.
+// The command is run by the shell with BITBUCKET_BASE_URL set to the base URL.
+type ProcessSource struct {
+	Command string
+}
+
+func (s ProcessSource) Name() string { return "credential process" }
+
+func (s ProcessSource) Credentials(cfg *Config) (*Credentials, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", s.Command)
+	} else {
+		cmd = exec.Command("sh", "-c", s.Command)
+	}
+	cmd.Env = append(os.Environ(), "BITBUCKET_BASE_URL="+cfg.BaseURL)
+	cmd.Stderr = os.Stderr
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("command failed: %w", err)
+	}
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return nil, fmt.Errorf("command returned an empty token")
+	}
+	return &Credentials{Token: token}, nil
+}
+
+// NetrcSource looks up the login and password of the base URL host in a .netrc file
+type NetrcSource struct {
+	Path string
+}
+
+func (s NetrcSource) Name() string { return "netrc " + s.Path }
+
+func (s NetrcSource) Credentials(cfg *Config) (*Credentials, error) {
+	u, err := url.Parse(cfg.BaseURL)
+	if err != nil || u.Hostname() == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var match, fallback *netrcEntry
+	for _, e := range parseNetrc(string(data)) {
+		if cfg.Username != "" && e.login != "" && e.login != cfg.Username {
+			continue
+		}
+		if e.machine == u.Host || e.machine == u.Hostname() {
+			match = &e
+			break
+		}
+		if e.isDefault && fallback == nil {
+			fallback = &e
+		}
+	}
+	if match == nil {
+		match = fallback
+	}
+	if match == nil || match.password == "" {
+		return nil, nil
+	}
+	return &Credentials{Username: match.login, Password: match.password}, nil
+}
+
+type netrcEntry struct {
+	machine   string
+	isDefault bool
+	login     string
+	password  string
+}
+
+// parseNetrc parses machine/default entries; macdef blocks are skipped
+func parseNetrc(data string) []netrcEntry {
+	var entries []netrcEntry
+	var cur *netrcEntry
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if j := strings.Index(line, "#"); j >= 0 {
+			line = line[:j]
+		}
+		fields := strings.Fields(line)
+		for k := 0; k < len(fields); k++ {
+			next := func() string {
+				if k+1 < len(fields) {
+					k++
+					return fields[k]
+				}
+				return ""
+			}
+			switch fields[k] {
+			case "machine":
+				entries = append(entries, netrcEntry{machine: next()})
+				cur = &entries[len(entries)-1]
+			case "default":
+				entries = append(entries, netrcEntry{isDefault: true})
+				cur = &entries[len(entries)-1]
+			case "login":
+				if v := next(); cur != nil {
+					cur.login = v
+				}
+			case "password":
+				if v := next(); cur != nil {
+					cur.password = v
+				}
+			case "account":
+				next()
+			case "macdef":
+				// skip the macro body up to the next empty line
+				for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+					i++
+				}
+				k = len(fields)
+			}
+		}
+	}
+	return entries
+}
+
+// netrcPath returns NETRC if set, otherwise ~/.netrc
+func netrcPath() string {
+	if p := os.Getenv("NETRC"); p != "" {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// CredentialSources returns the configured sources in the order they are tried:
+// token file, credential process, .netrc
+func (c *Config) CredentialSources() []CredentialSource {
+	var sources []CredentialSource
+	if c.TokenFile != "" {
+		sources = append(sources, TokenFileSource{Path: c.TokenFile})
+	}
+	if c.CredentialProcess != "" {
+		sources = append(sources, ProcessSource{Command: c.CredentialProcess})
+	}
+	if p := netrcPath(); p != "" {
+		sources = append(sources, NetrcSource{Path: p})
+	}
+	return sources
+}
+
+// ResolveCredentials fills missing credentials from the credential sources.
+// Credentials given directly by environment variables or flags take precedence.
+func (c *Config) ResolveCredentials() error {
+	for _, src := range c.CredentialSources() {
+		if c.CheckCredentials() == nil {
+			return nil
+		}
+		creds, err := src.Credentials(c)
+		if err != nil {
+			return fmt.Errorf("failed to get credentials from %s: %w", src.Name(), err)
+		}
+		if creds == nil {
+			continue
+		}
+		if GlobalLogger != nil {
+			GlobalLogger.Debug("Using credentials", "source", src.Name())
+		}
+		c.applyCredentials(creds)
+	}
+	return nil
+}
+
+// applyCredentials sets the credentials that are still empty
+func (c *Config) applyCredentials(creds *Credentials) {
+	if creds.Token != "" && c.Token == "" {
+		c.Token = creds.Token
+	}
+	if creds.Password == "" {
+		return
+	}
+	// a token stored as netrc password
+	if c.AuthMethod == AuthToken {
+		if c.Token == "" {
+			c.Token = creds.Password
+		}
+		return
+	}
+	if c.Password == "" {
+		c.Password = creds.Password
+		if c.Username == "" {
+			c.Username = creds.Username
+		}
+	}
+}
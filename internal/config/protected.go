@@ -0,0 +1,95 @@
+package config
+
+import (
+	"path"
+	"strings"
+)
+
+// Kinds of resources matched by protected patterns
+const (
+	KindProject    = "project"
+	KindRepository = "repository"
+	KindUser       = "user"
+	KindGroup      = "group"
+	KindPermission = "permission"
+	KindMember     = "member"
+)
+
+// ProtectedBy returns the first protected pattern matching the target, or ""
+// if it is not protected. Targets are a project key, a repository as
+// <projectKey>/<slug>, a user name, a group name or a permission as
+// "<project or repository> user:<name>" (or group:<name>), which is protected
+// when its project, repository, user or group is, or a group member as
+// "<group> user:<name>", which is protected when its group or user is. The user
+// bbctl authenticates as is always protected. Patterns are shell globs compared case-insensitively:
+//
+//	PRJ          the project PRJ and all of its repositories
+//	PRJ/repo-*   repositories of PRJ whose slug starts with repo-
+//	user:admin*  users
+//	group:admins groups
+func (c *Config) ProtectedBy(kind, target string) string {
+	if kind == KindPermission {
+		resource, principal, _ := strings.Cut(target, " ")
+		resourceKind := KindProject
+		if strings.Contains(resource, "/") {
+			resourceKind = KindRepository
+		}
+		if pattern := c.ProtectedBy(resourceKind, resource); pattern != "" {
+			return pattern
+		}
+		principalKind, name, _ := strings.Cut(principal, ":")
+		return c.ProtectedBy(principalKind, name)
+	}
+	if kind == KindMember {
+		group, member, _ := strings.Cut(target, " ")
+		if pattern := c.ProtectedBy(KindGroup, group); pattern != "" {
+			return pattern
+		}
+		return c.ProtectedBy(KindUser, strings.TrimPrefix(member, KindUser+":"))
+	}
+	if kind == KindUser && c.Username != "" && strings.EqualFold(target, c.Username) {
+		return "user:" + c.Username
+	}
+
+	target = strings.ToLower(target)
+	for _, pattern := range c.Protected {
+		p := strings.ToLower(strings.TrimSpace(pattern))
+		if p == "" {
+			continue
+		}
+		var matched bool
+		switch {
+		case strings.HasPrefix(p, KindUser+":"):
+			matched = kind == KindUser && match(strings.TrimPrefix(p, KindUser+":"), target)
+		case strings.HasPrefix(p, KindGroup+":"):
+			matched = kind == KindGroup && match(strings.TrimPrefix(p, KindGroup+":"), target)
+		case strings.Contains(p, "/"):
+			matched = kind == KindRepository && match(p, target)
+		case kind == KindProject:
+			matched = match(p, target)
+		case kind == KindRepository:
+			projectKey, _, _ := strings.Cut(target, "/")
+			matched = match(p, projectKey)
+		}
+		if matched {
+			return pattern
+		}
+	}
+	return ""
+}
+
+func match(pattern, name string) bool {
+	ok, err := path.Match(pattern, name)
+	return err == nil && ok
+}
+
+// SplitList splits a comma-separated list, dropping empty entries
+func SplitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
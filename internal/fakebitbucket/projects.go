@@ -0,0 +1,383 @@
+package fakebitbucket
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultBranch is the default branch of new repositories
+const defaultBranch = "main"
+
+var slugInvalid = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// slugify derives a repository slug from its name like Bitbucket does
+func slugify(name string) string {
+	return strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// SetFile stores the content of a file in the default branch of a repository,
+// served by the raw file endpoint. The repository must exist.
+func (s *Server) SetFile(projectKey, repositorySlug, path string, content []byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.projects[strings.ToUpper(projectKey)]
+	if !ok {
+		return false
+	}
+	repo, ok := p.repos[repositorySlug]
+	if !ok {
+		return false
+	}
+	repo.files[strings.TrimPrefix(path, "/")] = content
+	return true
+}
+
+// project returns the project of the request path, writing a 404 response when it does not exist
+func (s *Server) project(w http.ResponseWriter, r *http.Request) (*project, bool) {
+	key := r.PathValue("projectKey")
+	p, ok := s.projects[strings.ToUpper(key)]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Project %s does not exist.", key)
+	}
+	return p, ok
+}
+
+// repository returns the repository of the request path, writing a 404 response when it does not exist
+func (s *Server) repository(w http.ResponseWriter, r *http.Request) (*repository, bool) {
+	p, ok := s.project(w, r)
+	if !ok {
+		return nil, false
+	}
+	slug := r.PathValue("repositorySlug")
+	repo, ok := p.repos[slug]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Repository %s/%s does not exist.", r.PathValue("projectKey"), slug)
+	}
+	return repo, ok
+}
+
+func (s *Server) getProjects(w http.ResponseWriter, r *http.Request) {
+	var all []object
+	for _, p := range s.projects {
+		all = append(all, p.data)
+	}
+	writePage(w, r, filterSorted(all, "name", r.URL.Query().Get("name")))
+}
+
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request) {
+	var body object
+	if !decode(w, r, &body) {
+		return
+	}
+	key := strings.ToUpper(str(body["key"]))
+	if key == "" {
+		writeError(w, http.StatusBadRequest, "Project key is required.")
+		return
+	}
+	if _, ok := s.projects[key]; ok {
+		writeError(w, http.StatusConflict, "Project key %s is already in use.", key)
+		return
+	}
+	data := object{"id": s.id(), "key": key, "name": key, "public": false, "type": "NORMAL"}
+	merge(data, body, "name", "description", "public")
+	data["links"] = object{"self": []object{{"href": "/projects/" + key}}}
+	s.projects[key] = &project{data: data, repos: map[string]*repository{}, userPerms: map[string]string{}, groupPerms: map[string]string{}}
+	writeJSON(w, http.StatusCreated, data)
+}
+
+func (s *Server) getProject(w http.ResponseWriter, r *http.Request) {
+	if p, ok := s.project(w, r); ok {
+		writeJSON(w, http.StatusOK, p.data)
+	}
+}
+
+func (s *Server) updateProject(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.project(w, r)
+	if !ok {
+		return
+	}
+	var body object
+	if !decode(w, r, &body) {
+		return
+	}
+	merge(p.data, body, "name", "description", "public")
+	writeJSON(w, http.StatusOK, p.data)
+}
+
+func (s *Server) deleteProject(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.project(w, r)
+	if !ok {
+		return
+	}
+	if len(p.repos) > 0 {
+		writeError(w, http.StatusConflict, "Project %s cannot be deleted because it has repositories.", p.data["key"])
+		return
+	}
+	delete(s.projects, str(p.data["key"]))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getRepositories(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.project(w, r)
+	if !ok {
+		return
+	}
+	var all []object
+	for _, repo := range p.repos {
+		all = append(all, repo.data)
+	}
+	writePage(w, r, filterSorted(all, "slug", ""))
+}
+
+// newRepository stores a repository named name in p
+func (s *Server) newRepository(p *project, name string, body object) (*repository, bool) {
+	slug := slugify(name)
+	if _, ok := p.repos[slug]; ok || slug == "" {
+		return nil, false
+	}
+	data := object{
+		"id": s.id(), "slug": slug, "name": name, "scmId": "git", "state": "AVAILABLE",
+		"forkable": true, "public": false, "project": p.data,
+		"links": object{"self": []object{{"href": "/projects/" + str(p.data["key"]) + "/repos/" + slug + "/browse"}}},
+	}
+	merge(data, body, "description", "forkable", "public")
+	repo := &repository{
+		data:          data,
+		defaultBranch: defaultBranch,
+		files:         map[string][]byte{},
+		userPerms:     map[string]string{},
+		groupPerms:    map[string]string{},
+		workzone:      map[string]any{},
+	}
+	if b := str(body["defaultBranch"]); b != "" {
+		repo.defaultBranch = strings.TrimPrefix(b, "refs/heads/")
+	}
+	p.repos[slug] = repo
+	return repo, true
+}
+
+func (s *Server) createRepository(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.project(w, r)
+	if !ok {
+		return
+	}
+	var body object
+	if !decode(w, r, &body) {
+		return
+	}
+	name := str(body["name"])
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "Repository name is required.")
+		return
+	}
+	repo, ok := s.newRepository(p, name, body)
+	if !ok {
+		writeError(w, http.StatusConflict, "Repository %s already exists or has an invalid name.", name)
+		return
+	}
+	writeJSON(w, http.StatusCreated, repo.data)
+}
+
+func (s *Server) getRepository(w http.ResponseWriter, r *http.Request) {
+	if repo, ok := s.repository(w, r); ok {
+		writeJSON(w, http.StatusOK, repo.data)
+	}
+}
+
+func (s *Server) updateRepository(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var body object
+	if !decode(w, r, &body) {
+		return
+	}
+	merge(repo.data, body, "description", "forkable", "public")
+	if b := str(body["defaultBranch"]); b != "" {
+		repo.defaultBranch = strings.TrimPrefix(b, "refs/heads/")
+	}
+	// Renaming a repository changes its slug
+	if name := str(body["name"]); name != "" && name != str(repo.data["name"]) {
+		p := s.projects[strings.ToUpper(r.PathValue("projectKey"))]
+		slug := slugify(name)
+		if _, taken := p.repos[slug]; taken && slug != repo.data["slug"] {
+			writeError(w, http.StatusConflict, "Repository %s already exists.", name)
+			return
+		}
+		delete(p.repos, str(repo.data["slug"]))
+		repo.data["name"] = name
+		repo.data["slug"] = slug
+		p.repos[slug] = repo
+	}
+	writeJSON(w, http.StatusOK, repo.data)
+}
+
+func (s *Server) forkRepository(w http.ResponseWriter, r *http.Request) {
+	origin, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var body object
+	if !decode(w, r, &body) {
+		return
+	}
+	target := s.projects[strings.ToUpper(r.PathValue("projectKey"))]
+	if pr, ok := body["project"].(object); ok && str(pr["key"]) != "" {
+		if target, ok = s.projects[strings.ToUpper(str(pr["key"]))]; !ok {
+			writeError(w, http.StatusNotFound, "Project %s does not exist.", pr["key"])
+			return
+		}
+	}
+	name := str(body["name"])
+	if name == "" {
+		name = str(origin.data["name"])
+	}
+	repo, ok := s.newRepository(target, name, origin.data)
+	if !ok {
+		writeError(w, http.StatusConflict, "Repository %s already exists or has an invalid name.", name)
+		return
+	}
+	repo.defaultBranch = origin.defaultBranch
+	for path, content := range origin.files {
+		repo.files[path] = content
+	}
+	repo.data["origin"] = origin.data
+	writeJSON(w, http.StatusCreated, repo.data)
+}
+
+func (s *Server) deleteRepository(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.repository(w, r); !ok {
+		return
+	}
+	delete(s.projects[strings.ToUpper(r.PathValue("projectKey"))].repos, r.PathValue("repositorySlug"))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) getDefaultBranch(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, object{
+		"id":        "refs/heads/" + repo.defaultBranch,
+		"displayId": repo.defaultBranch,
+		"type":      "BRANCH",
+		"isDefault": true,
+	})
+}
+
+func (s *Server) getRawFile(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	content, ok := repo.files[r.PathValue("path")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "The path \"%s\" does not exist at revision \"%s\"", r.PathValue("path"), repo.defaultBranch)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write(content)
+}
+
+// permissions returns the user and group permissions of the project or repository of the request path
+func (s *Server) permissions(w http.ResponseWriter, r *http.Request) (users, groups map[string]string, ok bool) {
+	if r.PathValue("repositorySlug") != "" {
+		repo, ok := s.repository(w, r)
+		if !ok {
+			return nil, nil, false
+		}
+		return repo.userPerms, repo.groupPerms, true
+	}
+	p, ok := s.project(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+	return p.userPerms, p.groupPerms, true
+}
+
+func (s *Server) getUserPermissions(w http.ResponseWriter, r *http.Request) {
+	users, _, ok := s.permissions(w, r)
+	if !ok {
+		return
+	}
+	values := []object{}
+	for _, name := range sortedKeys(users) {
+		values = append(values, object{"user": s.users[name], "permission": users[name]})
+	}
+	writePage(w, r, values)
+}
+
+func (s *Server) getGroupPermissions(w http.ResponseWriter, r *http.Request) {
+	_, groups, ok := s.permissions(w, r)
+	if !ok {
+		return
+	}
+	values := []object{}
+	for _, name := range sortedKeys(groups) {
+		values = append(values, object{"group": object{"name": s.groups[name].name}, "permission": groups[name]})
+	}
+	writePage(w, r, values)
+}
+
+func (s *Server) setUserPermission(w http.ResponseWriter, r *http.Request) {
+	users, _, ok := s.permissions(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	for _, name := range q["name"] {
+		if _, ok := s.users[strings.ToLower(name)]; !ok {
+			writeError(w, http.StatusNotFound, "User %s does not exist.", name)
+			return
+		}
+	}
+	for _, name := range q["name"] {
+		users[strings.ToLower(name)] = q.Get("permission")
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) setGroupPermission(w http.ResponseWriter, r *http.Request) {
+	_, groups, ok := s.permissions(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	for _, name := range q["name"] {
+		if _, ok := s.groups[strings.ToLower(name)]; !ok {
+			writeError(w, http.StatusNotFound, "Group %s does not exist.", name)
+			return
+		}
+	}
+	for _, name := range q["name"] {
+		groups[strings.ToLower(name)] = q.Get("permission")
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) revokeUserPermission(w http.ResponseWriter, r *http.Request) {
+	if users, _, ok := s.permissions(w, r); ok {
+		delete(users, strings.ToLower(r.URL.Query().Get("name")))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) revokeGroupPermission(w http.ResponseWriter, r *http.Request) {
+	if _, groups, ok := s.permissions(w, r); ok {
+		delete(groups, strings.ToLower(r.URL.Query().Get("name")))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,266 @@
+package fakebitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// object is a JSON object as stored and returned by the fake server
+type object = map[string]any
+
+// Server is an in-memory Bitbucket Data Center with the REST endpoints bbctl uses:
+// projects, repositories, raw files, webhooks, required builds, branch restrictions,
+// reviewer groups, permissions, users, groups and the Workzone resources. It is an
+// http.Handler, so it can be used with httptest.NewServer. Any credentials are
+// accepted. The base URL of a client is the server URL, with or without /rest.
+type Server struct {
+	// Logger logs every request at debug level when set
+	Logger *slog.Logger
+
+	mux      *http.ServeMux
+	mu       sync.Mutex
+	nextID   int
+	projects map[string]*project // by upper-case key
+	users    map[string]object   // by lower-case name
+	groups   map[string]*group   // by lower-case name
+}
+
+type project struct {
+	data       object
+	repos      map[string]*repository // by slug
+	userPerms  map[string]string      // lower-case user name to permission
+	groupPerms map[string]string      // lower-case group name to permission
+}
+
+type repository struct {
+	data           object
+	defaultBranch  string
+	files          map[string][]byte
+	webhooks       []object
+	requiredBuilds []object
+	restrictions   []object
+	reviewerGroups []object
+	userPerms      map[string]string
+	groupPerms     map[string]string
+	workzone       map[string]any // workflow, reviewerslist, signapproverslist, automergelist
+}
+
+type group struct {
+	name    string
+	members map[string]bool // lower-case user names
+}
+
+// New returns an empty server
+func New() *Server {
+	s := &Server{
+		mux:      http.NewServeMux(),
+		projects: map[string]*project{},
+		users:    map[string]object{},
+		groups:   map[string]*group{},
+	}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	for _, v := range []string{"latest", "1.0"} {
+		api := "/api/" + v
+		p := api + "/projects/{projectKey}"
+		r := p + "/repos/{repositorySlug}"
+
+		s.handle("GET "+api+"/projects", s.getProjects)
+		s.handle("POST "+api+"/projects", s.createProject)
+		s.handle("GET "+p, s.getProject)
+		s.handle("PUT "+p, s.updateProject)
+		s.handle("DELETE "+p, s.deleteProject)
+		s.handle("GET "+p+"/permissions/users", s.getUserPermissions)
+		s.handle("PUT "+p+"/permissions/users", s.setUserPermission)
+		s.handle("DELETE "+p+"/permissions/users", s.revokeUserPermission)
+		s.handle("GET "+p+"/permissions/groups", s.getGroupPermissions)
+		s.handle("PUT "+p+"/permissions/groups", s.setGroupPermission)
+		s.handle("DELETE "+p+"/permissions/groups", s.revokeGroupPermission)
+
+		s.handle("GET "+p+"/repos", s.getRepositories)
+		s.handle("POST "+p+"/repos", s.createRepository)
+		s.handle("GET "+r, s.getRepository)
+		s.handle("PUT "+r, s.updateRepository)
+		s.handle("POST "+r, s.forkRepository)
+		s.handle("DELETE "+r, s.deleteRepository)
+		s.handle("GET "+r+"/default-branch", s.getDefaultBranch)
+		s.handle("GET "+r+"/raw/{path...}", s.getRawFile)
+		s.handle("GET "+r+"/permissions/users", s.getUserPermissions)
+		s.handle("PUT "+r+"/permissions/users", s.setUserPermission)
+		s.handle("DELETE "+r+"/permissions/users", s.revokeUserPermission)
+		s.handle("GET "+r+"/permissions/groups", s.getGroupPermissions)
+		s.handle("PUT "+r+"/permissions/groups", s.setGroupPermission)
+		s.handle("DELETE "+r+"/permissions/groups", s.revokeGroupPermission)
+
+		s.handle("GET "+r+"/webhooks", s.getWebhooks)
+		s.handle("POST "+r+"/webhooks", s.createWebhook)
+		s.handle("GET "+r+"/webhooks/{id}", s.getWebhook)
+		s.handle("PUT "+r+"/webhooks/{id}", s.updateWebhook)
+		s.handle("DELETE "+r+"/webhooks/{id}", s.deleteWebhook)
+		s.handle("GET "+r+"/settings/reviewer-groups", s.getReviewerGroups)
+		s.handle("POST "+r+"/settings/reviewer-groups", s.createReviewerGroup)
+		s.handle("GET "+r+"/settings/reviewer-groups/{id}", s.getReviewerGroup)
+		s.handle("PUT "+r+"/settings/reviewer-groups/{id}", s.updateReviewerGroup)
+		s.handle("DELETE "+r+"/settings/reviewer-groups/{id}", s.deleteReviewerGroup)
+
+		s.handle("GET "+api+"/users/{userSlug}", s.getUser)
+		s.handle("GET "+api+"/admin/users", s.getUsers)
+		s.handle("POST "+api+"/admin/users", s.createUser)
+		s.handle("PUT "+api+"/admin/users", s.updateUser)
+		s.handle("DELETE "+api+"/admin/users", s.deleteUser)
+		s.handle("GET "+api+"/admin/groups", s.getGroups)
+		s.handle("POST "+api+"/admin/groups", s.createGroup)
+		s.handle("DELETE "+api+"/admin/groups", s.deleteGroup)
+		s.handle("POST "+api+"/admin/groups/add-users", s.addUsersToGroup)
+		s.handle("POST "+api+"/admin/groups/remove-user", s.removeUserFromGroup)
+		s.handle("GET "+api+"/admin/groups/more-members", s.getGroupMembers)
+	}
+
+	r := "/projects/{projectKey}/repos/{repositorySlug}"
+	s.handle("GET /required-builds/latest"+r+"/conditions", s.getRequiredBuilds)
+	s.handle("POST /required-builds/latest"+r+"/condition", s.createRequiredBuild)
+	s.handle("PUT /required-builds/latest"+r+"/condition/{id}", s.updateRequiredBuild)
+	s.handle("DELETE /required-builds/latest"+r+"/condition/{id}", s.deleteRequiredBuild)
+	s.handle("GET /branch-permissions/latest"+r+"/restrictions", s.getRestrictions)
+	s.handle("POST /branch-permissions/latest"+r+"/restrictions", s.createRestrictions)
+	s.handle("GET /branch-permissions/latest"+r+"/restrictions/{id}", s.getRestriction)
+	s.handle("DELETE /branch-permissions/latest"+r+"/restrictions/{id}", s.deleteRestriction)
+
+	wz := "/workzoneresource/latest"
+	for _, section := range []string{"branch/reviewerslist", "branch/signapproverslist", "branch/automergelist", "workflow"} {
+		path := wz + "/" + section + "/{projectKey}/{repositorySlug}"
+		s.handle("GET "+path, s.getWorkzone(section))
+		s.handle("POST "+path, s.setWorkzone(section))
+		s.handle("PUT "+path, s.updateWorkzone(section))
+		s.handle("DELETE "+path, s.deleteWorkzone(section))
+	}
+}
+
+// handle registers a handler that runs with the state locked
+func (s *Server) handle(pattern string, h http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		h(w, r)
+	})
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.Logger != nil {
+		s.Logger.Debug("Fake server request", "method", r.Method, "path", r.URL.Path, "query", r.URL.RawQuery)
+	}
+	// Clients are configured with the /rest base path of a real instance
+	if rest, ok := strings.CutPrefix(r.URL.Path, "/rest/"); ok {
+		r.URL.Path = "/" + rest
+		r.URL.RawPath = ""
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// id returns a new id, unique across all kinds of objects
+func (s *Server) id() int {
+	s.nextID++
+	return s.nextID
+}
+
+// writeJSON writes v with the given status code
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the format of Bitbucket
+func writeError(w http.ResponseWriter, code int, format string, args ...any) {
+	writeJSON(w, code, object{"errors": []object{{"message": fmt.Sprintf(format, args...)}}})
+}
+
+// writePage writes the values selected by the start and limit query parameters as a page
+func writePage[T any](w http.ResponseWriter, r *http.Request, values []T) {
+	start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 25
+	}
+	start = min(max(start, 0), len(values))
+	end := min(start+limit, len(values))
+	page := object{
+		"start":      start,
+		"limit":      limit,
+		"size":       end - start,
+		"isLastPage": end == len(values),
+		"values":     append([]T{}, values[start:end]...),
+	}
+	if end < len(values) {
+		page["nextPageStart"] = end
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// decode reads the JSON request body into v, writing a 400 response on failure
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: %v", err)
+		return false
+	}
+	return true
+}
+
+// filterSorted returns the objects whose key field contains filter, sorted by that field
+func filterSorted(objects []object, key, filter string) []object {
+	out := []object{}
+	for _, o := range objects {
+		if filter == "" || strings.Contains(strings.ToLower(str(o[key])), strings.ToLower(filter)) {
+			out = append(out, o)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(str(out[i][key])) < strings.ToLower(str(out[j][key])) })
+	return out
+}
+
+// merge copies the given fields of src to dst when present
+func merge(dst, src object, fields ...string) {
+	for _, f := range fields {
+		if v, ok := src[f]; ok {
+			dst[f] = v
+		}
+	}
+}
+
+// str returns a JSON string value, "" for anything else
+func str(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// sameID reports whether a stored id matches a path or body id
+func sameID(o object, id any) bool {
+	return idString(o["id"]) == idString(id)
+}
+
+// idString formats an id of a path or of decoded JSON, where numbers are float64
+func idString(id any) string {
+	if f, ok := id.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(id)
+}
+
+// indexOf returns the position of the object with the id of the request path, or -1
+func indexOf(objects []object, r *http.Request) int {
+	for i, o := range objects {
+		if sameID(o, r.PathValue("id")) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,167 @@
+package fakebitbucket_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/fakebitbucket"
+	"github.com/vinisman/bbctl/internal/models"
+	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
+)
+
+// newClient starts a fake server and returns a client connected to it. The
+// page size is small so listings go through several pages.
+func newClient(t *testing.T) (*fakebitbucket.Server, *bitbucket.Client) {
+	t.Helper()
+	fake := fakebitbucket.New()
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	config.GlobalLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	config.GlobalMaxWorkers = 2
+	client, err := bitbucket.NewClientFor(&config.Config{BaseURL: srv.URL, Token: "fake", PageSize: 2, GlobalMaxWorkers: 2})
+	if err != nil {
+		t.Fatalf("NewClientFor: %v", err)
+	}
+	return fake, client
+}
+
+// newRepo creates the project PRJ with a repository named name
+func newRepo(t *testing.T, client *bitbucket.Client, name string) models.ExtendedRepository {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := client.CreateProjects(ctx, []openapi.RestProject{{Key: openapi.PtrString("PRJ"), Name: openapi.PtrString("Project")}}); err != nil {
+		t.Fatalf("CreateProjects: %v", err)
+	}
+	created, err := client.CreateRepos(ctx, []models.ExtendedRepository{{ProjectKey: "PRJ", RestRepository: &openapi.RestRepository{Name: openapi.PtrString(name)}}})
+	if err != nil {
+		t.Fatalf("CreateRepos: %v", err)
+	}
+	return models.ExtendedRepository{ProjectKey: "PRJ", RepositorySlug: *created[0].RestRepository.Slug}
+}
+
+func TestRepositories(t *testing.T) {
+	_, client := newClient(t)
+	ctx := context.Background()
+	repo := newRepo(t, client, "My Repo")
+	if repo.RepositorySlug != "my-repo" {
+		t.Errorf("slug = %q, want my-repo", repo.RepositorySlug)
+	}
+
+	repos, err := client.GetAllReposForProject(ctx, "PRJ", models.RepositoryOptions{Repository: true, DefaultBranch: true})
+	if err != nil {
+		t.Fatalf("GetAllReposForProject: %v", err)
+	}
+	if len(repos) != 1 || repos[0].RepositorySlug != "my-repo" || repos[0].DefaultBranch != "main" {
+		t.Errorf("repositories = %+v, want my-repo with default branch main", repos)
+	}
+
+	_, err = client.CreateRepos(ctx, []models.ExtendedRepository{{ProjectKey: "PRJ", RestRepository: &openapi.RestRepository{Name: openapi.PtrString("my repo")}}})
+	if err == nil {
+		t.Error("creating a repository with the same slug succeeded")
+	}
+	_, err = client.CreateRepos(ctx, []models.ExtendedRepository{{ProjectKey: "NOPE", RestRepository: &openapi.RestRepository{Name: openapi.PtrString("x")}}})
+	if err == nil {
+		t.Error("creating a repository in a missing project succeeded")
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	_, client := newClient(t)
+	ctx := context.Background()
+	repo := newRepo(t, client, "repo")
+
+	hook := openapi.RestWebhook{
+		Name:   openapi.PtrString("ci"),
+		Url:    openapi.PtrString("https://ci.example.com/hook"),
+		Events: []string{"repo:refs_changed"},
+	}
+	repo.Webhooks = &[]openapi.RestWebhook{hook}
+	if _, err := client.CreateWebhooks(ctx, []models.ExtendedRepository{repo}); err != nil {
+		t.Fatalf("CreateWebhooks: %v", err)
+	}
+
+	got := getWebhooks(t, client, repo)
+	if len(got) != 1 || got[0].Id == nil || *got[0].Name != "ci" {
+		t.Fatalf("webhooks = %+v, want ci with an id", got)
+	}
+
+	got[0].Url = openapi.PtrString("https://ci.example.com/other")
+	repo.Webhooks = &got
+	if _, err := client.UpdateWebhooks(ctx, []models.ExtendedRepository{repo}); err != nil {
+		t.Fatalf("UpdateWebhooks: %v", err)
+	}
+	if got := getWebhooks(t, client, repo); len(got) != 1 || *got[0].Url != "https://ci.example.com/other" {
+		t.Errorf("webhooks after update = %+v", got)
+	}
+
+	if err := client.DeleteWebhooks(ctx, []models.ExtendedRepository{repo}); err != nil {
+		t.Fatalf("DeleteWebhooks: %v", err)
+	}
+	if got := getWebhooks(t, client, repo); len(got) != 0 {
+		t.Errorf("webhooks after delete = %+v, want none", got)
+	}
+
+	// the webhook is gone, so updating it fails and deleting it again is a no-op
+	if _, err := client.UpdateWebhooks(ctx, []models.ExtendedRepository{repo}); err == nil {
+		t.Error("updating a deleted webhook succeeded")
+	}
+	if err := client.DeleteWebhooks(ctx, []models.ExtendedRepository{repo}); err != nil {
+		t.Errorf("deleting a deleted webhook: %v", err)
+	}
+}
+
+func getWebhooks(t *testing.T, client *bitbucket.Client, repo models.ExtendedRepository) []openapi.RestWebhook {
+	t.Helper()
+	repo.Webhooks = nil
+	repos, err := client.GetWebhooks(context.Background(), []models.ExtendedRepository{repo})
+	if err != nil {
+		t.Fatalf("GetWebhooks: %v", err)
+	}
+	if len(repos) != 1 || repos[0].Webhooks == nil {
+		return nil
+	}
+	return *repos[0].Webhooks
+}
+
+func TestUsersAndGroupsPaginate(t *testing.T) {
+	fake, client := newClient(t)
+	ctx := context.Background()
+	for _, name := range []string{"alice", "bob", "carol", "dave", "erin"} {
+		fake.AddUser(name, "", name+"@example.com")
+	}
+	users, err := client.GetAllUsers(ctx)
+	if err != nil {
+		t.Fatalf("GetAllUsers: %v", err)
+	}
+	if len(users) != 5 {
+		t.Errorf("got %d users, want all 5 across pages", len(users))
+	}
+
+	var groups []openapi.RestDetailedGroup
+	for _, name := range []string{"admins", "devs", "ops"} {
+		groups = append(groups, openapi.RestDetailedGroup{Name: openapi.PtrString(name)})
+	}
+	if _, err := client.CreateGroups(ctx, groups); err != nil {
+		t.Fatalf("CreateGroups: %v", err)
+	}
+	all, err := client.GetAllGroups(ctx)
+	if err != nil {
+		t.Fatalf("GetAllGroups: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("got %d groups, want all 3 across pages", len(all))
+	}
+
+	if _, err := client.DeleteUsers(ctx, []string{"bob"}); err != nil {
+		t.Fatalf("DeleteUsers: %v", err)
+	}
+	if users, _ := client.GetAllUsers(ctx); len(users) != 4 {
+		t.Errorf("got %d users after delete, want 4", len(users))
+	}
+}
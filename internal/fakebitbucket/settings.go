@@ -0,0 +1,389 @@
+package fakebitbucket
+
+import (
+	"net/http"
+	"strings"
+)
+
+func (s *Server) getWebhooks(w http.ResponseWriter, r *http.Request) {
+	if repo, ok := s.repository(w, r); ok {
+		writePage(w, r, repo.webhooks)
+	}
+}
+
+func (s *Server) getWebhook(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	i := indexOf(repo.webhooks, r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Webhook %s does not exist.", r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, repo.webhooks[i])
+}
+
+func (s *Server) createWebhook(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var body object
+	if !decode(w, r, &body) {
+		return
+	}
+	if str(body["name"]) == "" || str(body["url"]) == "" {
+		writeError(w, http.StatusBadRequest, "Webhook name and url are required.")
+		return
+	}
+	hook := object{"id": s.id(), "active": true, "events": []any{}, "configuration": object{}, "scopeType": "repository"}
+	merge(hook, body, "name", "url", "active", "events", "configuration", "sslVerificationRequired")
+	repo.webhooks = append(repo.webhooks, hook)
+	writeJSON(w, http.StatusCreated, hook)
+}
+
+func (s *Server) updateWebhook(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	i := indexOf(repo.webhooks, r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Webhook %s does not exist.", r.PathValue("id"))
+		return
+	}
+	var body object
+	if !decode(w, r, &body) {
+		return
+	}
+	merge(repo.webhooks[i], body, "name", "url", "active", "events", "configuration", "sslVerificationRequired")
+	writeJSON(w, http.StatusOK, repo.webhooks[i])
+}
+
+func (s *Server) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	i := indexOf(repo.webhooks, r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Webhook %s does not exist.", r.PathValue("id"))
+		return
+	}
+	repo.webhooks = append(repo.webhooks[:i], repo.webhooks[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getRequiredBuilds(w http.ResponseWriter, r *http.Request) {
+	if repo, ok := s.repository(w, r); ok {
+		writePage(w, r, repo.requiredBuilds)
+	}
+}
+
+func (s *Server) createRequiredBuild(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var body object
+	if !decode(w, r, &body) {
+		return
+	}
+	if _, ok := body["refMatcher"].(object); !ok {
+		writeError(w, http.StatusBadRequest, "A ref matcher is required.")
+		return
+	}
+	cond := object{"id": s.id(), "buildParentKeys": []any{}}
+	merge(cond, body, "buildParentKeys", "refMatcher", "exemptRefMatcher")
+	completeMatchers(cond)
+	repo.requiredBuilds = append(repo.requiredBuilds, cond)
+	writeJSON(w, http.StatusOK, cond)
+}
+
+func (s *Server) updateRequiredBuild(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	i := indexOf(repo.requiredBuilds, r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Required build %s does not exist.", r.PathValue("id"))
+		return
+	}
+	var body object
+	if !decode(w, r, &body) {
+		return
+	}
+	merge(repo.requiredBuilds[i], body, "buildParentKeys", "refMatcher", "exemptRefMatcher")
+	completeMatchers(repo.requiredBuilds[i])
+	writeJSON(w, http.StatusOK, repo.requiredBuilds[i])
+}
+
+func (s *Server) deleteRequiredBuild(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	i := indexOf(repo.requiredBuilds, r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Required build %s does not exist.", r.PathValue("id"))
+		return
+	}
+	repo.requiredBuilds = append(repo.requiredBuilds[:i], repo.requiredBuilds[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getRestrictions(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	values := []object{}
+	for _, rr := range repo.restrictions {
+		m, _ := rr["matcher"].(object)
+		mt, _ := m["type"].(object)
+		if (q.Get("type") == "" || q.Get("type") == str(rr["type"])) &&
+			(q.Get("matcherId") == "" || q.Get("matcherId") == str(m["id"])) &&
+			(q.Get("matcherType") == "" || strings.EqualFold(q.Get("matcherType"), str(mt["id"]))) {
+			values = append(values, rr)
+		}
+	}
+	writePage(w, r, values)
+}
+
+func (s *Server) getRestriction(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	i := indexOf(repo.restrictions, r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Restriction %s does not exist.", r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, repo.restrictions[i])
+}
+
+// createRestrictions creates restrictions in bulk. Like Bitbucket, a restriction of
+// the same type and matcher as an existing one replaces it.
+func (s *Server) createRestrictions(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var body []object
+	if !decode(w, r, &body) {
+		return
+	}
+
+	var created []object
+	for _, in := range body {
+		m, _ := in["matcher"].(object)
+		if str(in["type"]) == "" || str(m["id"]) == "" {
+			writeError(w, http.StatusBadRequest, "Restriction type and matcher are required.")
+			return
+		}
+		completeMatcher(m)
+		users := []object{}
+		for _, u := range stringList(in["users"]) {
+			user, ok := s.users[strings.ToLower(u)]
+			if !ok {
+				writeError(w, http.StatusBadRequest, "User %s does not exist.", u)
+				return
+			}
+			users = append(users, user)
+		}
+		groups := []any{}
+		for _, g := range stringList(in["groups"]) {
+			if _, ok := s.groups[strings.ToLower(g)]; !ok {
+				writeError(w, http.StatusBadRequest, "Group %s does not exist.", g)
+				return
+			}
+			groups = append(groups, g)
+		}
+		rr := object{
+			"type": in["type"], "matcher": m, "users": users, "groups": groups, "accessKeys": []any{},
+			"scope": object{"type": "REPOSITORY", "resourceId": repo.data["id"]},
+		}
+
+		replaced := false
+		for i, old := range repo.restrictions {
+			om, _ := old["matcher"].(object)
+			if str(old["type"]) == str(rr["type"]) && str(om["id"]) == str(m["id"]) {
+				rr["id"] = old["id"]
+				repo.restrictions[i] = rr
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			rr["id"] = s.id()
+			repo.restrictions = append(repo.restrictions, rr)
+		}
+		created = append(created, rr)
+	}
+	writeJSON(w, http.StatusOK, created)
+}
+
+func (s *Server) deleteRestriction(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	i := indexOf(repo.restrictions, r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Restriction %s does not exist.", r.PathValue("id"))
+		return
+	}
+	repo.restrictions = append(repo.restrictions[:i], repo.restrictions[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getReviewerGroups(w http.ResponseWriter, r *http.Request) {
+	if repo, ok := s.repository(w, r); ok {
+		writePage(w, r, repo.reviewerGroups)
+	}
+}
+
+func (s *Server) getReviewerGroup(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	i := indexOf(repo.reviewerGroups, r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Reviewer group %s does not exist.", r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, repo.reviewerGroups[i])
+}
+
+// reviewerGroupUsers resolves the users of a reviewer group body by id or name
+func (s *Server) reviewerGroupUsers(w http.ResponseWriter, body object) ([]object, bool) {
+	users := []object{}
+	list, _ := body["users"].([]any)
+	for _, v := range list {
+		in, _ := v.(object)
+		var found object
+		for _, u := range s.users {
+			if (in["id"] != nil && sameID(u, in["id"])) || strings.EqualFold(str(u["name"]), str(in["name"])) {
+				found = u
+				break
+			}
+		}
+		if found == nil {
+			writeError(w, http.StatusBadRequest, "User %v does not exist.", in["name"])
+			return nil, false
+		}
+		users = append(users, found)
+	}
+	return users, true
+}
+
+func (s *Server) createReviewerGroup(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var body object
+	if !decode(w, r, &body) {
+		return
+	}
+	name := str(body["name"])
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "Reviewer group name is required.")
+		return
+	}
+	for _, g := range repo.reviewerGroups {
+		if strings.EqualFold(str(g["name"]), name) {
+			writeError(w, http.StatusConflict, "Reviewer group %s already exists.", name)
+			return
+		}
+	}
+	users, ok := s.reviewerGroupUsers(w, body)
+	if !ok {
+		return
+	}
+	rg := object{
+		"id": s.id(), "name": name, "users": users,
+		"scope": object{"type": "REPOSITORY", "resourceId": repo.data["id"]},
+	}
+	merge(rg, body, "description")
+	repo.reviewerGroups = append(repo.reviewerGroups, rg)
+	writeJSON(w, http.StatusCreated, rg)
+}
+
+func (s *Server) updateReviewerGroup(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	i := indexOf(repo.reviewerGroups, r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Reviewer group %s does not exist.", r.PathValue("id"))
+		return
+	}
+	var body object
+	if !decode(w, r, &body) {
+		return
+	}
+	if _, ok := body["users"]; ok {
+		users, ok := s.reviewerGroupUsers(w, body)
+		if !ok {
+			return
+		}
+		repo.reviewerGroups[i]["users"] = users
+	}
+	merge(repo.reviewerGroups[i], body, "name", "description")
+	writeJSON(w, http.StatusOK, repo.reviewerGroups[i])
+}
+
+func (s *Server) deleteReviewerGroup(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	i := indexOf(repo.reviewerGroups, r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Reviewer group %s does not exist.", r.PathValue("id"))
+		return
+	}
+	repo.reviewerGroups = append(repo.reviewerGroups[:i], repo.reviewerGroups[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// stringList returns the strings of a decoded JSON array
+func stringList(v any) []string {
+	list, _ := v.([]any)
+	var out []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// completeMatchers completes the ref matchers of a required build condition
+func completeMatchers(cond object) {
+	for _, k := range []string{"refMatcher", "exemptRefMatcher"} {
+		if m, ok := cond[k].(object); ok {
+			completeMatcher(m)
+		}
+	}
+}
+
+// completeMatcher adds the display id and type name Bitbucket returns for a ref matcher
+func completeMatcher(m object) {
+	if str(m["displayId"]) == "" {
+		m["displayId"] = strings.TrimPrefix(strings.TrimPrefix(str(m["id"]), "refs/heads/"), "refs/tags/")
+	}
+	if t, ok := m["type"].(object); ok && str(t["name"]) == "" {
+		names := map[string]string{"BRANCH": "Branch", "PATTERN": "Pattern", "MODEL_BRANCH": "Branching model branch", "MODEL_CATEGORY": "Branching model category", "ANY_REF": "Any branch"}
+		if name, ok := names[str(t["id"])]; ok {
+			t["name"] = name
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package fakebitbucket
+
+import (
+	"net/http"
+	"strings"
+)
+
+// AddUser stores a user, e.g. the account a client authenticates as
+func (s *Server) AddUser(name, displayName, email string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addUser(name, displayName, email)
+}
+
+func (s *Server) addUser(name, displayName, email string) object {
+	if displayName == "" {
+		displayName = name
+	}
+	u := object{
+		"id": s.id(), "name": name, "slug": strings.ToLower(name), "displayName": displayName,
+		"emailAddress": email, "active": true, "type": "NORMAL",
+		"links": object{"self": []object{{"href": "/users/" + strings.ToLower(name)}}},
+	}
+	s.users[strings.ToLower(name)] = u
+	return u
+}
+
+// allPermissions returns the user and group permission maps of every project and repository
+func (s *Server) allPermissions() []map[string]string {
+	var out []map[string]string
+	for _, p := range s.projects {
+		out = append(out, p.userPerms, p.groupPerms)
+		for _, repo := range p.repos {
+			out = append(out, repo.userPerms, repo.groupPerms)
+		}
+	}
+	return out
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	u, ok := s.users[strings.ToLower(r.PathValue("userSlug"))]
+	if !ok {
+		writeError(w, http.StatusNotFound, "User %s does not exist.", r.PathValue("userSlug"))
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (s *Server) getUsers(w http.ResponseWriter, r *http.Request) {
+	var all []object
+	for _, u := range s.users {
+		all = append(all, u)
+	}
+	writePage(w, r, filterSorted(all, "name", r.URL.Query().Get("filter")))
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	name := q.Get("name")
+	if name == "" || q.Get("emailAddress") == "" {
+		writeError(w, http.StatusBadRequest, "User name and email address are required.")
+		return
+	}
+	if _, ok := s.users[strings.ToLower(name)]; ok {
+		writeError(w, http.StatusConflict, "User %s already exists.", name)
+		return
+	}
+	s.addUser(name, q.Get("displayName"), q.Get("emailAddress"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request) {
+	var body object
+	if !decode(w, r, &body) {
+		return
+	}
+	u, ok := s.users[strings.ToLower(str(body["name"]))]
+	if !ok {
+		writeError(w, http.StatusNotFound, "User %s does not exist.", body["name"])
+		return
+	}
+	merge(u, body, "displayName")
+	if email, ok := body["email"]; ok {
+		u["emailAddress"] = email
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
+	name := strings.ToLower(r.URL.Query().Get("name"))
+	u, ok := s.users[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "User %s does not exist.", r.URL.Query().Get("name"))
+		return
+	}
+	delete(s.users, name)
+	for _, g := range s.groups {
+		delete(g.members, name)
+	}
+	for _, perms := range s.allPermissions() {
+		delete(perms, name)
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (s *Server) getGroups(w http.ResponseWriter, r *http.Request) {
+	var all []object
+	for _, g := range s.groups {
+		all = append(all, object{"name": g.name, "deletable": true})
+	}
+	writePage(w, r, filterSorted(all, "name", r.URL.Query().Get("filter")))
+}
+
+func (s *Server) createGroup(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "Group name is required.")
+		return
+	}
+	if _, ok := s.groups[strings.ToLower(name)]; ok {
+		writeError(w, http.StatusConflict, "Group %s already exists.", name)
+		return
+	}
+	s.groups[strings.ToLower(name)] = &group{name: name, members: map[string]bool{}}
+	writeJSON(w, http.StatusOK, object{"name": name, "deletable": true})
+}
+
+func (s *Server) deleteGroup(w http.ResponseWriter, r *http.Request) {
+	name := strings.ToLower(r.URL.Query().Get("name"))
+	g, ok := s.groups[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Group %s does not exist.", r.URL.Query().Get("name"))
+		return
+	}
+	delete(s.groups, name)
+	for _, perms := range s.allPermissions() {
+		delete(perms, name)
+	}
+	writeJSON(w, http.StatusOK, object{"name": g.name, "deletable": true})
+}
+
+func (s *Server) addUsersToGroup(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Group string   `json:"group"`
+		Users []string `json:"users"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	g, ok := s.groups[strings.ToLower(body.Group)]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Group %s does not exist.", body.Group)
+		return
+	}
+	for _, u := range body.Users {
+		if _, ok := s.users[strings.ToLower(u)]; !ok {
+			writeError(w, http.StatusNotFound, "User %s does not exist.", u)
+			return
+		}
+	}
+	for _, u := range body.Users {
+		g.members[strings.ToLower(u)] = true
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) removeUserFromGroup(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Context  string `json:"context"`
+		ItemName string `json:"itemName"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	g, ok := s.groups[strings.ToLower(body.Context)]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Group %s does not exist.", body.Context)
+		return
+	}
+	delete(g.members, strings.ToLower(body.ItemName))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getGroupMembers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	g, ok := s.groups[strings.ToLower(q.Get("context"))]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Group %s does not exist.", q.Get("context"))
+		return
+	}
+	var members []object
+	for name := range g.members {
+		members = append(members, s.users[name])
+	}
+	writePage(w, r, filterSorted(members, "name", q.Get("filter")))
+}
@@ -0,0 +1,74 @@
+package fakebitbucket
+
+import "net/http"
+
+// Workzone sections are stored as sent: the workflow properties as an object,
+// the branch lists as arrays
+
+func (s *Server) getWorkzone(section string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		repo, ok := s.repository(w, r)
+		if !ok {
+			return
+		}
+		v, ok := repo.workzone[section]
+		if !ok {
+			if section == "workflow" {
+				v = object{}
+			} else {
+				v = []any{}
+			}
+		}
+		writeJSON(w, http.StatusOK, v)
+	}
+}
+
+func (s *Server) setWorkzone(section string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		repo, ok := s.repository(w, r)
+		if !ok {
+			return
+		}
+		var body any
+		if !decode(w, r, &body) {
+			return
+		}
+		repo.workzone[section] = body
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// updateWorkzone merges the fields of the body into the workflow properties;
+// the branch lists have no update endpoint and are replaced
+func (s *Server) updateWorkzone(section string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		repo, ok := s.repository(w, r)
+		if !ok {
+			return
+		}
+		var body any
+		if !decode(w, r, &body) {
+			return
+		}
+		current, isObject := repo.workzone[section].(object)
+		update, bodyIsObject := body.(object)
+		if !isObject || !bodyIsObject {
+			repo.workzone[section] = body
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		for k, v := range update {
+			current[k] = v
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (s *Server) deleteWorkzone(section string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if repo, ok := s.repository(w, r); ok {
+			delete(repo.workzone, section)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
@@ -26,6 +26,7 @@ type ExtendedRepository struct {
 	RequiredBuilds     *[]openapi.RestRequiredBuildCondition `json:"requiredBuilds,omitempty" yaml:"requiredBuilds,omitempty"`
 	ReviewerGroups     *[]openapi.RestReviewerGroup          `json:"reviewerGroups,omitempty" yaml:"reviewerGroups,omitempty"`
 	Workzone           *WorkzoneData                         `json:"workzone,omitempty" yaml:"workzone,omitempty"`
+	Permissions        *Permissions                          `json:"permissions,omitempty" yaml:"permissions,omitempty"`
 }
 
 // WorkzoneData groups Workzone-related sections for a repository
@@ -56,6 +57,45 @@ type ProjectYaml struct {
 	Projects []openapi.RestProject `json:"projects,omitempty" yaml:"projects,omitempty"`
 }
 
+// ExtendedProject is a project key with project-scoped settings.
+// Its key matches the `key` of RestProject documents, so `project get` output can be reused.
+type ExtendedProject struct {
+	Key         string       `json:"key" yaml:"key"`
+	Permissions *Permissions `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+}
+
+type ProjectPermissionYaml struct {
+	Projects []ExtendedProject `json:"projects,omitempty" yaml:"projects,omitempty"`
+}
+
+// PermissionEntry grants a permission (e.g. PROJECT_WRITE, REPO_READ) to a user or group
+type PermissionEntry struct {
+	Name       string `json:"name" yaml:"name"`
+	Permission string `json:"permission,omitempty" yaml:"permission,omitempty"`
+}
+
+// Permissions lists explicit user and group permissions of a project or repository
+type Permissions struct {
+	Users  []PermissionEntry `json:"users,omitempty" yaml:"users,omitempty"`
+	Groups []PermissionEntry `json:"groups,omitempty" yaml:"groups,omitempty"`
+}
+
+// PermissionChange is a single difference between current and desired permissions.
+// From is empty for grants, To is empty for revokes.
+type PermissionChange struct {
+	Type string `json:"type" yaml:"type"` // user or group
+	Name string `json:"name" yaml:"name"`
+	From string `json:"from,omitempty" yaml:"from,omitempty"`
+	To   string `json:"to,omitempty" yaml:"to,omitempty"`
+}
+
+// PermissionDrift lists permission changes of a project (RepositorySlug empty) or repository
+type PermissionDrift struct {
+	ProjectKey     string             `json:"projectKey" yaml:"projectKey"`
+	RepositorySlug string             `json:"repositorySlug,omitempty" yaml:"repositorySlug,omitempty"`
+	Changes        []PermissionChange `json:"changes" yaml:"changes"`
+}
+
 // User represents a user for YAML parsing (with string fields)
 type User struct {
 	Name         string `json:"name" yaml:"name"`
@@ -67,9 +107,11 @@ type UserYaml struct {
 	Users []User `json:"users,omitempty" yaml:"users,omitempty"`
 }
 
-// Group represents a group for YAML parsing (with string fields)
+// Group represents a group for YAML parsing (with string fields).
+// Members holds usernames and is only used by membership commands.
 type Group struct {
-	Name string `json:"name" yaml:"name"`
+	Name    string   `json:"name" yaml:"name"`
+	Members []string `json:"members,omitempty" yaml:"members,omitempty"`
 }
 
 type GroupYaml struct {
@@ -97,11 +139,14 @@ type RepoDiff struct {
 // RepositoryYamlInput is the input format for create/update branch permissions
 // Uses RestRefRestrictionCreate which accepts users as strings
 type RepositoryYamlInput struct {
-	Repositories []struct {
-		ProjectKey        string                        `json:"projectKey" yaml:"projectKey"`
-		RepositorySlug    string                        `json:"repositorySlug" yaml:"repositorySlug"`
-		BranchPermissions *[]openapi.RestRefRestrictionCreate `json:"branchPermissions,omitempty" yaml:"branchPermissions,omitempty"`
-	} `json:"repositories" yaml:"repositories"`
+	Repositories []RepositoryInput `json:"repositories" yaml:"repositories"`
+}
+
+// RepositoryInput is a repository entry of RepositoryYamlInput
+type RepositoryInput struct {
+	ProjectKey        string                              `json:"projectKey" yaml:"projectKey"`
+	RepositorySlug    string                              `json:"repositorySlug" yaml:"repositorySlug"`
+	BranchPermissions *[]openapi.RestRefRestrictionCreate `json:"branchPermissions,omitempty" yaml:"branchPermissions,omitempty"`
 }
 
 // ToRepositoryYaml converts input to internal format for API operations
@@ -140,3 +185,105 @@ func (r *RepositoryYamlInput) ToRepositoryYaml() RepositoryYaml {
 	}
 	return result
 }
+
+// StateYaml is the desired-state document consumed by `bbctl apply`.
+// Only the resource kinds present in the file are managed: a repository
+// without a `webhooks` key keeps its webhooks untouched, while `webhooks: []`
+// removes all of them.
+type StateYaml struct {
+	Projects     []openapi.RestProject `json:"projects,omitempty" yaml:"projects,omitempty"`
+	Repositories []ExtendedRepository  `json:"repositories,omitempty" yaml:"repositories,omitempty"`
+	Users        []User                `json:"users,omitempty" yaml:"users,omitempty"`
+	Groups       []Group               `json:"groups,omitempty" yaml:"groups,omitempty"`
+}
+
+// ProjectDiff is a diff container for projects keyed by project key.
+type ProjectDiff struct {
+	Create []openapi.RestProject `json:"create" yaml:"create"`
+	Update []openapi.RestProject `json:"update" yaml:"update"`
+	Delete []openapi.RestProject `json:"delete" yaml:"delete"`
+}
+
+// UserDiff is a diff container for users keyed by user name.
+type UserDiff struct {
+	Create []User `json:"create" yaml:"create"`
+	Update []User `json:"update" yaml:"update"`
+	Delete []User `json:"delete" yaml:"delete"`
+}
+
+// GroupDiff is a diff container for groups keyed by group name.
+type GroupDiff struct {
+	Create []Group `json:"create" yaml:"create"`
+	Delete []Group `json:"delete" yaml:"delete"`
+}
+
+// StatePlan is the set of changes required to move live state to a StateYaml.
+// Sections are listed in the order they are applied.
+type StatePlan struct {
+	Users             UserDiff    `json:"users" yaml:"users"`
+	Groups            GroupDiff   `json:"groups" yaml:"groups"`
+	Projects          ProjectDiff `json:"projects" yaml:"projects"`
+	Repositories      RepoDiff    `json:"repositories" yaml:"repositories"`
+	Webhooks          RepoDiff    `json:"webhooks" yaml:"webhooks"`
+	RequiredBuilds    RepoDiff    `json:"requiredBuilds" yaml:"requiredBuilds"`
+	BranchPermissions RepoDiff    `json:"branchPermissions" yaml:"branchPermissions"`
+	ReviewerGroups    RepoDiff    `json:"reviewerGroups" yaml:"reviewerGroups"`
+	// Workzone: update holds sections to set (desired values),
+	// delete holds sections to remove (live values).
+	Workzone RepoDiff `json:"workzone" yaml:"workzone"`
+}
+
+// PlanFile is a saved plan produced by `bbctl plan` and executed by `bbctl apply --plan`.
+// Fingerprint identifies the live state the plan was computed against; Checksum
+// protects the file content (everything except the checksum itself).
+type PlanFile struct {
+	Version     int       `json:"version" yaml:"version"`
+	CreatedAt   string    `json:"createdAt" yaml:"createdAt"`
+	Fingerprint string    `json:"fingerprint" yaml:"fingerprint"`
+	Checksum    string    `json:"checksum" yaml:"checksum"`
+	Desired     StateYaml `json:"desired" yaml:"desired"`
+	Plan        StatePlan `json:"plan" yaml:"plan"`
+}
+
+// ItemResult is the outcome of a single item of a bulk operation
+type ItemResult struct {
+	Operation string `json:"operation" yaml:"operation"` // create, update, delete, ...
+	Kind      string `json:"kind" yaml:"kind"`           // repository, webhook, user, ...
+	Item      string `json:"item" yaml:"item"`
+	Status    string `json:"status" yaml:"status"` // succeeded, failed, skipped or planned
+	HTTPCode  int    `json:"httpCode,omitempty" yaml:"httpCode,omitempty"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ItemReport is the per-item report written by --report
+type ItemReport struct {
+	Succeeded int          `json:"succeeded" yaml:"succeeded"`
+	Failed    int          `json:"failed" yaml:"failed"`
+	Skipped   int          `json:"skipped" yaml:"skipped"`
+	Planned   int          `json:"planned,omitempty" yaml:"planned,omitempty"` // dry run only
+	Items     []ItemResult `json:"items" yaml:"items"`
+}
+
+// MigrationMapping renames users and groups of the source instance whose names
+// differ on the target of `bbctl migrate`, keyed by source name.
+type MigrationMapping struct {
+	Users  map[string]string `json:"users,omitempty" yaml:"users,omitempty"`
+	Groups map[string]string `json:"groups,omitempty" yaml:"groups,omitempty"`
+}
+
+// UnmappedPrincipal is a user or group referenced by a source setting that does
+// not exist on the target instance, even after mapping; it is left out of the setting.
+type UnmappedPrincipal struct {
+	Repository string `json:"repository" yaml:"repository"`
+	Setting    string `json:"setting" yaml:"setting"` // branch permission, reviewer group, workzone ...
+	Kind       string `json:"kind" yaml:"kind"`       // user or group
+	Name       string `json:"name" yaml:"name"`
+	MappedTo   string `json:"mappedTo,omitempty" yaml:"mappedTo,omitempty"`
+}
+
+// MigrationResult is the outcome of `bbctl migrate`
+type MigrationResult struct {
+	Plan     *StatePlan          `json:"plan" yaml:"plan"`
+	Unmapped []UnmappedPrincipal `json:"unmapped,omitempty" yaml:"unmapped,omitempty"`
+	Skipped  []string            `json:"skipped,omitempty" yaml:"skipped,omitempty"` // repositories missing on the target
+}
@@ -0,0 +1,179 @@
+package state
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/internal/workzone"
+	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
+)
+
+// CountChanges returns the total number of objects a plan would touch
+func CountChanges(plan *models.StatePlan) int {
+	n := len(plan.Users.Create) + len(plan.Users.Update) + len(plan.Users.Delete)
+	n += len(plan.Groups.Create) + len(plan.Groups.Delete)
+	n += len(plan.Projects.Create) + len(plan.Projects.Update) + len(plan.Projects.Delete)
+	for _, d := range []models.RepoDiff{
+		plan.Repositories, plan.Webhooks, plan.RequiredBuilds,
+		plan.BranchPermissions, plan.ReviewerGroups, plan.Workzone,
+	} {
+		n += len(d.Create) + len(d.Update) + len(d.Delete)
+	}
+	return n
+}
+
+// Apply executes a plan in dependency order: users and groups, projects,
+// repositories, then repository settings. Each settings kind is applied as
+// delete, then update, then create. Apply stops at the first failing step
+// because later steps usually depend on earlier ones.
+// userPassword is used as the initial password for every created user.
+func Apply(ctx context.Context, client *bitbucket.Client, plan *models.StatePlan, userPassword string) error {
+	// Users
+	if len(plan.Users.Create) > 0 {
+		if userPassword == "" {
+			return fmt.Errorf("--user-password is required to create %d users", len(plan.Users.Create))
+		}
+		users := ToRestUsers(plan.Users.Create)
+		passwords := make([]string, len(users))
+		for i := range passwords {
+			passwords[i] = userPassword
+		}
+		if _, err := client.CreateUsers(ctx, users, passwords); err != nil {
+			return fmt.Errorf("apply users create failed: %w", err)
+		}
+	}
+	if len(plan.Users.Update) > 0 {
+		if _, err := client.UpdateUsers(ctx, ToRestUsers(plan.Users.Update)); err != nil {
+			return fmt.Errorf("apply users update failed: %w", err)
+		}
+	}
+
+	// Groups
+	if len(plan.Groups.Create) > 0 {
+		groups := make([]openapi.RestDetailedGroup, len(plan.Groups.Create))
+		for i, g := range plan.Groups.Create {
+			groups[i] = openapi.RestDetailedGroup{Name: &g.Name}
+		}
+		if _, err := client.CreateGroups(ctx, groups); err != nil {
+			return fmt.Errorf("apply groups create failed: %w", err)
+		}
+	}
+
+	// Projects
+	if len(plan.Projects.Create) > 0 {
+		if _, err := client.CreateProjects(ctx, plan.Projects.Create); err != nil {
+			return fmt.Errorf("apply projects create failed: %w", err)
+		}
+	}
+	if len(plan.Projects.Update) > 0 {
+		if _, err := client.UpdateProjects(ctx, plan.Projects.Update); err != nil {
+			return fmt.Errorf("apply projects update failed: %w", err)
+		}
+	}
+
+	// Repositories
+	if len(plan.Repositories.Create) > 0 {
+		if _, err := client.CreateRepos(ctx, plan.Repositories.Create); err != nil {
+			return fmt.Errorf("apply repositories create failed: %w", err)
+		}
+	}
+	if len(plan.Repositories.Update) > 0 {
+		if _, err := client.UpdateRepos(ctx, plan.Repositories.Update); err != nil {
+			return fmt.Errorf("apply repositories update failed: %w", err)
+		}
+	}
+
+	// Webhooks
+	if len(plan.Webhooks.Delete) > 0 {
+		if err := client.DeleteWebhooks(ctx, plan.Webhooks.Delete); err != nil {
+			return fmt.Errorf("apply webhooks delete failed: %w", err)
+		}
+	}
+	if len(plan.Webhooks.Update) > 0 {
+		if _, err := client.UpdateWebhooks(ctx, plan.Webhooks.Update); err != nil {
+			return fmt.Errorf("apply webhooks update failed: %w", err)
+		}
+	}
+	if len(plan.Webhooks.Create) > 0 {
+		if _, err := client.CreateWebhooks(ctx, plan.Webhooks.Create); err != nil {
+			return fmt.Errorf("apply webhooks create failed: %w", err)
+		}
+	}
+
+	// Required builds
+	if len(plan.RequiredBuilds.Delete) > 0 {
+		if err := client.DeleteRequiredBuilds(ctx, plan.RequiredBuilds.Delete); err != nil {
+			return fmt.Errorf("apply required builds delete failed: %w", err)
+		}
+	}
+	if len(plan.RequiredBuilds.Update) > 0 {
+		if _, err := client.UpdateRequiredBuilds(ctx, plan.RequiredBuilds.Update); err != nil {
+			return fmt.Errorf("apply required builds update failed: %w", err)
+		}
+	}
+	if len(plan.RequiredBuilds.Create) > 0 {
+		if _, err := client.CreateRequiredBuilds(ctx, plan.RequiredBuilds.Create); err != nil {
+			return fmt.Errorf("apply required builds create failed: %w", err)
+		}
+	}
+
+	// Branch permissions
+	if len(plan.BranchPermissions.Delete) > 0 {
+		if err := client.DeleteBranchPermissions(ctx, plan.BranchPermissions.Delete); err != nil {
+			return fmt.Errorf("apply branch permissions delete failed: %w", err)
+		}
+	}
+	if len(plan.BranchPermissions.Update) > 0 {
+		if _, err := client.UpdateBranchPermissions(ctx, plan.BranchPermissions.Update); err != nil {
+			return fmt.Errorf("apply branch permissions update failed: %w", err)
+		}
+	}
+	if len(plan.BranchPermissions.Create) > 0 {
+		if _, err := client.CreateBranchPermissions(ctx, plan.BranchPermissions.Create); err != nil {
+			return fmt.Errorf("apply branch permissions create failed: %w", err)
+		}
+	}
+
+	// Reviewer groups
+	if len(plan.ReviewerGroups.Delete) > 0 {
+		if _, err := client.DeleteReviewerGroups(ctx, plan.ReviewerGroups.Delete); err != nil {
+			return fmt.Errorf("apply reviewer groups delete failed: %w", err)
+		}
+	}
+	if len(plan.ReviewerGroups.Update) > 0 {
+		if _, err := client.UpdateReviewerGroups(ctx, plan.ReviewerGroups.Update); err != nil {
+			return fmt.Errorf("apply reviewer groups update failed: %w", err)
+		}
+	}
+	if len(plan.ReviewerGroups.Create) > 0 {
+		if _, err := client.CreateReviewerGroups(ctx, plan.ReviewerGroups.Create); err != nil {
+			return fmt.Errorf("apply reviewer groups create failed: %w", err)
+		}
+	}
+
+	// Workzone
+	if len(plan.Workzone.Delete) > 0 || len(plan.Workzone.Update) > 0 {
+		if err := workzone.NewClient(client).ApplyReposDiff(ctx, plan.Workzone); err != nil {
+			return fmt.Errorf("apply %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ToRestUsers converts users parsed from files to API users, leaving empty fields unset
+func ToRestUsers(users []models.User) []openapi.RestApplicationUser {
+	out := make([]openapi.RestApplicationUser, len(users))
+	for i, u := range users {
+		out[i] = openapi.RestApplicationUser{Name: &u.Name}
+		if u.DisplayName != "" {
+			out[i].DisplayName = &u.DisplayName
+		}
+		if u.EmailAddress != "" {
+			out[i].EmailAddress = &u.EmailAddress
+		}
+	}
+	return out
+}
@@ -0,0 +1,212 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/internal/workzone"
+	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
+	"gopkg.in/yaml.v3"
+)
+
+// Backup fetches every setting of the repositories that Restore can recreate: the
+// repository with its default branch, webhooks, required builds, repository-scoped
+// branch permissions and reviewer groups, and the Workzone sections. A repository
+// that cannot be fetched is an error. Workzone errors are only logged unless
+// requireWorkzone is set, as the plugin is not installed everywhere.
+func Backup(ctx context.Context, client *bitbucket.Client, repos []models.ExtendedRepository, requireWorkzone bool) ([]models.ExtendedRepository, error) {
+	// One entry per repository, grouped by project for GetReposBySlugs
+	var projects []string
+	slugs := map[string][]string{}
+	seen := map[string]bool{}
+	for _, r := range repos {
+		if seen[repoKey(r)] {
+			continue
+		}
+		seen[repoKey(r)] = true
+		if _, ok := slugs[r.ProjectKey]; !ok {
+			projects = append(projects, r.ProjectKey)
+		}
+		slugs[r.ProjectKey] = append(slugs[r.ProjectKey], r.RepositorySlug)
+	}
+
+	var out []models.ExtendedRepository
+	for _, p := range projects {
+		fetched, err := client.GetReposBySlugs(ctx, p, slugs[p], models.RepositoryOptions{Repository: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch repositories of project %s: %w", p, err)
+		}
+		out = append(out, fetched...)
+	}
+	if len(out) < len(seen) {
+		var missing []string
+		found := map[string]bool{}
+		for _, r := range out {
+			found[repoKey(r)] = true
+		}
+		for _, r := range repos {
+			if !found[repoKey(r)] {
+				missing = append(missing, repoKey(r))
+			}
+		}
+		return nil, fmt.Errorf("failed to fetch repositories %s", strings.Join(missing, ", "))
+	}
+
+	return fetchSettings(ctx, client, out, requireWorkzone)
+}
+
+// fetchSettings adds the default branch and every repository setting kind to the
+// fetched repositories, see Backup
+func fetchSettings(ctx context.Context, client *bitbucket.Client, repos []models.ExtendedRepository, requireWorkzone bool) ([]models.ExtendedRepository, error) {
+	for i := range repos {
+		// Empty repositories have no default branch, which is not an error here
+		b, _, err := client.API().ProjectAPI.GetDefaultBranch2(client.AuthContext(ctx), repos[i].ProjectKey, repos[i].RepositorySlug).Execute()
+		if err == nil && b != nil && b.DisplayId != nil {
+			repos[i].DefaultBranch = *b.DisplayId
+		}
+	}
+
+	out, err := client.GetWebhooks(ctx, repos)
+	if err != nil {
+		return nil, err
+	}
+	if out, err = client.GetRequiredBuilds(ctx, out); err != nil {
+		return nil, err
+	}
+	if out, err = client.GetBranchPermissions(ctx, out); err != nil {
+		return nil, err
+	}
+	if out, err = client.GetReviewerGroups(ctx, out); err != nil {
+		return nil, err
+	}
+	out = OnlyRepositoryScoped(out)
+
+	withWorkzone, err := fetchWorkzone(ctx, workzone.NewClient(client), out)
+	if err != nil {
+		if requireWorkzone {
+			return nil, err
+		}
+		client.Logger.Warn("Workzone settings not fetched, the plugin may not be installed", "error", err)
+		return out, nil
+	}
+	return withWorkzone, nil
+}
+
+// BackupBeforeDelete writes a Backup of the repositories affected by a delete command
+// to a timestamped file named after the command in the backup directory. Nothing is
+// written with --no-backup or in dry-run mode. An error means nothing may be deleted.
+func BackupBeforeDelete(ctx context.Context, client *bitbucket.Client, command string, repos []models.ExtendedRepository, requireWorkzone bool) error {
+	cfg := config.GlobalCfg
+	if cfg.NoBackup || cfg.DryRun || len(repos) == 0 {
+		return nil
+	}
+
+	backup, err := Backup(ctx, client, repos, requireWorkzone)
+	if err != nil {
+		return fmt.Errorf("backup before delete failed, nothing was deleted (use --no-backup to delete without backup): %w", err)
+	}
+
+	dir := cfg.BackupDir
+	if dir == "" {
+		if dir, err = config.DefaultBackupDir(); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	path := filepath.Join(dir, command+"-"+time.Now().UTC().Format("20060102-150405.000")+".yaml")
+
+	data, err := yaml.Marshal(models.RepositoryYaml{Repositories: backup})
+	if err != nil {
+		return fmt.Errorf("failed to encode backup: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write backup %s: %w", path, err)
+	}
+	client.Logger.Info("Backup written, restore it with 'bbctl restore --from'", "file", path, "repositories", len(backup))
+	return nil
+}
+
+// RestorePlan returns the changes that recreate the repositories and settings of
+// a backup. It is an apply plan without deletions: settings added after the backup
+// are kept, and items of the backup are matched to live items by natural key since
+// the ids of deleted items are not reused.
+func RestorePlan(ctx context.Context, client *bitbucket.Client, backup []models.ExtendedRepository) (*models.StatePlan, error) {
+	desired := models.StateYaml{Repositories: make([]models.ExtendedRepository, len(backup))}
+	for i, r := range backup {
+		desired.Repositories[i] = restorable(r)
+	}
+
+	plan, _, err := BuildPlan(ctx, client, desired)
+	if err != nil {
+		return nil, err
+	}
+	withoutDeletes(plan)
+	return plan, nil
+}
+
+// withoutDeletes drops the deletions of repositories and repository settings from plan
+func withoutDeletes(plan *models.StatePlan) {
+	for _, d := range []*models.RepoDiff{
+		&plan.Repositories, &plan.Webhooks, &plan.RequiredBuilds,
+		&plan.BranchPermissions, &plan.ReviewerGroups, &plan.Workzone,
+	} {
+		d.Delete = []models.ExtendedRepository{}
+	}
+}
+
+// restorable strips a backed up repository to what can be sent again: the
+// repository fields accepted on create and the settings without their ids
+func restorable(r models.ExtendedRepository) models.ExtendedRepository {
+	out := models.ExtendedRepository{
+		ProjectKey:     r.ProjectKey,
+		RepositorySlug: r.RepositorySlug,
+		DefaultBranch:  r.DefaultBranch,
+		Workzone:       r.Workzone,
+	}
+	if rr := r.RestRepository; rr != nil {
+		out.RestRepository = &openapi.RestRepository{
+			Name:        rr.Name,
+			Description: rr.Description,
+			Forkable:    rr.Forkable,
+			Public:      rr.Public,
+		}
+	}
+	if r.Webhooks != nil {
+		items := append([]openapi.RestWebhook{}, *r.Webhooks...)
+		for i := range items {
+			items[i].Id = nil
+		}
+		out.Webhooks = &items
+	}
+	if r.RequiredBuilds != nil {
+		items := append([]openapi.RestRequiredBuildCondition{}, *r.RequiredBuilds...)
+		for i := range items {
+			items[i].Id = nil
+		}
+		out.RequiredBuilds = &items
+	}
+	if r.BranchPermissions != nil {
+		items := append([]openapi.RestRefRestriction{}, *r.BranchPermissions...)
+		for i := range items {
+			items[i].Id = nil
+		}
+		out.BranchPermissions = &items
+	}
+	if r.ReviewerGroups != nil {
+		items := append([]openapi.RestReviewerGroup{}, *r.ReviewerGroups...)
+		for i := range items {
+			items[i].Id = nil
+		}
+		out.ReviewerGroups = &items
+	}
+	return out
+}
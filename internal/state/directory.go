@@ -0,0 +1,74 @@
+package state
+
+import (
+	"strings"
+
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
+	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
+)
+
+// DiffUsers compares desired users with live users by name (case-insensitive).
+// A user is updated when a non-empty display name or email address differs.
+// Delete lists live users missing from desired.
+func DiffUsers(live []openapi.RestApplicationUser, desired []models.User) models.UserDiff {
+	diff := models.UserDiff{Create: []models.User{}, Update: []models.User{}, Delete: []models.User{}}
+
+	liveByName := make(map[string]openapi.RestApplicationUser, len(live))
+	for _, u := range live {
+		liveByName[strings.ToLower(utils.SafeValue(u.Name))] = u
+	}
+
+	declared := make(map[string]bool, len(desired))
+	for _, u := range desired {
+		declared[strings.ToLower(u.Name)] = true
+		l, ok := liveByName[strings.ToLower(u.Name)]
+		if !ok {
+			diff.Create = append(diff.Create, u)
+			continue
+		}
+		if (u.DisplayName != "" && u.DisplayName != utils.SafeValue(l.DisplayName)) ||
+			(u.EmailAddress != "" && u.EmailAddress != utils.SafeValue(l.EmailAddress)) {
+			diff.Update = append(diff.Update, u)
+		}
+	}
+
+	for _, l := range live {
+		name := utils.SafeValue(l.Name)
+		if !declared[strings.ToLower(name)] {
+			diff.Delete = append(diff.Delete, models.User{
+				Name:         name,
+				DisplayName:  utils.SafeValue(l.DisplayName),
+				EmailAddress: utils.SafeValue(l.EmailAddress),
+			})
+		}
+	}
+	return diff
+}
+
+// DiffGroups compares desired groups with live groups by name (case-insensitive).
+// Delete lists live groups missing from desired.
+func DiffGroups(live []openapi.RestDetailedGroup, desired []models.Group) models.GroupDiff {
+	diff := models.GroupDiff{Create: []models.Group{}, Delete: []models.Group{}}
+
+	liveNames := make(map[string]bool, len(live))
+	for _, g := range live {
+		liveNames[strings.ToLower(utils.SafeValue(g.Name))] = true
+	}
+
+	declared := make(map[string]bool, len(desired))
+	for _, g := range desired {
+		declared[strings.ToLower(g.Name)] = true
+		if !liveNames[strings.ToLower(g.Name)] {
+			diff.Create = append(diff.Create, g)
+		}
+	}
+
+	for _, g := range live {
+		name := utils.SafeValue(g.Name)
+		if !declared[strings.ToLower(name)] {
+			diff.Delete = append(diff.Delete, models.Group{Name: name})
+		}
+	}
+	return diff
+}
@@ -0,0 +1,178 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
+	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
+	"gopkg.in/yaml.v3"
+)
+
+// ExportSummary counts the objects written by Export
+type ExportSummary struct {
+	Projects     int `json:"projects" yaml:"projects"`
+	Repositories int `json:"repositories" yaml:"repositories"`
+	Users        int `json:"users" yaml:"users"`
+	Groups       int `json:"groups" yaml:"groups"`
+}
+
+// Export writes a snapshot of the instance to dir:
+//
+//	projects/KEY.yaml      the project, in the `projects:` input format
+//	repos/KEY/slug.yaml    the repository with all settings, as in a Backup
+//	users.yaml             all users
+//	groups.yaml            all groups with their members
+//
+// Everything is sorted and fields that change without a change of configuration
+// (webhook statistics) are left out, so snapshots of the same state are identical.
+// Files of projects and repositories that no longer exist are removed.
+func Export(ctx context.Context, client *bitbucket.Client, dir string) (ExportSummary, error) {
+	var summary ExportSummary
+	written := map[string]bool{}
+	write := func(rel string, v any) error {
+		written[filepath.Join(dir, rel)] = true
+		return writeSnapshotFile(filepath.Join(dir, rel), v)
+	}
+
+	projects, err := bitbucket.GetAllProjects(ctx, client)
+	if err != nil {
+		return summary, fmt.Errorf("failed to fetch projects: %w", err)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return utils.SafeValue(projects[i].Key) < utils.SafeValue(projects[j].Key)
+	})
+
+	for _, p := range projects {
+		key := utils.SafeValue(p.Key)
+		if err := write(filepath.Join("projects", key+".yaml"), models.ProjectYaml{Projects: []openapi.RestProject{p}}); err != nil {
+			return summary, err
+		}
+		summary.Projects++
+
+		repos, err := client.GetAllReposForProject(ctx, key, models.RepositoryOptions{Repository: true})
+		if err != nil {
+			return summary, fmt.Errorf("failed to fetch repositories of project %s: %w", key, err)
+		}
+		if len(repos) == 0 {
+			continue
+		}
+		if repos, err = fetchSettings(ctx, client, repos, false); err != nil {
+			return summary, fmt.Errorf("failed to fetch settings of project %s: %w", key, err)
+		}
+		for _, r := range utils.SortRepositoriesStable(repos) {
+			if r.Webhooks != nil {
+				hooks := make([]openapi.RestWebhook, len(*r.Webhooks))
+				for i, h := range *r.Webhooks {
+					h.Statistics = nil
+					hooks[i] = h
+				}
+				r.Webhooks = &hooks
+			}
+			rel := filepath.Join("repos", key, r.RepositorySlug+".yaml")
+			if err := write(rel, models.RepositoryYaml{Repositories: []models.ExtendedRepository{r}}); err != nil {
+				return summary, err
+			}
+			summary.Repositories++
+		}
+	}
+
+	liveUsers, err := client.GetAllUsers(ctx)
+	if err != nil {
+		return summary, fmt.Errorf("failed to fetch users: %w", err)
+	}
+	users := make([]models.User, 0, len(liveUsers))
+	for _, u := range liveUsers {
+		users = append(users, models.User{
+			Name:         utils.SafeValue(u.Name),
+			DisplayName:  utils.SafeValue(u.DisplayName),
+			EmailAddress: utils.SafeValue(u.EmailAddress),
+		})
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	if err := write("users.yaml", models.UserYaml{Users: users}); err != nil {
+		return summary, err
+	}
+	summary.Users = len(users)
+
+	liveGroups, err := client.GetAllGroups(ctx)
+	if err != nil {
+		return summary, fmt.Errorf("failed to fetch groups: %w", err)
+	}
+	names := make([]string, 0, len(liveGroups))
+	for _, g := range liveGroups {
+		names = append(names, utils.SafeValue(g.Name))
+	}
+	sort.Strings(names)
+	groups, err := client.GetGroupMembers(ctx, names)
+	if err != nil {
+		return summary, fmt.Errorf("failed to fetch group members: %w", err)
+	}
+	for i := range groups {
+		sort.Strings(groups[i].Members)
+	}
+	if err := write("groups.yaml", models.GroupYaml{Groups: groups}); err != nil {
+		return summary, err
+	}
+	summary.Groups = len(groups)
+
+	return summary, pruneSnapshot(dir, written)
+}
+
+// writeSnapshotFile writes v as YAML, creating the parent directories
+func writeSnapshotFile(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// pruneSnapshot removes YAML files under projects/ and repos/ that were not written
+// by this export, and repository directories left empty
+func pruneSnapshot(dir string, written map[string]bool) error {
+	for _, sub := range []string{"projects", "repos"} {
+		root := filepath.Join(dir, sub)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".yaml") || written[path] {
+				return nil
+			}
+			return os.Remove(path)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to remove stale files in %s: %w", root, err)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "repos"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		// Remove fails for directories that are not empty, which are kept
+		_ = os.Remove(filepath.Join(dir, "repos", e.Name()))
+	}
+	return nil
+}
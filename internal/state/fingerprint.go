@@ -0,0 +1,86 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
+	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
+)
+
+// liveSnapshot collects the live objects a plan was computed against.
+// Only objects matched by the desired state are recorded, so unrelated
+// changes elsewhere on the server do not invalidate a saved plan.
+type liveSnapshot struct {
+	Users        []openapi.RestApplicationUser `json:"users"`
+	Groups       []string                      `json:"groups"`
+	Projects     []openapi.RestProject         `json:"projects"`
+	Repositories []models.ExtendedRepository   `json:"repositories"`
+	Settings     []models.ExtendedRepository   `json:"settings"`
+}
+
+// fingerprint returns a sha256 over a canonical JSON encoding of the snapshot
+func (s *liveSnapshot) fingerprint() (string, error) {
+	sort.Slice(s.Users, func(i, j int) bool {
+		return utils.SafeValue(s.Users[i].Name) < utils.SafeValue(s.Users[j].Name)
+	})
+	sort.Strings(s.Groups)
+	sort.Slice(s.Projects, func(i, j int) bool {
+		return utils.SafeValue(s.Projects[i].Key) < utils.SafeValue(s.Projects[j].Key)
+	})
+	sortByRepoKey(s.Repositories)
+
+	// Settings are grouped per repository so that the same repo fetched for
+	// several kinds is merged into one entry.
+	merged := map[string]models.ExtendedRepository{}
+	for _, r := range s.Settings {
+		m := merged[repoKey(r)]
+		m.ProjectKey, m.RepositorySlug = r.ProjectKey, r.RepositorySlug
+		if r.Webhooks != nil {
+			// delivery statistics change on every event and are not part of the state
+			hooks := make([]openapi.RestWebhook, len(*r.Webhooks))
+			for i, h := range *r.Webhooks {
+				h.Statistics = nil
+				hooks[i] = h
+			}
+			m.Webhooks = &hooks
+		}
+		if r.RequiredBuilds != nil {
+			m.RequiredBuilds = r.RequiredBuilds
+		}
+		if r.BranchPermissions != nil {
+			m.BranchPermissions = r.BranchPermissions
+		}
+		if r.ReviewerGroups != nil {
+			m.ReviewerGroups = r.ReviewerGroups
+		}
+		if r.Workzone != nil {
+			m.Workzone = r.Workzone
+		}
+		merged[repoKey(r)] = m
+	}
+	settings := make([]models.ExtendedRepository, 0, len(merged))
+	for _, r := range merged {
+		settings = append(settings, r)
+	}
+	sortByRepoKey(settings)
+	s.Settings = settings
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// sortByRepoKey orders repositories by projectKey/repositorySlug. Items inside each
+// repository keep the order returned by the server, which is stable between calls.
+func sortByRepoKey(repos []models.ExtendedRepository) {
+	sort.Slice(repos, func(i, j int) bool {
+		return repoKey(repos[i]) < repoKey(repos[j])
+	})
+}
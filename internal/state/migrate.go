@@ -0,0 +1,245 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
+	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
+	workzone "github.com/vinisman/workzone-sdk-go/client"
+)
+
+// MigrationSource fetches the repositories of the projects with the settings that
+// Migrate carries over, see Backup. Workzone settings are left out when the plugin
+// is not installed on the source instance.
+func MigrationSource(ctx context.Context, client *bitbucket.Client, projects []string) ([]models.ExtendedRepository, error) {
+	var repos []models.ExtendedRepository
+	for _, p := range projects {
+		fetched, err := client.GetAllReposForProject(ctx, p, models.RepositoryOptions{Repository: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch repositories of project %s: %w", p, err)
+		}
+		repos = append(repos, fetched...)
+	}
+	if len(repos) == 0 {
+		return nil, nil
+	}
+	return fetchSettings(ctx, client, repos, false)
+}
+
+// MigratePlan returns the changes that recreate the settings of the source
+// repositories on the target instance. Repositories are not created: those missing
+// on the target are skipped. Ids are stripped and items are matched to target items
+// by natural key like RestorePlan, nothing is deleted. Users and groups are renamed
+// with the mapping; the ones that do not exist on the target are left out of the
+// settings and listed as unmapped.
+func MigratePlan(ctx context.Context, target *bitbucket.Client, source []models.ExtendedRepository, mapping models.MigrationMapping) (*models.MigrationResult, error) {
+	result := &models.MigrationResult{}
+
+	onTarget := map[string]bool{}
+	fetched := map[string]bool{}
+	for _, r := range source {
+		k := strings.ToUpper(r.ProjectKey)
+		if fetched[k] {
+			continue
+		}
+		fetched[k] = true
+		repos, err := target.GetAllReposForProject(ctx, r.ProjectKey, models.RepositoryOptions{Repository: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch repositories of project %s on the target: %w", r.ProjectKey, err)
+		}
+		for _, tr := range repos {
+			onTarget[strings.ToUpper(tr.ProjectKey)+"/"+tr.RepositorySlug] = true
+		}
+	}
+
+	users, err := target.GetAllUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch users of the target: %w", err)
+	}
+	groups, err := target.GetAllGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch groups of the target: %w", err)
+	}
+	m := newPrincipalMapper(mapping)
+	for _, u := range users {
+		m.users[strings.ToLower(utils.SafeValue(u.Name))] = true
+	}
+	for _, g := range groups {
+		m.groups[strings.ToLower(utils.SafeValue(g.Name))] = true
+	}
+
+	desired := models.StateYaml{Repositories: []models.ExtendedRepository{}}
+	for _, r := range source {
+		if !onTarget[strings.ToUpper(r.ProjectKey)+"/"+r.RepositorySlug] {
+			target.Logger.Warn("Repository not found on the target, skipped", "project", r.ProjectKey, "slug", r.RepositorySlug)
+			result.Skipped = append(result.Skipped, repoKey(r))
+			continue
+		}
+		out := restorable(r)
+		// The repository itself is migrated with its content, only settings are set here
+		out.RestRepository = nil
+		out.DefaultBranch = ""
+		m.remap(&out)
+		desired.Repositories = append(desired.Repositories, out)
+	}
+	result.Unmapped = m.unmapped
+
+	if len(desired.Repositories) == 0 {
+		result.Plan = newPlan()
+		return result, nil
+	}
+	plan, _, err := BuildPlan(ctx, target, desired)
+	if err != nil {
+		return nil, err
+	}
+	withoutDeletes(plan)
+	result.Plan = plan
+	return result, nil
+}
+
+// principalMapper renames users and groups and records those missing on the target
+type principalMapper struct {
+	mapping  models.MigrationMapping
+	users    map[string]bool
+	groups   map[string]bool
+	unmapped []models.UnmappedPrincipal
+}
+
+func newPrincipalMapper(mapping models.MigrationMapping) *principalMapper {
+	m := &principalMapper{
+		mapping: models.MigrationMapping{Users: map[string]string{}, Groups: map[string]string{}},
+		users:   map[string]bool{},
+		groups:  map[string]bool{},
+	}
+	// Bitbucket user and group names are case-insensitive
+	for k, v := range mapping.Users {
+		m.mapping.Users[strings.ToLower(k)] = v
+	}
+	for k, v := range mapping.Groups {
+		m.mapping.Groups[strings.ToLower(k)] = v
+	}
+	return m
+}
+
+// name returns the target name of a user or group, false when it does not exist there
+func (m *principalMapper) name(repo, setting, kind, name string) (string, bool) {
+	mapping, known := m.mapping.Users, m.users
+	if kind == "group" {
+		mapping, known = m.mapping.Groups, m.groups
+	}
+	mapped := name
+	if v, ok := mapping[strings.ToLower(name)]; ok {
+		mapped = v
+	}
+	if known[strings.ToLower(mapped)] {
+		return mapped, true
+	}
+	u := models.UnmappedPrincipal{Repository: repo, Setting: setting, Kind: kind, Name: name}
+	if mapped != name {
+		u.MappedTo = mapped
+	}
+	m.unmapped = append(m.unmapped, u)
+	return "", false
+}
+
+func (m *principalMapper) groupNames(repo, setting string, names []string) []string {
+	var out []string
+	for _, n := range names {
+		if mapped, ok := m.name(repo, setting, "group", n); ok {
+			out = append(out, mapped)
+		}
+	}
+	return out
+}
+
+func (m *principalMapper) workzoneUsers(repo, setting string, users []workzone.RestApplicationUser) []workzone.RestApplicationUser {
+	var out []workzone.RestApplicationUser
+	for _, u := range users {
+		if mapped, ok := m.name(repo, setting, "user", utils.SafeValue(u.Name)); ok {
+			out = append(out, workzone.RestApplicationUser{Name: &mapped})
+		}
+	}
+	return out
+}
+
+// remap renames the users and groups of every setting of r in place. Users are
+// reduced to their name, as the ids of the source are not valid on the target.
+func (m *principalMapper) remap(r *models.ExtendedRepository) {
+	repo := repoKey(*r)
+
+	if r.BranchPermissions != nil {
+		for i, p := range *r.BranchPermissions {
+			setting := "branch permission " + utils.SafeValue(p.Type)
+			if p.Matcher != nil {
+				setting += " " + utils.SafeValue(p.Matcher.DisplayId)
+			}
+			var users []openapi.RestApplicationUser
+			for _, u := range p.Users {
+				if mapped, ok := m.name(repo, setting, "user", utils.SafeValue(u.Name)); ok {
+					users = append(users, openapi.RestApplicationUser{Name: &mapped})
+				}
+			}
+			p.Users = users
+			p.Groups = m.groupNames(repo, setting, p.Groups)
+			// Access keys are SSH keys of the source instance
+			p.AccessKeys = nil
+			(*r.BranchPermissions)[i] = p
+		}
+	}
+
+	if r.ReviewerGroups != nil {
+		for i, g := range *r.ReviewerGroups {
+			setting := "reviewer group " + utils.SafeValue(g.Name)
+			var users []openapi.ApplicationUser
+			for _, u := range g.Users {
+				if mapped, ok := m.name(repo, setting, "user", utils.SafeValue(u.Name)); ok {
+					users = append(users, openapi.ApplicationUser{Name: &mapped})
+				}
+			}
+			g.Users = users
+			(*r.ReviewerGroups)[i] = g
+		}
+	}
+
+	if r.Workzone == nil {
+		return
+	}
+	wz := *r.Workzone
+	wz.Reviewers = slices.Clone(wz.Reviewers)
+	for i, rv := range wz.Reviewers {
+		setting := "workzone reviewers " + utils.SafeValue(rv.RefName)
+		rv.Users = m.workzoneUsers(repo, setting, rv.Users)
+		rv.Groups = m.groupNames(repo, setting, rv.Groups)
+		rv.MandatoryUsers = m.workzoneUsers(repo, setting, rv.MandatoryUsers)
+		rv.MandatoryGroups = m.groupNames(repo, setting, rv.MandatoryGroups)
+		rv.FilePathReviewers = slices.Clone(rv.FilePathReviewers)
+		for j, fp := range rv.FilePathReviewers {
+			fpSetting := setting + " " + utils.SafeValue(fp.FilePathPattern)
+			fp.Users = m.workzoneUsers(repo, fpSetting, fp.Users)
+			fp.Groups = m.groupNames(repo, fpSetting, fp.Groups)
+			fp.MandatoryUsers = m.workzoneUsers(repo, fpSetting, fp.MandatoryUsers)
+			fp.MandatoryGroups = m.groupNames(repo, fpSetting, fp.MandatoryGroups)
+			rv.FilePathReviewers[j] = fp
+		}
+		wz.Reviewers[i] = rv
+	}
+	wz.Signapprovers = slices.Clone(wz.Signapprovers)
+	for i, sa := range wz.Signapprovers {
+		setting := "workzone signapprovers " + utils.SafeValue(sa.RefName)
+		sa.Users = m.workzoneUsers(repo, setting, sa.Users)
+		sa.Groups = m.groupNames(repo, setting, sa.Groups)
+		wz.Signapprovers[i] = sa
+	}
+	wz.Mergerules = slices.Clone(wz.Mergerules)
+	for i, mr := range wz.Mergerules {
+		setting := "workzone mergerules " + utils.SafeValue(mr.RefName)
+		mr.AutomergeUsers = m.workzoneUsers(repo, setting, mr.AutomergeUsers)
+		wz.Mergerules[i] = mr
+	}
+	r.Workzone = &wz
+}
@@ -0,0 +1,80 @@
+package state
+
+import (
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/utils"
+	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
+)
+
+var webhookOps = utils.RepoItemOps[openapi.RestWebhook, int32]{
+	GetItems: func(r models.ExtendedRepository) []openapi.RestWebhook {
+		if r.Webhooks == nil {
+			return nil
+		}
+		return *r.Webhooks
+	},
+	SetItems: func(r *models.ExtendedRepository, items []openapi.RestWebhook) { r.Webhooks = &items },
+	GetID: func(it openapi.RestWebhook) (int32, bool) {
+		if it.Id == nil {
+			return 0, false
+		}
+		return *it.Id, true
+	},
+	Equal: bitbucket.AreWebhooksEqual,
+}
+
+var requiredBuildOps = utils.RepoItemOps[openapi.RestRequiredBuildCondition, int64]{
+	GetItems: func(r models.ExtendedRepository) []openapi.RestRequiredBuildCondition {
+		if r.RequiredBuilds == nil {
+			return nil
+		}
+		return *r.RequiredBuilds
+	},
+	SetItems: func(r *models.ExtendedRepository, items []openapi.RestRequiredBuildCondition) {
+		r.RequiredBuilds = &items
+	},
+	GetID: func(it openapi.RestRequiredBuildCondition) (int64, bool) {
+		if it.Id == nil {
+			return 0, false
+		}
+		return *it.Id, true
+	},
+	Equal: bitbucket.AreRequiredBuildsEqual,
+}
+
+// BranchPermissionOps is also used by repo branch-permission diff
+var BranchPermissionOps = utils.RepoItemOps[openapi.RestRefRestriction, int32]{
+	GetItems: func(r models.ExtendedRepository) []openapi.RestRefRestriction {
+		if r.BranchPermissions == nil {
+			return nil
+		}
+		return *r.BranchPermissions
+	},
+	SetItems: func(r *models.ExtendedRepository, items []openapi.RestRefRestriction) { r.BranchPermissions = &items },
+	GetID: func(it openapi.RestRefRestriction) (int32, bool) {
+		if it.Id == nil {
+			return 0, false
+		}
+		return *it.Id, true
+	},
+	Equal: bitbucket.AreBranchPermissionsEqual,
+}
+
+// ReviewerGroupOps is also used by repo reviewer-group diff
+var ReviewerGroupOps = utils.RepoItemOps[openapi.RestReviewerGroup, int64]{
+	GetItems: func(r models.ExtendedRepository) []openapi.RestReviewerGroup {
+		if r.ReviewerGroups == nil {
+			return nil
+		}
+		return *r.ReviewerGroups
+	},
+	SetItems: func(r *models.ExtendedRepository, items []openapi.RestReviewerGroup) { r.ReviewerGroups = &items },
+	GetID: func(it openapi.RestReviewerGroup) (int64, bool) {
+		if it.Id == nil {
+			return 0, false
+		}
+		return *it.Id, true
+	},
+	Equal: bitbucket.AreReviewerGroupsEqual,
+}
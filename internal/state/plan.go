@@ -0,0 +1,547 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/vinisman/bbctl/internal/bitbucket"
+	"github.com/vinisman/bbctl/internal/models"
+	"github.com/vinisman/bbctl/internal/workzone"
+	"github.com/vinisman/bbctl/utils"
+	openapi "github.com/vinisman/bitbucket-sdk-go/openapi"
+)
+
+// BuildPlan fetches live state for everything declared in desired and returns the
+// changes required to reach it, together with a fingerprint of the live objects the
+// plan was computed against. Projects, repositories, users and groups are only
+// created or updated, never deleted. Repository settings are reconciled fully for
+// every kind a repository declares, including deletion of items missing from the file.
+func BuildPlan(ctx context.Context, client *bitbucket.Client, desired models.StateYaml) (*models.StatePlan, string, error) {
+	if err := validateState(desired); err != nil {
+		return nil, "", err
+	}
+
+	plan := newPlan()
+	snap := &liveSnapshot{}
+
+	if err := planUsers(ctx, client, desired, plan, snap); err != nil {
+		return nil, "", err
+	}
+	if err := planGroups(ctx, client, desired, plan, snap); err != nil {
+		return nil, "", err
+	}
+	liveProjects, err := planProjects(ctx, client, desired, plan, snap)
+	if err != nil {
+		return nil, "", err
+	}
+	existing, err := planRepositories(ctx, client, desired, liveProjects, plan, snap)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := planRepoSettings(ctx, client, desired, existing, plan, snap); err != nil {
+		return nil, "", err
+	}
+
+	fingerprint, err := snap.fingerprint()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to compute live state fingerprint: %w", err)
+	}
+	return plan, fingerprint, nil
+}
+
+func newPlan() *models.StatePlan {
+	emptyRepoDiff := func() models.RepoDiff {
+		return models.RepoDiff{
+			Create: []models.ExtendedRepository{},
+			Update: []models.ExtendedRepository{},
+			Delete: []models.ExtendedRepository{},
+		}
+	}
+	return &models.StatePlan{
+		Users:             models.UserDiff{Create: []models.User{}, Update: []models.User{}, Delete: []models.User{}},
+		Groups:            models.GroupDiff{Create: []models.Group{}, Delete: []models.Group{}},
+		Projects:          models.ProjectDiff{Create: []openapi.RestProject{}, Update: []openapi.RestProject{}, Delete: []openapi.RestProject{}},
+		Repositories:      emptyRepoDiff(),
+		Webhooks:          emptyRepoDiff(),
+		RequiredBuilds:    emptyRepoDiff(),
+		BranchPermissions: emptyRepoDiff(),
+		ReviewerGroups:    emptyRepoDiff(),
+		Workzone:          emptyRepoDiff(),
+	}
+}
+
+// validateState checks identifiers required to match desired and live objects
+func validateState(desired models.StateYaml) error {
+	for i, p := range desired.Projects {
+		if utils.SafeValue(p.Key) == "" {
+			return fmt.Errorf("project at index %d is missing required field 'key'", i)
+		}
+	}
+	seen := map[string]bool{}
+	for i, r := range desired.Repositories {
+		if r.ProjectKey == "" || r.RepositorySlug == "" {
+			return fmt.Errorf("repository at index %d is missing required fields 'projectKey' and 'repositorySlug'", i)
+		}
+		key := repoKey(r)
+		if seen[key] {
+			return fmt.Errorf("repository %s is declared more than once", key)
+		}
+		seen[key] = true
+	}
+	for i, u := range desired.Users {
+		if u.Name == "" {
+			return fmt.Errorf("user at index %d is missing required field 'name'", i)
+		}
+	}
+	for i, g := range desired.Groups {
+		if g.Name == "" {
+			return fmt.Errorf("group at index %d is missing required field 'name'", i)
+		}
+	}
+	return nil
+}
+
+func planUsers(ctx context.Context, client *bitbucket.Client, desired models.StateYaml, plan *models.StatePlan, snap *liveSnapshot) error {
+	if len(desired.Users) == 0 {
+		return nil
+	}
+	live, err := client.GetAllUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch users: %w", err)
+	}
+	diff := DiffUsers(live, desired.Users)
+	plan.Users.Create = diff.Create
+	plan.Users.Update = diff.Update
+
+	declared := make(map[string]bool, len(desired.Users))
+	for _, u := range desired.Users {
+		declared[strings.ToLower(u.Name)] = true
+	}
+	for _, l := range live {
+		if declared[strings.ToLower(utils.SafeValue(l.Name))] {
+			snap.Users = append(snap.Users, l)
+		}
+	}
+	return nil
+}
+
+func planGroups(ctx context.Context, client *bitbucket.Client, desired models.StateYaml, plan *models.StatePlan, snap *liveSnapshot) error {
+	if len(desired.Groups) == 0 {
+		return nil
+	}
+	live, err := client.GetAllGroups(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch groups: %w", err)
+	}
+	diff := DiffGroups(live, desired.Groups)
+	plan.Groups.Create = diff.Create
+
+	created := make(map[string]bool, len(diff.Create))
+	for _, g := range diff.Create {
+		created[strings.ToLower(g.Name)] = true
+	}
+	for _, g := range desired.Groups {
+		if !created[strings.ToLower(g.Name)] {
+			snap.Groups = append(snap.Groups, g.Name)
+		}
+	}
+	return nil
+}
+
+// planProjects returns the set of project keys (upper-cased) that exist live
+func planProjects(ctx context.Context, client *bitbucket.Client, desired models.StateYaml, plan *models.StatePlan, snap *liveSnapshot) (map[string]bool, error) {
+	liveKeys := map[string]bool{}
+	if len(desired.Projects) == 0 && len(desired.Repositories) == 0 {
+		return liveKeys, nil
+	}
+
+	live, err := bitbucket.GetAllProjects(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch projects: %w", err)
+	}
+	liveByKey := make(map[string]openapi.RestProject, len(live))
+	for _, p := range live {
+		k := strings.ToUpper(utils.SafeValue(p.Key))
+		liveByKey[k] = p
+		liveKeys[k] = true
+	}
+
+	declared := map[string]bool{}
+	for _, p := range desired.Projects {
+		k := strings.ToUpper(*p.Key)
+		declared[k] = true
+		l, ok := liveByKey[k]
+		if !ok {
+			plan.Projects.Create = append(plan.Projects.Create, p)
+			continue
+		}
+		snap.Projects = append(snap.Projects, l)
+		if !bitbucket.AreProjectsEqual(l, p) {
+			plan.Projects.Update = append(plan.Projects.Update, p)
+		}
+	}
+
+	for _, r := range desired.Repositories {
+		k := strings.ToUpper(r.ProjectKey)
+		if !liveKeys[k] && !declared[k] {
+			return nil, fmt.Errorf("project %s of repository %s does not exist and is not declared in projects", r.ProjectKey, repoKey(r))
+		}
+	}
+	return liveKeys, nil
+}
+
+// planRepositories returns the desired repositories that already exist live
+func planRepositories(ctx context.Context, client *bitbucket.Client, desired models.StateYaml, liveProjects map[string]bool, plan *models.StatePlan, snap *liveSnapshot) ([]models.ExtendedRepository, error) {
+	if len(desired.Repositories) == 0 {
+		return nil, nil
+	}
+
+	// Fetch live repositories only for projects that already exist
+	liveRepos := map[string]models.ExtendedRepository{}
+	fetched := map[string]bool{}
+	for _, r := range desired.Repositories {
+		k := strings.ToUpper(r.ProjectKey)
+		if !liveProjects[k] || fetched[k] {
+			continue
+		}
+		fetched[k] = true
+		repos, err := client.GetAllReposForProject(ctx, r.ProjectKey, models.RepositoryOptions{Repository: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch repositories for project %s: %w", r.ProjectKey, err)
+		}
+		for _, lr := range repos {
+			liveRepos[strings.ToUpper(lr.ProjectKey)+"/"+lr.RepositorySlug] = lr
+		}
+	}
+
+	var existing []models.ExtendedRepository
+	for _, r := range desired.Repositories {
+		l, ok := liveRepos[strings.ToUpper(r.ProjectKey)+"/"+r.RepositorySlug]
+		if !ok {
+			plan.Repositories.Create = append(plan.Repositories.Create, models.ExtendedRepository{
+				ProjectKey:     r.ProjectKey,
+				RepositorySlug: r.RepositorySlug,
+				RestRepository: desiredRestRepository(r),
+			})
+			continue
+		}
+		existing = append(existing, models.ExtendedRepository{ProjectKey: r.ProjectKey, RepositorySlug: r.RepositorySlug})
+
+		if r.DefaultBranch != "" {
+			b, err := client.GetDefaultBranch(ctx, r.ProjectKey, r.RepositorySlug)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch default branch for %s: %w", repoKey(r), err)
+			}
+			l.RestRepository.DefaultBranch = &b
+		}
+		snap.Repositories = append(snap.Repositories, l)
+
+		if r.RestRepository == nil && r.DefaultBranch == "" {
+			continue
+		}
+		want := desiredRestRepository(r)
+		if repositoryNeedsUpdate(*l.RestRepository, *want) {
+			plan.Repositories.Update = append(plan.Repositories.Update, models.ExtendedRepository{
+				ProjectKey:     r.ProjectKey,
+				RepositorySlug: r.RepositorySlug,
+				RestRepository: want,
+			})
+		}
+	}
+	return existing, nil
+}
+
+// desiredRestRepository returns the repository payload for create/update.
+// A repository declared only by projectKey/repositorySlug is created with its slug as name.
+func desiredRestRepository(r models.ExtendedRepository) *openapi.RestRepository {
+	var rr openapi.RestRepository
+	if r.RestRepository != nil {
+		rr = *r.RestRepository
+	}
+	if rr.Name == nil {
+		rr.Name = utils.OptionalString(r.RepositorySlug)
+	}
+	if r.DefaultBranch != "" {
+		rr.DefaultBranch = utils.OptionalString(r.DefaultBranch)
+	}
+	return &rr
+}
+
+// repositoryNeedsUpdate reports whether any field set in desired differs from live
+func repositoryNeedsUpdate(live, desired openapi.RestRepository) bool {
+	if desired.Name != nil && !equalStringPtr(live.Name, desired.Name) {
+		return true
+	}
+	if desired.Description != nil && !equalStringPtr(live.Description, desired.Description) {
+		return true
+	}
+	if desired.DefaultBranch != nil && strings.TrimPrefix(utils.SafeValue(live.DefaultBranch), "refs/heads/") != strings.TrimPrefix(*desired.DefaultBranch, "refs/heads/") {
+		return true
+	}
+	if desired.Forkable != nil && utils.SafeValue(live.Forkable) != *desired.Forkable {
+		return true
+	}
+	if desired.Public != nil && utils.SafeValue(live.Public) != *desired.Public {
+		return true
+	}
+	if desired.Archived != nil && utils.SafeValue(live.Archived) != *desired.Archived {
+		return true
+	}
+	return false
+}
+
+// planRepoSettings diffs each settings kind declared in desired against live state
+func planRepoSettings(ctx context.Context, client *bitbucket.Client, desired models.StateYaml, existing []models.ExtendedRepository, plan *models.StatePlan, snap *liveSnapshot) error {
+	exists := map[string]bool{}
+	for _, r := range existing {
+		exists[repoKey(r)] = true
+	}
+
+	// declaredLive returns live stubs for existing repos that declare a kind, plus the declaring repos
+	declared := func(has func(models.ExtendedRepository) bool) (live, want []models.ExtendedRepository) {
+		for _, r := range desired.Repositories {
+			if !has(r) {
+				continue
+			}
+			want = append(want, r)
+			if exists[repoKey(r)] {
+				live = append(live, models.ExtendedRepository{ProjectKey: r.ProjectKey, RepositorySlug: r.RepositorySlug})
+			}
+		}
+		return live, want
+	}
+
+	// Webhooks
+	if live, want := declared(func(r models.ExtendedRepository) bool { return r.Webhooks != nil }); len(want) > 0 {
+		if len(live) > 0 {
+			fetched, err := client.GetWebhooks(ctx, live)
+			if err != nil {
+				return err
+			}
+			live = fetched
+		}
+		snap.Settings = append(snap.Settings, live...)
+		diff, err := diffSettings(live, want, webhookOps, func(it openapi.RestWebhook) string {
+			return utils.SafeValue(it.Name)
+		}, func(it *openapi.RestWebhook, id int32) { it.Id = &id })
+		if err != nil {
+			return err
+		}
+		plan.Webhooks = *diff
+	}
+
+	// Required builds
+	if live, want := declared(func(r models.ExtendedRepository) bool { return r.RequiredBuilds != nil }); len(want) > 0 {
+		if len(live) > 0 {
+			fetched, err := client.GetRequiredBuilds(ctx, live)
+			if err != nil {
+				return err
+			}
+			live = fetched
+		}
+		snap.Settings = append(snap.Settings, live...)
+		diff, err := diffSettings(live, want, requiredBuildOps, func(it openapi.RestRequiredBuildCondition) string {
+			return refMatcherKey(it.RefMatcher)
+		}, func(it *openapi.RestRequiredBuildCondition, id int64) { it.Id = &id })
+		if err != nil {
+			return err
+		}
+		plan.RequiredBuilds = *diff
+	}
+
+	// Branch permissions
+	if live, want := declared(func(r models.ExtendedRepository) bool { return r.BranchPermissions != nil }); len(want) > 0 {
+		if len(live) > 0 {
+			fetched, err := client.GetBranchPermissions(ctx, live)
+			if err != nil {
+				return err
+			}
+			live = OnlyRepositoryScoped(fetched)
+		}
+		snap.Settings = append(snap.Settings, live...)
+		diff, err := diffSettings(live, want, BranchPermissionOps, func(it openapi.RestRefRestriction) string {
+			return utils.SafeValue(it.Type) + "|" + refMatcherKey(it.Matcher)
+		}, func(it *openapi.RestRefRestriction, id int32) { it.Id = &id })
+		if err != nil {
+			return err
+		}
+		plan.BranchPermissions = *diff
+	}
+
+	// Reviewer groups
+	if live, want := declared(func(r models.ExtendedRepository) bool { return r.ReviewerGroups != nil }); len(want) > 0 {
+		if len(live) > 0 {
+			fetched, err := client.GetReviewerGroups(ctx, live)
+			if err != nil {
+				return err
+			}
+			live = OnlyRepositoryScoped(fetched)
+		}
+		snap.Settings = append(snap.Settings, live...)
+		diff, err := diffSettings(live, want, ReviewerGroupOps, func(it openapi.RestReviewerGroup) string {
+			return utils.SafeValue(it.Name)
+		}, func(it *openapi.RestReviewerGroup, id int64) { it.Id = &id })
+		if err != nil {
+			return err
+		}
+		plan.ReviewerGroups = *diff
+	}
+
+	// Workzone
+	if live, want := declared(func(r models.ExtendedRepository) bool { return r.Workzone != nil }); len(want) > 0 {
+		if len(live) > 0 {
+			fetched, err := fetchWorkzone(ctx, workzone.NewClient(client), live)
+			if err != nil {
+				return err
+			}
+			live = fetched
+		}
+		snap.Settings = append(snap.Settings, live...)
+		plan.Workzone = diffWorkzone(live, want)
+	}
+
+	return nil
+}
+
+// diffSettings adopts live ids for items matched by natural key and diffs live against desired
+func diffSettings[T any, ID comparable](live, want []models.ExtendedRepository, ops utils.RepoItemOps[T, ID], key func(T) string, setID func(*T, ID)) (*models.RepoDiff, error) {
+	want = utils.MatchRepoItemIDs(live, want, ops, key, setID)
+	return utils.GenerateRepoDiff(live, want, ops)
+}
+
+// OnlyRepositoryScoped drops inherited project-level branch permissions and reviewer
+// groups, which cannot be managed through repository endpoints.
+func OnlyRepositoryScoped(repos []models.ExtendedRepository) []models.ExtendedRepository {
+	isRepoScope := func(t *string) bool {
+		return t == nil || strings.EqualFold(*t, "REPOSITORY")
+	}
+	for i := range repos {
+		if repos[i].BranchPermissions != nil {
+			kept := []openapi.RestRefRestriction{}
+			for _, p := range *repos[i].BranchPermissions {
+				if p.Scope == nil || isRepoScope(p.Scope.Type) {
+					kept = append(kept, p)
+				}
+			}
+			repos[i].BranchPermissions = &kept
+		}
+		if repos[i].ReviewerGroups != nil {
+			kept := []openapi.RestReviewerGroup{}
+			for _, g := range *repos[i].ReviewerGroups {
+				if g.Scope == nil || isRepoScope(g.Scope.Type) {
+					kept = append(kept, g)
+				}
+			}
+			repos[i].ReviewerGroups = &kept
+		}
+	}
+	return repos
+}
+
+// fetchWorkzone fetches all Workzone sections for the given repositories.
+// All sections are fetched regardless of what is declared so that the live
+// fingerprint does not depend on which sections a repository lists.
+func fetchWorkzone(ctx context.Context, wzClient *workzone.Client, live []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+	out, err := wzClient.GetRepoWorkflows(ctx, live)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workzone workflow properties: %w", err)
+	}
+	if out, err = wzClient.GetReposReviewersList(ctx, out); err != nil {
+		return nil, fmt.Errorf("failed to fetch workzone reviewers: %w", err)
+	}
+	if out, err = wzClient.GetReposSignapprovers(ctx, out); err != nil {
+		return nil, fmt.Errorf("failed to fetch workzone sign approvers: %w", err)
+	}
+	if out, err = wzClient.GetReposAutomergers(ctx, out); err != nil {
+		return nil, fmt.Errorf("failed to fetch workzone mergerules: %w", err)
+	}
+	return out, nil
+}
+
+// diffWorkzone compares declared Workzone sections. Sections whose desired value
+// differs are placed in Update (desired values); list sections declared empty while
+// live is non-empty are placed in Delete (live values).
+func diffWorkzone(live, want []models.ExtendedRepository) models.RepoDiff {
+	diff := models.RepoDiff{
+		Create: []models.ExtendedRepository{},
+		Update: []models.ExtendedRepository{},
+		Delete: []models.ExtendedRepository{},
+	}
+
+	liveByKey := map[string]models.WorkzoneData{}
+	for _, r := range live {
+		if r.Workzone != nil {
+			liveByKey[repoKey(r)] = *r.Workzone
+		} else {
+			liveByKey[repoKey(r)] = models.WorkzoneData{}
+		}
+	}
+
+	for _, r := range want {
+		cur := liveByKey[repoKey(r)]
+		set := models.WorkzoneData{}
+		del := models.WorkzoneData{}
+		var hasSet, hasDel bool
+
+		if r.Workzone.WorkflowProperties != nil && !reflect.DeepEqual(cur.WorkflowProperties, r.Workzone.WorkflowProperties) {
+			set.WorkflowProperties = r.Workzone.WorkflowProperties
+			hasSet = true
+		}
+		if r.Workzone.Reviewers != nil {
+			if len(r.Workzone.Reviewers) == 0 && len(cur.Reviewers) > 0 {
+				del.Reviewers = cur.Reviewers
+				hasDel = true
+			} else if len(r.Workzone.Reviewers) > 0 && !reflect.DeepEqual(cur.Reviewers, r.Workzone.Reviewers) {
+				set.Reviewers = r.Workzone.Reviewers
+				hasSet = true
+			}
+		}
+		if r.Workzone.Signapprovers != nil {
+			if len(r.Workzone.Signapprovers) == 0 && len(cur.Signapprovers) > 0 {
+				del.Signapprovers = cur.Signapprovers
+				hasDel = true
+			} else if len(r.Workzone.Signapprovers) > 0 && !reflect.DeepEqual(cur.Signapprovers, r.Workzone.Signapprovers) {
+				set.Signapprovers = r.Workzone.Signapprovers
+				hasSet = true
+			}
+		}
+		if r.Workzone.Mergerules != nil {
+			if len(r.Workzone.Mergerules) == 0 && len(cur.Mergerules) > 0 {
+				del.Mergerules = cur.Mergerules
+				hasDel = true
+			} else if len(r.Workzone.Mergerules) > 0 && !reflect.DeepEqual(cur.Mergerules, r.Workzone.Mergerules) {
+				set.Mergerules = r.Workzone.Mergerules
+				hasSet = true
+			}
+		}
+
+		if hasSet {
+			diff.Update = append(diff.Update, models.ExtendedRepository{ProjectKey: r.ProjectKey, RepositorySlug: r.RepositorySlug, Workzone: &set})
+		}
+		if hasDel {
+			diff.Delete = append(diff.Delete, models.ExtendedRepository{ProjectKey: r.ProjectKey, RepositorySlug: r.RepositorySlug, Workzone: &del})
+		}
+	}
+	return diff
+}
+
+func repoKey(r models.ExtendedRepository) string {
+	return r.ProjectKey + "/" + r.RepositorySlug
+}
+
+func refMatcherKey(m *openapi.UpdatePullRequestCondition1RequestSourceMatcher) string {
+	if m == nil {
+		return ""
+	}
+	t := ""
+	if m.Type != nil {
+		t = utils.SafeValue(m.Type.Id)
+	}
+	return t + ":" + utils.SafeValue(m.Id)
+}
+
+func equalStringPtr(a, b *string) bool {
+	return utils.SafeValue(a) == utils.SafeValue(b)
+}
@@ -2,6 +2,7 @@ package workzone
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -11,12 +12,12 @@ import (
 )
 
 // GetRepoAutomergersList fetches branch mergerules list (settings) for a repo
-func (c *Client) GetRepoAutomergersList(projectKey, repoSlug string) ([]wz.RestBranchAutoMergers, error) {
+func (c *Client) GetRepoAutomergersList(ctx context.Context, projectKey, repoSlug string) ([]wz.RestBranchAutoMergers, error) {
 	if projectKey == "" || repoSlug == "" {
 		return nil, fmt.Errorf("projectKey and repoSlug are required")
 	}
 
-	items, httpResp, err := c.api.DefaultAPI.GetBranchAutomergersList(c.ctx, projectKey, repoSlug).Execute()
+	items, httpResp, err := c.api.DefaultAPI.GetBranchAutomergersList(c.bc.AuthContext(ctx), projectKey, repoSlug).Execute()
 	if err != nil {
 		if ge, ok := err.(*wz.GenericOpenAPIError); ok {
 			if config.GlobalLogger != nil {
@@ -33,8 +34,9 @@ func (c *Client) GetRepoAutomergersList(projectKey, repoSlug string) ([]wz.RestB
 }
 
 // GetReposAutomergers concurrently fetches mergerules for multiple repos
-func (c *Client) GetReposAutomergers(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) GetReposAutomergers(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	return batchGetOperation(
+		ctx,
 		repos,
 		c.GetRepoAutomergersList,
 		func(wz *models.WorkzoneData, items []wz.RestBranchAutoMergers) {
@@ -44,11 +46,11 @@ func (c *Client) GetReposAutomergers(repos []models.ExtendedRepository) ([]model
 }
 
 // SetBranchAutomergersList sets mergerules list for a repo
-func (c *Client) SetBranchAutomergersList(projectKey, repoSlug string, items []wz.RestBranchAutoMergers) error {
+func (c *Client) SetBranchAutomergersList(ctx context.Context, projectKey, repoSlug string, items []wz.RestBranchAutoMergers) error {
 	if projectKey == "" || repoSlug == "" {
 		return fmt.Errorf("projectKey and repoSlug are required")
 	}
-	httpResp, err := c.api.DefaultAPI.SetBranchAutomergersList(c.ctx, projectKey, repoSlug).RestBranchAutoMergers(items).Execute()
+	httpResp, err := c.api.DefaultAPI.SetBranchAutomergersList(c.bc.AuthContext(ctx), projectKey, repoSlug).RestBranchAutoMergers(items).Execute()
 	if err != nil {
 		if ge, ok := err.(*wz.GenericOpenAPIError); ok {
 			if config.GlobalLogger != nil {
@@ -57,7 +59,7 @@ func (c *Client) SetBranchAutomergersList(projectKey, repoSlug string, items []w
 		}
 		// Fallback: some Workzone versions respond 500 while actually applying the change.
 		if httpResp != nil && httpResp.StatusCode == 500 {
-			current, _, getErr := c.api.DefaultAPI.GetBranchAutomergersList(c.ctx, projectKey, repoSlug).Execute()
+			current, _, getErr := c.api.DefaultAPI.GetBranchAutomergersList(c.bc.AuthContext(ctx), projectKey, repoSlug).Execute()
 			if getErr == nil {
 				wantJSON, _ := json.Marshal(items)
 				gotJSON, _ := json.Marshal(current)
@@ -78,11 +80,11 @@ func (c *Client) SetBranchAutomergersList(projectKey, repoSlug string, items []w
 }
 
 // DeleteBranchAutomergersList deletes automergers list for a repo
-func (c *Client) DeleteBranchAutomergersList(projectKey, repoSlug string) error {
+func (c *Client) DeleteBranchAutomergersList(ctx context.Context, projectKey, repoSlug string) error {
 	if projectKey == "" || repoSlug == "" {
 		return fmt.Errorf("projectKey and repoSlug are required")
 	}
-	httpResp, err := c.api.DefaultAPI.DeleteBranchAutomergersList(c.ctx, projectKey, repoSlug).Execute()
+	httpResp, err := c.api.DefaultAPI.DeleteBranchAutomergersList(c.bc.AuthContext(ctx), projectKey, repoSlug).Execute()
 	if err != nil {
 		if httpResp != nil {
 			return fmt.Errorf("delete automergers list failed: http %d: %w", httpResp.StatusCode, err)
@@ -93,12 +95,12 @@ func (c *Client) DeleteBranchAutomergersList(projectKey, repoSlug string) error
 }
 
 // SetReposAutomergers concurrently sets automergers list for multiple repos
-func (c *Client) SetReposAutomergers(repos []models.ExtendedRepository) error {
-	return batchOperation(repos, func(r models.ExtendedRepository) error {
+func (c *Client) SetReposAutomergers(ctx context.Context, repos []models.ExtendedRepository) error {
+	return c.batchOperation(ctx, "set", "workzone mergerules", repos, func(ctx context.Context, r models.ExtendedRepository) error {
 		if r.Workzone == nil || len(r.Workzone.Mergerules) == 0 {
 			return fmt.Errorf("%s/%s: missing mergerules list", r.ProjectKey, r.RepositorySlug)
 		}
-		if err := c.SetBranchAutomergersList(r.ProjectKey, r.RepositorySlug, r.Workzone.Mergerules); err != nil {
+		if err := c.SetBranchAutomergersList(ctx, r.ProjectKey, r.RepositorySlug, r.Workzone.Mergerules); err != nil {
 			return fmt.Errorf("%s/%s: %w", r.ProjectKey, r.RepositorySlug, err)
 		}
 		return nil
@@ -106,9 +108,9 @@ func (c *Client) SetReposAutomergers(repos []models.ExtendedRepository) error {
 }
 
 // DeleteReposAutomergers concurrently deletes mergerules list for multiple repos
-func (c *Client) DeleteReposAutomergers(repos []models.ExtendedRepository) error {
-	return batchOperation(repos, func(r models.ExtendedRepository) error {
-		if err := c.DeleteBranchAutomergersList(r.ProjectKey, r.RepositorySlug); err != nil {
+func (c *Client) DeleteReposAutomergers(ctx context.Context, repos []models.ExtendedRepository) error {
+	return c.batchOperation(ctx, "delete", "workzone mergerules", repos, func(ctx context.Context, r models.ExtendedRepository) error {
+		if err := c.DeleteBranchAutomergersList(ctx, r.ProjectKey, r.RepositorySlug); err != nil {
 			return fmt.Errorf("%s/%s: %w", r.ProjectKey, r.RepositorySlug, err)
 		}
 		return nil
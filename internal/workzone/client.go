@@ -1,7 +1,6 @@
 package workzone
 
 import (
-	"context"
 	"strings"
 
 	bb "github.com/vinisman/bbctl/internal/bitbucket"
@@ -10,7 +9,7 @@ import (
 
 type Client struct {
 	api *wz.APIClient
-	ctx context.Context
+	bc  *bb.Client
 }
 
 // NewClient constructs Workzone client based on existing Bitbucket client configuration
@@ -35,12 +34,10 @@ func NewClient(bc *bb.Client) *Client {
 	cfg.AddDefaultHeader("Accept", "application/json")
 	// Content-Type should be set by the SDK automatically for each request
 
-	if bc.API().GetConfig().HTTPClient != nil {
-		cfg.HTTPClient = bc.API().GetConfig().HTTPClient
-	}
+	// Reuse the transport of the Bitbucket client (TLS, proxy, retries, rate limit)
+	cfg.HTTPClient = bc.HTTPClient()
 
-	return &Client{api: wz.NewAPIClient(cfg), ctx: bc.Context()}
+	return &Client{api: wz.NewAPIClient(cfg), bc: bc}
 }
 
-func (c *Client) API() *wz.APIClient       { return c.api }
-func (c *Client) Context() context.Context { return c.ctx }
+func (c *Client) API() *wz.APIClient { return c.api }
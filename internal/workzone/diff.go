@@ -0,0 +1,51 @@
+package workzone
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vinisman/bbctl/internal/models"
+)
+
+// ApplyReposDiff removes sections listed in diff.Delete and sets sections listed in diff.Update.
+// A section is selected by being non-empty in the repository's Workzone data; the values
+// in diff.Delete are only used to decide which sections to remove.
+func (c *Client) ApplyReposDiff(ctx context.Context, diff models.RepoDiff) error {
+	pick := func(repos []models.ExtendedRepository, has func(*models.WorkzoneData) bool) []models.ExtendedRepository {
+		var out []models.ExtendedRepository
+		for _, r := range repos {
+			if r.Workzone != nil && has(r.Workzone) {
+				out = append(out, r)
+			}
+		}
+		return out
+	}
+	hasProps := func(w *models.WorkzoneData) bool { return w.WorkflowProperties != nil }
+	hasReviewers := func(w *models.WorkzoneData) bool { return len(w.Reviewers) > 0 }
+	hasSignapprovers := func(w *models.WorkzoneData) bool { return len(w.Signapprovers) > 0 }
+	hasMergerules := func(w *models.WorkzoneData) bool { return len(w.Mergerules) > 0 }
+
+	steps := []struct {
+		name  string
+		repos []models.ExtendedRepository
+		run   func(context.Context, []models.ExtendedRepository) error
+	}{
+		{"delete workflow properties", pick(diff.Delete, hasProps), c.RemoveReposWorkflowProperties},
+		{"delete reviewers", pick(diff.Delete, hasReviewers), c.DeleteReposReviewersList},
+		{"delete sign approvers", pick(diff.Delete, hasSignapprovers), c.DeleteReposSignapprovers},
+		{"delete mergerules", pick(diff.Delete, hasMergerules), c.DeleteReposAutomergers},
+		{"set workflow properties", pick(diff.Update, hasProps), c.SetReposWorkflowProperties},
+		{"set reviewers", pick(diff.Update, hasReviewers), c.SetReposReviewersList},
+		{"set sign approvers", pick(diff.Update, hasSignapprovers), c.SetReposSignapprovers},
+		{"set mergerules", pick(diff.Update, hasMergerules), c.SetReposAutomergers},
+	}
+	for _, s := range steps {
+		if len(s.repos) == 0 {
+			continue
+		}
+		if err := s.run(ctx, s.repos); err != nil {
+			return fmt.Errorf("workzone %s failed: %w", s.name, err)
+		}
+	}
+	return nil
+}
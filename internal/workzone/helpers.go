@@ -1,18 +1,25 @@
 package workzone
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
 
+	bb "github.com/vinisman/bbctl/internal/bitbucket"
 	"github.com/vinisman/bbctl/internal/config"
 	"github.com/vinisman/bbctl/internal/models"
 )
 
-// batchOperation executes operation on multiple repositories concurrently
-func batchOperation(
+// batchOperation executes operation on multiple repositories concurrently.
+// Once ctx is cancelled, repositories that have not started yet are skipped.
+// In dry-run mode only the repositories are looked up and the change is logged.
+// The result of each repository is recorded in the report as op and kind.
+func (c *Client) batchOperation(
+	ctx context.Context,
+	op, kind string,
 	repos []models.ExtendedRepository,
-	operation func(models.ExtendedRepository) error,
+	operation func(context.Context, models.ExtendedRepository) error,
 ) error {
 	if len(repos) == 0 {
 		return nil
@@ -31,7 +38,14 @@ func batchOperation(
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			if err := operation(r); err != nil {
+			err := skipIfCanceled(ctx, r)
+			if err == nil && c.bc.DryRun() {
+				err = c.bc.PreviewRepoChange(ctx, op, kind, r)
+			} else if err == nil {
+				err = operation(ctx, r)
+			}
+			c.bc.Record(op, kind, r.ProjectKey+"/"+r.RepositorySlug, r, err)
+			if err != nil {
 				mu.Lock()
 				errs = append(errs, err)
 				mu.Unlock()
@@ -50,8 +64,9 @@ func batchOperation(
 // batchGetOperation executes get operation on multiple repositories concurrently
 // and populates the Workzone field of each repository
 func batchGetOperation[T any](
+	ctx context.Context,
 	repos []models.ExtendedRepository,
-	operation func(projectKey, repoSlug string) (T, error),
+	operation func(ctx context.Context, projectKey, repoSlug string) (T, error),
 	setter func(*models.WorkzoneData, T),
 ) ([]models.ExtendedRepository, error) {
 	if len(repos) == 0 {
@@ -75,7 +90,13 @@ func batchGetOperation[T any](
 			defer func() { <-sem }()
 
 			r := out[idx]
-			result, err := operation(r.ProjectKey, r.RepositorySlug)
+			if err := skipIfCanceled(ctx, r); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+				return
+			}
+			result, err := operation(ctx, r.ProjectKey, r.RepositorySlug)
 			if err != nil {
 				mu.Lock()
 				errs = append(errs, fmt.Errorf("%s/%s: %w", r.ProjectKey, r.RepositorySlug, err))
@@ -100,3 +121,13 @@ func batchGetOperation[T any](
 
 	return out, nil
 }
+
+// skipIfCanceled logs and returns an error for a repository that is not processed
+// because the operation was interrupted
+func skipIfCanceled(ctx context.Context, r models.ExtendedRepository) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	config.GlobalLogger.Warn("Skipped, operation interrupted", "project", r.ProjectKey, "repo", r.RepositorySlug)
+	return fmt.Errorf("%s/%s: %w: %w", r.ProjectKey, r.RepositorySlug, bb.ErrNotRun, ctx.Err())
+}
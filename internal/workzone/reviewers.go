@@ -1,6 +1,7 @@
 package workzone
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/vinisman/bbctl/internal/config"
@@ -9,12 +10,12 @@ import (
 )
 
 // GetRepoReviewersList fetches branch reviewers list using Workzone SDK
-func (c *Client) GetRepoReviewersList(projectKey, repoSlug string) (*models.WorkzoneData, error) {
+func (c *Client) GetRepoReviewersList(ctx context.Context, projectKey, repoSlug string) (*models.WorkzoneData, error) {
 	if projectKey == "" || repoSlug == "" {
 		return nil, fmt.Errorf("projectKey and repoSlug are required")
 	}
 
-	items, httpResp, err := c.api.DefaultAPI.GetBranchReviewersList(c.ctx, projectKey, repoSlug).Execute()
+	items, httpResp, err := c.api.DefaultAPI.GetBranchReviewersList(c.bc.AuthContext(ctx), projectKey, repoSlug).Execute()
 	if err != nil {
 		// If SDK failed to unmarshal with 200 OK, log raw body for diagnostics
 		if ge, ok := err.(*wz.GenericOpenAPIError); ok {
@@ -34,11 +35,12 @@ func (c *Client) GetRepoReviewersList(projectKey, repoSlug string) (*models.Work
 }
 
 // GetReposReviewersList concurrently fetches reviewers for multiple repos
-func (c *Client) GetReposReviewersList(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) GetReposReviewersList(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	return batchGetOperation(
+		ctx,
 		repos,
-		func(projectKey, repoSlug string) ([]wz.RestBranchReviewers, error) {
-			data, err := c.GetRepoReviewersList(projectKey, repoSlug)
+		func(ctx context.Context, projectKey, repoSlug string) ([]wz.RestBranchReviewers, error) {
+			data, err := c.GetRepoReviewersList(ctx, projectKey, repoSlug)
 			if err != nil {
 				return nil, err
 			}
@@ -51,11 +53,11 @@ func (c *Client) GetReposReviewersList(repos []models.ExtendedRepository) ([]mod
 }
 
 // SetBranchReviewersList sets reviewers list for a repo
-func (c *Client) SetBranchReviewersList(projectKey, repoSlug string, items []wz.RestBranchReviewers) error {
+func (c *Client) SetBranchReviewersList(ctx context.Context, projectKey, repoSlug string, items []wz.RestBranchReviewers) error {
 	if projectKey == "" || repoSlug == "" {
 		return fmt.Errorf("projectKey and repoSlug are required")
 	}
-	httpResp, err := c.api.DefaultAPI.SetBranchReviewersList(c.ctx, projectKey, repoSlug).RestBranchReviewers(items).Execute()
+	httpResp, err := c.api.DefaultAPI.SetBranchReviewersList(c.bc.AuthContext(ctx), projectKey, repoSlug).RestBranchReviewers(items).Execute()
 	if err != nil {
 		if httpResp != nil {
 			return fmt.Errorf("set reviewers list failed: http %d: %w", httpResp.StatusCode, err)
@@ -66,11 +68,11 @@ func (c *Client) SetBranchReviewersList(projectKey, repoSlug string, items []wz.
 }
 
 // DeleteBranchReviewersList deletes reviewers list for a repo
-func (c *Client) DeleteBranchReviewersList(projectKey, repoSlug string) error {
+func (c *Client) DeleteBranchReviewersList(ctx context.Context, projectKey, repoSlug string) error {
 	if projectKey == "" || repoSlug == "" {
 		return fmt.Errorf("projectKey and repoSlug are required")
 	}
-	httpResp, err := c.api.DefaultAPI.DeleteBranchReviewersList(c.ctx, projectKey, repoSlug).Execute()
+	httpResp, err := c.api.DefaultAPI.DeleteBranchReviewersList(c.bc.AuthContext(ctx), projectKey, repoSlug).Execute()
 	if err != nil {
 		if httpResp != nil {
 			return fmt.Errorf("delete reviewers list failed: http %d: %w", httpResp.StatusCode, err)
@@ -81,12 +83,12 @@ func (c *Client) DeleteBranchReviewersList(projectKey, repoSlug string) error {
 }
 
 // SetReposReviewersList concurrently sets reviewers list for multiple repos
-func (c *Client) SetReposReviewersList(repos []models.ExtendedRepository) error {
-	return batchOperation(repos, func(r models.ExtendedRepository) error {
+func (c *Client) SetReposReviewersList(ctx context.Context, repos []models.ExtendedRepository) error {
+	return c.batchOperation(ctx, "set", "workzone reviewers", repos, func(ctx context.Context, r models.ExtendedRepository) error {
 		if r.Workzone == nil || len(r.Workzone.Reviewers) == 0 {
 			return fmt.Errorf("%s/%s: missing reviewers list", r.ProjectKey, r.RepositorySlug)
 		}
-		if err := c.SetBranchReviewersList(r.ProjectKey, r.RepositorySlug, r.Workzone.Reviewers); err != nil {
+		if err := c.SetBranchReviewersList(ctx, r.ProjectKey, r.RepositorySlug, r.Workzone.Reviewers); err != nil {
 			return fmt.Errorf("%s/%s: %w", r.ProjectKey, r.RepositorySlug, err)
 		}
 		return nil
@@ -94,9 +96,9 @@ func (c *Client) SetReposReviewersList(repos []models.ExtendedRepository) error
 }
 
 // DeleteReposReviewersList concurrently deletes reviewers list for multiple repos
-func (c *Client) DeleteReposReviewersList(repos []models.ExtendedRepository) error {
-	return batchOperation(repos, func(r models.ExtendedRepository) error {
-		if err := c.DeleteBranchReviewersList(r.ProjectKey, r.RepositorySlug); err != nil {
+func (c *Client) DeleteReposReviewersList(ctx context.Context, repos []models.ExtendedRepository) error {
+	return c.batchOperation(ctx, "delete", "workzone reviewers", repos, func(ctx context.Context, r models.ExtendedRepository) error {
+		if err := c.DeleteBranchReviewersList(ctx, r.ProjectKey, r.RepositorySlug); err != nil {
 			return fmt.Errorf("%s/%s: %w", r.ProjectKey, r.RepositorySlug, err)
 		}
 		return nil
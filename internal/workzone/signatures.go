@@ -1,6 +1,7 @@
 package workzone
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/vinisman/bbctl/internal/config"
@@ -9,12 +10,12 @@ import (
 )
 
 // GetRepoSignapproversList fetches branch sign approvers list (settings) for a repo
-func (c *Client) GetRepoSignapproversList(projectKey, repoSlug string) ([]wz.RestBranchSignapprovers, error) {
+func (c *Client) GetRepoSignapproversList(ctx context.Context, projectKey, repoSlug string) ([]wz.RestBranchSignapprovers, error) {
 	if projectKey == "" || repoSlug == "" {
 		return nil, fmt.Errorf("projectKey and repoSlug are required")
 	}
 
-	items, httpResp, err := c.api.DefaultAPI.GetSignapproversList(c.ctx, projectKey, repoSlug).Execute()
+	items, httpResp, err := c.api.DefaultAPI.GetSignapproversList(c.bc.AuthContext(ctx), projectKey, repoSlug).Execute()
 	if err != nil {
 		if ge, ok := err.(*wz.GenericOpenAPIError); ok {
 			if config.GlobalLogger != nil {
@@ -31,8 +32,9 @@ func (c *Client) GetRepoSignapproversList(projectKey, repoSlug string) ([]wz.Res
 }
 
 // GetReposSignapprovers concurrently fetches signatures for multiple repos
-func (c *Client) GetReposSignapprovers(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) GetReposSignapprovers(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	return batchGetOperation(
+		ctx,
 		repos,
 		c.GetRepoSignapproversList,
 		func(wz *models.WorkzoneData, items []wz.RestBranchSignapprovers) {
@@ -42,11 +44,11 @@ func (c *Client) GetReposSignapprovers(repos []models.ExtendedRepository) ([]mod
 }
 
 // SetSignApproversList sets sign approvers list for a repo
-func (c *Client) SetSignApproversList(projectKey, repoSlug string, items []wz.RestBranchSignapprovers) error {
+func (c *Client) SetSignApproversList(ctx context.Context, projectKey, repoSlug string, items []wz.RestBranchSignapprovers) error {
 	if projectKey == "" || repoSlug == "" {
 		return fmt.Errorf("projectKey and repoSlug are required")
 	}
-	httpResp, err := c.api.DefaultAPI.SetSignApproversList(c.ctx, projectKey, repoSlug).RestBranchSignapprovers(items).Execute()
+	httpResp, err := c.api.DefaultAPI.SetSignApproversList(c.bc.AuthContext(ctx), projectKey, repoSlug).RestBranchSignapprovers(items).Execute()
 	if err != nil {
 		if httpResp != nil {
 			return fmt.Errorf("set signapprovers list failed: http %d: %w", httpResp.StatusCode, err)
@@ -57,11 +59,11 @@ func (c *Client) SetSignApproversList(projectKey, repoSlug string, items []wz.Re
 }
 
 // DeleteSignapproversList deletes sign approvers list for a repo
-func (c *Client) DeleteSignapproversList(projectKey, repoSlug string) error {
+func (c *Client) DeleteSignapproversList(ctx context.Context, projectKey, repoSlug string) error {
 	if projectKey == "" || repoSlug == "" {
 		return fmt.Errorf("projectKey and repoSlug are required")
 	}
-	httpResp, err := c.api.DefaultAPI.DeleteSignapproversList(c.ctx, projectKey, repoSlug).Execute()
+	httpResp, err := c.api.DefaultAPI.DeleteSignapproversList(c.bc.AuthContext(ctx), projectKey, repoSlug).Execute()
 	if err != nil {
 		if httpResp != nil {
 			return fmt.Errorf("delete signapprovers list failed: http %d: %w", httpResp.StatusCode, err)
@@ -72,12 +74,12 @@ func (c *Client) DeleteSignapproversList(projectKey, repoSlug string) error {
 }
 
 // SetReposSignapprovers concurrently sets sign approvers list for multiple repos
-func (c *Client) SetReposSignapprovers(repos []models.ExtendedRepository) error {
-	return batchOperation(repos, func(r models.ExtendedRepository) error {
+func (c *Client) SetReposSignapprovers(ctx context.Context, repos []models.ExtendedRepository) error {
+	return c.batchOperation(ctx, "set", "workzone signatures", repos, func(ctx context.Context, r models.ExtendedRepository) error {
 		if r.Workzone == nil || len(r.Workzone.Signapprovers) == 0 {
 			return fmt.Errorf("%s/%s: missing signapprovers list", r.ProjectKey, r.RepositorySlug)
 		}
-		if err := c.SetSignApproversList(r.ProjectKey, r.RepositorySlug, r.Workzone.Signapprovers); err != nil {
+		if err := c.SetSignApproversList(ctx, r.ProjectKey, r.RepositorySlug, r.Workzone.Signapprovers); err != nil {
 			return fmt.Errorf("%s/%s: %w", r.ProjectKey, r.RepositorySlug, err)
 		}
 		return nil
@@ -85,9 +87,9 @@ func (c *Client) SetReposSignapprovers(repos []models.ExtendedRepository) error
 }
 
 // DeleteReposSignapprovers concurrently deletes sign approvers list for multiple repos
-func (c *Client) DeleteReposSignapprovers(repos []models.ExtendedRepository) error {
-	return batchOperation(repos, func(r models.ExtendedRepository) error {
-		if err := c.DeleteSignapproversList(r.ProjectKey, r.RepositorySlug); err != nil {
+func (c *Client) DeleteReposSignapprovers(ctx context.Context, repos []models.ExtendedRepository) error {
+	return c.batchOperation(ctx, "delete", "workzone signatures", repos, func(ctx context.Context, r models.ExtendedRepository) error {
+		if err := c.DeleteSignapproversList(ctx, r.ProjectKey, r.RepositorySlug); err != nil {
 			return fmt.Errorf("%s/%s: %w", r.ProjectKey, r.RepositorySlug, err)
 		}
 		return nil
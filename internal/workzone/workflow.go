@@ -1,6 +1,7 @@
 package workzone
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/vinisman/bbctl/internal/models"
@@ -11,11 +12,12 @@ import (
 // (single-repo method removed; batch method below is used by CLI)
 
 // GetRepoWorkflows fetches WorkflowProperties for multiple repositories concurrently
-func (c *Client) GetRepoWorkflows(repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
+func (c *Client) GetRepoWorkflows(ctx context.Context, repos []models.ExtendedRepository) ([]models.ExtendedRepository, error) {
 	return batchGetOperation(
+		ctx,
 		repos,
-		func(projectKey, repoSlug string) (*wz.WorkflowProperties, error) {
-			props, httpResp, err := c.api.DefaultAPI.GetRepoWorkflowProperties(c.ctx, projectKey, repoSlug).Execute()
+		func(ctx context.Context, projectKey, repoSlug string) (*wz.WorkflowProperties, error) {
+			props, httpResp, err := c.api.DefaultAPI.GetRepoWorkflowProperties(c.bc.AuthContext(ctx), projectKey, repoSlug).Execute()
 			if err != nil {
 				if httpResp != nil {
 					return nil, fmt.Errorf("http %d: %w", httpResp.StatusCode, err)
@@ -31,11 +33,11 @@ func (c *Client) GetRepoWorkflows(repos []models.ExtendedRepository) ([]models.E
 }
 
 // SetRepoWorkflowProperties sets workflow properties for a repo
-func (c *Client) SetRepoWorkflowProperties(projectKey, repoSlug string, props *wz.WorkflowProperties) error {
+func (c *Client) SetRepoWorkflowProperties(ctx context.Context, projectKey, repoSlug string, props *wz.WorkflowProperties) error {
 	if projectKey == "" || repoSlug == "" || props == nil {
 		return fmt.Errorf("projectKey, repoSlug and props are required")
 	}
-	httpResp, err := c.api.DefaultAPI.SetRepoWorkflowProperties(c.ctx, projectKey, repoSlug).WorkflowProperties(*props).Execute()
+	httpResp, err := c.api.DefaultAPI.SetRepoWorkflowProperties(c.bc.AuthContext(ctx), projectKey, repoSlug).WorkflowProperties(*props).Execute()
 	if err != nil {
 		if httpResp != nil {
 			return fmt.Errorf("set workflow properties failed: http %d: %w", httpResp.StatusCode, err)
@@ -46,11 +48,11 @@ func (c *Client) SetRepoWorkflowProperties(projectKey, repoSlug string, props *w
 }
 
 // UpdateRepoWorkflowProperties updates workflow properties for a repo
-func (c *Client) UpdateRepoWorkflowProperties(projectKey, repoSlug string, props *wz.WorkflowProperties) error {
+func (c *Client) UpdateRepoWorkflowProperties(ctx context.Context, projectKey, repoSlug string, props *wz.WorkflowProperties) error {
 	if projectKey == "" || repoSlug == "" || props == nil {
 		return fmt.Errorf("projectKey, repoSlug and props are required")
 	}
-	httpResp, err := c.api.DefaultAPI.UpdateRepoWorkflowProperties(c.ctx, projectKey, repoSlug).WorkflowProperties(*props).Execute()
+	httpResp, err := c.api.DefaultAPI.UpdateRepoWorkflowProperties(c.bc.AuthContext(ctx), projectKey, repoSlug).WorkflowProperties(*props).Execute()
 	if err != nil {
 		if httpResp != nil {
 			return fmt.Errorf("update workflow properties failed: http %d: %w", httpResp.StatusCode, err)
@@ -61,11 +63,11 @@ func (c *Client) UpdateRepoWorkflowProperties(projectKey, repoSlug string, props
 }
 
 // RemoveRepoWorkflowProperties deletes workflow properties for a repo
-func (c *Client) RemoveRepoWorkflowProperties(projectKey, repoSlug string) error {
+func (c *Client) RemoveRepoWorkflowProperties(ctx context.Context, projectKey, repoSlug string) error {
 	if projectKey == "" || repoSlug == "" {
 		return fmt.Errorf("projectKey and repoSlug are required")
 	}
-	httpResp, err := c.api.DefaultAPI.RemoveRepoWorkflowProperties(c.ctx, projectKey, repoSlug).Execute()
+	httpResp, err := c.api.DefaultAPI.RemoveRepoWorkflowProperties(c.bc.AuthContext(ctx), projectKey, repoSlug).Execute()
 	if err != nil {
 		if httpResp != nil {
 			return fmt.Errorf("remove workflow properties failed: http %d: %w", httpResp.StatusCode, err)
@@ -76,12 +78,12 @@ func (c *Client) RemoveRepoWorkflowProperties(projectKey, repoSlug string) error
 }
 
 // SetReposWorkflowProperties concurrently sets workflow properties for multiple repos
-func (c *Client) SetReposWorkflowProperties(repos []models.ExtendedRepository) error {
-	return batchOperation(repos, func(r models.ExtendedRepository) error {
+func (c *Client) SetReposWorkflowProperties(ctx context.Context, repos []models.ExtendedRepository) error {
+	return c.batchOperation(ctx, "set", "workzone properties", repos, func(ctx context.Context, r models.ExtendedRepository) error {
 		if r.Workzone == nil || r.Workzone.WorkflowProperties == nil {
 			return fmt.Errorf("%s/%s: missing workflowProperties", r.ProjectKey, r.RepositorySlug)
 		}
-		if err := c.SetRepoWorkflowProperties(r.ProjectKey, r.RepositorySlug, r.Workzone.WorkflowProperties); err != nil {
+		if err := c.SetRepoWorkflowProperties(ctx, r.ProjectKey, r.RepositorySlug, r.Workzone.WorkflowProperties); err != nil {
 			return fmt.Errorf("%s/%s: %w", r.ProjectKey, r.RepositorySlug, err)
 		}
 		return nil
@@ -89,12 +91,12 @@ func (c *Client) SetReposWorkflowProperties(repos []models.ExtendedRepository) e
 }
 
 // UpdateReposWorkflowProperties concurrently updates workflow properties for multiple repos
-func (c *Client) UpdateReposWorkflowProperties(repos []models.ExtendedRepository) error {
-	return batchOperation(repos, func(r models.ExtendedRepository) error {
+func (c *Client) UpdateReposWorkflowProperties(ctx context.Context, repos []models.ExtendedRepository) error {
+	return c.batchOperation(ctx, "update", "workzone properties", repos, func(ctx context.Context, r models.ExtendedRepository) error {
 		if r.Workzone == nil || r.Workzone.WorkflowProperties == nil {
 			return fmt.Errorf("%s/%s: missing workflowProperties", r.ProjectKey, r.RepositorySlug)
 		}
-		if err := c.UpdateRepoWorkflowProperties(r.ProjectKey, r.RepositorySlug, r.Workzone.WorkflowProperties); err != nil {
+		if err := c.UpdateRepoWorkflowProperties(ctx, r.ProjectKey, r.RepositorySlug, r.Workzone.WorkflowProperties); err != nil {
 			return fmt.Errorf("%s/%s: %w", r.ProjectKey, r.RepositorySlug, err)
 		}
 		return nil
@@ -102,9 +104,9 @@ func (c *Client) UpdateReposWorkflowProperties(repos []models.ExtendedRepository
 }
 
 // RemoveReposWorkflowProperties concurrently removes workflow properties for multiple repos
-func (c *Client) RemoveReposWorkflowProperties(repos []models.ExtendedRepository) error {
-	return batchOperation(repos, func(r models.ExtendedRepository) error {
-		if err := c.RemoveRepoWorkflowProperties(r.ProjectKey, r.RepositorySlug); err != nil {
+func (c *Client) RemoveReposWorkflowProperties(ctx context.Context, repos []models.ExtendedRepository) error {
+	return c.batchOperation(ctx, "delete", "workzone properties", repos, func(ctx context.Context, r models.ExtendedRepository) error {
+		if err := c.RemoveRepoWorkflowProperties(ctx, r.ProjectKey, r.RepositorySlug); err != nil {
 			return fmt.Errorf("%s/%s: %w", r.ProjectKey, r.RepositorySlug, err)
 		}
 		return nil
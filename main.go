@@ -1,14 +1,51 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/vinisman/bbctl/cmd"
+	"github.com/vinisman/bbctl/internal/bitbucket"
 )
 
 func main() {
+	// The first SIGINT/SIGTERM cancels the running operation: workers stop
+	// picking new jobs and in-flight requests are aborted. A second one
+	// terminates the process immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	rootCmd := cmd.NewRootCmd()
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.ExecuteContext(ctx)
+	if ctx.Err() != nil {
+		printInterrupted()
+		os.Exit(130)
+	}
+	if err != nil {
 		os.Exit(1)
 	}
 }
+
+// printInterrupted lists the items of the bulk operations that completed before
+// the interrupt and those that were never run
+func printInterrupted() {
+	summary := bitbucket.GlobalReport.Summary()
+	fmt.Fprintf(os.Stderr, "Interrupted: %d succeeded, %d failed, %d not run\n", summary.Succeeded, summary.Failed, summary.Skipped)
+	for _, group := range []struct{ status, label string }{
+		{bitbucket.StatusSucceeded, "completed"},
+		{bitbucket.StatusSkipped, "not run"},
+	} {
+		for _, it := range summary.Items {
+			if it.Status == group.status {
+				fmt.Fprintf(os.Stderr, "  %s: %s %s %s\n", group.label, it.Operation, it.Kind, it.Item)
+			}
+		}
+	}
+}
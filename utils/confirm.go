@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vinisman/bbctl/internal/config"
+)
+
+// confirmSample is the number of targets listed in the confirmation prompt
+const confirmSample = 10
+
+var kindPlurals = map[string]string{
+	config.KindProject:    "projects",
+	config.KindRepository: "repositories",
+	config.KindUser:       "users",
+	config.KindGroup:      "groups",
+	config.KindPermission: "permissions",
+	config.KindMember:     "group members",
+}
+
+// ConfirmDelete guards a command before anything is deleted, revoked or removed,
+// also deletes of sync --prune, revokes of permission set and removals of group
+// members set. It refuses protected
+// targets unless --force-protected is set and more targets than --max-deletes,
+// then asks for confirmation when stdin is a terminal, unless --yes or --dry-run
+// is set.
+func ConfirmDelete(kind string, targets []string) error {
+	if len(targets) == 0 {
+		return nil
+	}
+	cfg := config.GlobalCfg
+	plural := kindPlurals[kind]
+	verb := "delete"
+	switch kind {
+	case config.KindPermission:
+		verb = "revoke"
+	case config.KindMember:
+		verb = "remove"
+	}
+
+	var protected []string
+	for _, t := range targets {
+		if pattern := cfg.ProtectedBy(kind, t); pattern != "" {
+			protected = append(protected, t)
+			config.GlobalLogger.Debug("Target is protected", "kind", kind, "item", t, "pattern", pattern)
+		}
+	}
+	if len(protected) > 0 {
+		if !cfg.ForceProtected {
+			return fmt.Errorf("refusing to %s protected %s %s, use --force-protected to %s them",
+				verb, plural, strings.Join(protected, ", "), verb)
+		}
+		config.GlobalLogger.Warn("Going to "+verb+" protected "+plural, "items", strings.Join(protected, ", "))
+	}
+
+	if cfg.MaxDeletes > 0 && len(targets) > cfg.MaxDeletes {
+		return fmt.Errorf("refusing to %s %d %s, more than --max-deletes %d", verb, len(targets), plural, cfg.MaxDeletes)
+	}
+
+	if cfg.AssumeYes || cfg.DryRun || !isTerminal(os.Stdin) {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "About to %s %d %s:\n", verb, len(targets), plural)
+	for i, t := range targets {
+		if i == confirmSample {
+			fmt.Fprintf(os.Stderr, "  ... and %d more\n", len(targets)-confirmSample)
+			break
+		}
+		fmt.Fprintf(os.Stderr, "  %s\n", t)
+	}
+	fmt.Fprint(os.Stderr, "Continue? [y/N]: ")
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return fmt.Errorf("no confirmation given, use --yes to skip it")
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return fmt.Errorf("deletion cancelled")
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,571 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONPath templates follow kubectl: text with {expressions} such as {.a.b},
+// {.items[*].name}, {.items[0]}, {.items[1:3]}, {.items[::2]}, {..name}, {.items[?(@.active==true)].name},
+// {range .items[*]}...{end} and quoted literals like {"\n"}. The results of an
+// expression are separated by spaces, strings are printed as is and objects as JSON.
+// Missing fields give no result.
+
+// jsonPathNode is a piece of a parsed template: text, a literal, an expression or a range
+type jsonPathNode struct {
+	text  string
+	path  []jsonPathStep // nil for text
+	body  []jsonPathNode // range body
+	isRng bool
+}
+
+// jsonPathStep is one step of an expression
+type jsonPathStep struct {
+	kind   string // "child", "wildcard", "recursive", "index", "slice", "filter"
+	name   string
+	index  int
+	start  *int
+	end    *int
+	stride int // slice step, 0 for 1
+	filter *jsonPathFilter
+}
+
+// jsonPathFilter is a filter such as ?(@.name=="x") or ?(@.active)
+type jsonPathFilter struct {
+	path  []jsonPathStep
+	op    string // empty for an existence test
+	value any
+}
+
+// executeJSONPath parses the template and writes its result for data to w
+func executeJSONPath(w io.Writer, template string, data any) error {
+	nodes, err := parseJSONPath(template)
+	if err != nil {
+		return fmt.Errorf("invalid jsonpath template: %w", err)
+	}
+	return writeJSONPathNodes(w, nodes, data)
+}
+
+func writeJSONPathNodes(w io.Writer, nodes []jsonPathNode, data any) error {
+	for _, n := range nodes {
+		switch {
+		case n.isRng:
+			for _, item := range evalJSONPath(n.path, data) {
+				items := []any{item}
+				if list, ok := item.([]any); ok {
+					items = list
+				}
+				for _, it := range items {
+					if err := writeJSONPathNodes(w, n.body, it); err != nil {
+						return err
+					}
+				}
+			}
+		case n.path != nil:
+			var out []string
+			for _, v := range evalJSONPath(n.path, data) {
+				out = append(out, jsonPathString(v))
+			}
+			if _, err := io.WriteString(w, strings.Join(out, " ")); err != nil {
+				return err
+			}
+		default:
+			if _, err := io.WriteString(w, n.text); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonPathString formats a result: strings as is, objects and lists as JSON
+func jsonPathString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// parseJSONPath splits the template into text and {expressions}, nesting range bodies
+func parseJSONPath(template string) ([]jsonPathNode, error) {
+	type frame struct {
+		node  jsonPathNode
+		nodes []jsonPathNode
+	}
+	stack := []frame{{}}
+	add := func(n jsonPathNode) {
+		top := &stack[len(stack)-1]
+		top.nodes = append(top.nodes, n)
+	}
+
+	for len(template) > 0 {
+		open := strings.IndexByte(template, '{')
+		if open < 0 {
+			add(jsonPathNode{text: template})
+			break
+		}
+		if open > 0 {
+			add(jsonPathNode{text: template[:open]})
+		}
+		end, err := closingBrace(template, open)
+		if err != nil {
+			return nil, err
+		}
+		expr := strings.TrimSpace(template[open+1 : end])
+		template = template[end+1:]
+
+		switch {
+		case expr == "end":
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("{end} without {range}")
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			top.node.body = top.nodes
+			add(top.node)
+		case strings.HasPrefix(expr, "range "):
+			path, err := parseJSONPathExpr(strings.TrimSpace(strings.TrimPrefix(expr, "range ")))
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, frame{node: jsonPathNode{path: path, isRng: true}})
+		case strings.HasPrefix(expr, `"`) || strings.HasPrefix(expr, "'"):
+			text, err := unquoteJSONPath(expr)
+			if err != nil {
+				return nil, err
+			}
+			add(jsonPathNode{text: text})
+		default:
+			path, err := parseJSONPathExpr(expr)
+			if err != nil {
+				return nil, err
+			}
+			add(jsonPathNode{path: path})
+		}
+	}
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("{range} without {end}")
+	}
+	return stack[0].nodes, nil
+}
+
+// closingBrace returns the position of the } closing the { at open, skipping quoted text
+func closingBrace(s string, open int) (int, error) {
+	var quote byte
+	for i := open + 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '}':
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unclosed { at position %d", open)
+}
+
+// unquoteJSONPath unquotes a literal in double or single quotes
+func unquoteJSONPath(s string) (string, error) {
+	if strings.HasPrefix(s, "'") {
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("invalid literal %s", s)
+		}
+		return s[1 : len(s)-1], nil
+	}
+	text, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid literal %s", s)
+	}
+	return text, nil
+}
+
+var jsonPathName = regexp.MustCompile(`^[A-Za-z0-9_\-]+`)
+
+// parseJSONPathExpr parses an expression such as .a.b[*].c or $..name
+func parseJSONPathExpr(expr string) ([]jsonPathStep, error) {
+	orig := expr
+	expr = strings.TrimPrefix(strings.TrimPrefix(expr, "$"), "@")
+	steps := []jsonPathStep{}
+
+	for len(expr) > 0 {
+		switch {
+		case strings.HasPrefix(expr, ".."):
+			name := jsonPathName.FindString(expr[2:])
+			if name == "" {
+				return nil, fmt.Errorf("expected a field name after .. in %q", orig)
+			}
+			steps = append(steps, jsonPathStep{kind: "recursive", name: name})
+			expr = expr[2+len(name):]
+		case strings.HasPrefix(expr, ".*"):
+			steps = append(steps, jsonPathStep{kind: "wildcard"})
+			expr = expr[2:]
+		case strings.HasPrefix(expr, "."):
+			name := jsonPathName.FindString(expr[1:])
+			expr = expr[1+len(name):]
+			if name != "" {
+				steps = append(steps, jsonPathStep{kind: "child", name: name})
+			}
+		case strings.HasPrefix(expr, "["):
+			end, err := closingBracket(expr)
+			if err != nil {
+				return nil, fmt.Errorf("%w in %q", err, orig)
+			}
+			step, err := parseJSONPathBracket(strings.TrimSpace(expr[1:end]))
+			if err != nil {
+				return nil, fmt.Errorf("%w in %q", err, orig)
+			}
+			steps = append(steps, step)
+			expr = expr[end+1:]
+		default:
+			name := jsonPathName.FindString(expr)
+			if name == "" || len(steps) > 0 {
+				return nil, fmt.Errorf("unexpected %q in %q", expr, orig)
+			}
+			// a leading field without dot, e.g. {repositories[0]}
+			steps = append(steps, jsonPathStep{kind: "child", name: name})
+			expr = expr[len(name):]
+		}
+	}
+	return steps, nil
+}
+
+// closingBracket returns the position of the ] closing the [ at the start of s
+func closingBracket(s string) (int, error) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unclosed [")
+}
+
+// parseJSONPathBracket parses the content of [...]: *, an index, a slice, a quoted name or a filter
+func parseJSONPathBracket(s string) (jsonPathStep, error) {
+	switch {
+	case s == "*":
+		return jsonPathStep{kind: "wildcard"}, nil
+	case strings.HasPrefix(s, "?(") && strings.HasSuffix(s, ")"):
+		f, err := parseJSONPathFilter(strings.TrimSpace(s[2 : len(s)-1]))
+		if err != nil {
+			return jsonPathStep{}, err
+		}
+		return jsonPathStep{kind: "filter", filter: f}, nil
+	case strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`):
+		name, err := unquoteJSONPath(s)
+		if err != nil {
+			return jsonPathStep{}, err
+		}
+		return jsonPathStep{kind: "child", name: name}, nil
+	case strings.Contains(s, ":"):
+		parts := strings.Split(s, ":")
+		if len(parts) > 3 {
+			return jsonPathStep{}, fmt.Errorf("invalid slice [%s]", s)
+		}
+		step := jsonPathStep{kind: "slice"}
+		for i, p := range parts {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				return jsonPathStep{}, fmt.Errorf("invalid slice [%s]", s)
+			}
+			switch i {
+			case 0:
+				step.start = &n
+			case 1:
+				step.end = &n
+			default:
+				if n <= 0 {
+					return jsonPathStep{}, fmt.Errorf("slice step must be positive in [%s]", s)
+				}
+				step.stride = n
+			}
+		}
+		return step, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return jsonPathStep{}, fmt.Errorf("invalid index [%s]", s)
+	}
+	return jsonPathStep{kind: "index", index: n}, nil
+}
+
+var jsonPathOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseJSONPathFilter parses @.path, or @.path followed by an operator and a literal
+func parseJSONPathFilter(s string) (*jsonPathFilter, error) {
+	f := &jsonPathFilter{}
+	left := s
+	if i, op := findJSONPathOp(s); op != "" {
+		left, f.op = strings.TrimSpace(s[:i]), op
+		literal := strings.TrimSpace(s[i+len(op):])
+		switch {
+		case strings.HasPrefix(literal, "'") || strings.HasPrefix(literal, `"`):
+			text, err := unquoteJSONPath(literal)
+			if err != nil {
+				return nil, err
+			}
+			f.value = text
+		case literal == "true" || literal == "false":
+			f.value = literal == "true"
+		default:
+			n, err := strconv.ParseFloat(literal, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter value %s", literal)
+			}
+			f.value = n
+		}
+	}
+	if !strings.HasPrefix(left, "@") {
+		return nil, fmt.Errorf("filter must start with @: %s", s)
+	}
+	path, err := parseJSONPathExpr(left)
+	if err != nil {
+		return nil, err
+	}
+	f.path = path
+	return f, nil
+}
+
+// findJSONPathOp returns the position of the first comparison operator outside quotes
+func findJSONPathOp(s string) (int, string) {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		default:
+			for _, op := range jsonPathOps {
+				if strings.HasPrefix(s[i:], op) {
+					return i, op
+				}
+			}
+		}
+	}
+	return -1, ""
+}
+
+// evalJSONPath applies the steps to data and returns all results
+func evalJSONPath(steps []jsonPathStep, data any) []any {
+	results := []any{data}
+	for _, step := range steps {
+		var next []any
+		for _, v := range results {
+			next = append(next, evalJSONPathStep(step, v)...)
+		}
+		results = next
+	}
+	return results
+}
+
+func evalJSONPathStep(step jsonPathStep, v any) []any {
+	switch step.kind {
+	case "child":
+		if m, ok := v.(map[string]any); ok {
+			if child, ok := m[step.name]; ok {
+				return []any{child}
+			}
+		}
+	case "wildcard":
+		return jsonPathChildren(v)
+	case "recursive":
+		var out []any
+		if m, ok := v.(map[string]any); ok {
+			if child, ok := m[step.name]; ok {
+				out = append(out, child)
+			}
+		}
+		for _, child := range jsonPathChildren(v) {
+			out = append(out, evalJSONPathStep(step, child)...)
+		}
+		return out
+	case "index":
+		if list, ok := v.([]any); ok {
+			i := step.index
+			if i < 0 {
+				i += len(list)
+			}
+			if i >= 0 && i < len(list) {
+				return []any{list[i]}
+			}
+		}
+	case "slice":
+		if list, ok := v.([]any); ok {
+			start, end := 0, len(list)
+			if step.start != nil {
+				start = *step.start
+			}
+			if step.end != nil {
+				end = *step.end
+			}
+			if start < 0 {
+				start += len(list)
+			}
+			if end < 0 {
+				end += len(list)
+			}
+			start, end = min(max(start, 0), len(list)), min(max(end, 0), len(list))
+			if start >= end {
+				return nil
+			}
+			if step.stride <= 1 {
+				return list[start:end]
+			}
+			var out []any
+			for i := start; i < end; i += step.stride {
+				out = append(out, list[i])
+			}
+			return out
+		}
+	case "filter":
+		var out []any
+		for _, child := range jsonPathChildren(v) {
+			if step.filter.matches(child) {
+				out = append(out, child)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// jsonPathChildren returns the elements of a list or the values of an object, ordered by key
+func jsonPathChildren(v any) []any {
+	switch v := v.(type) {
+	case []any:
+		return v
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]any, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, v[k])
+		}
+		return out
+	}
+	return nil
+}
+
+func (f *jsonPathFilter) matches(v any) bool {
+	results := evalJSONPath(f.path, v)
+	if f.op == "" {
+		return len(results) > 0 && results[0] != nil && results[0] != false
+	}
+	for _, r := range results {
+		if compareJSONPath(r, f.op, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareJSONPath compares a value with a filter literal of the same type
+func compareJSONPath(v any, op string, literal any) bool {
+	switch lit := literal.(type) {
+	case string:
+		s, ok := v.(string)
+		if !ok {
+			return op == "!="
+		}
+		return compareOrdered(strings.Compare(s, lit), op)
+	case bool:
+		b, ok := v.(bool)
+		if !ok {
+			return op == "!="
+		}
+		if op == "==" || op == "!=" {
+			return (b == lit) == (op == "==")
+		}
+	case float64:
+		var n float64
+		switch num := v.(type) {
+		case json.Number:
+			f, err := num.Float64()
+			if err != nil {
+				return op == "!="
+			}
+			n = f
+		case float64:
+			n = num
+		default:
+			return op == "!="
+		}
+		switch {
+		case n < lit:
+			return compareOrdered(-1, op)
+		case n > lit:
+			return compareOrdered(1, op)
+		}
+		return compareOrdered(0, op)
+	}
+	return false
+}
+
+func compareOrdered(c int, op string) bool {
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const jsonPathInput = `{
+  "kind": "List",
+  "flag": true,
+  "nums": [0, 1, 2, 3, 4, 5],
+  "items": [
+    {"kind": "None", "metadata": {"name": "127.0.0.1"}, "status": {"capacity": {"cpu": "4"}}},
+    {"kind": "None", "metadata": {"name": "127.0.0.2"}, "status": {"capacity": {"cpu": "8"}}}
+  ],
+  "users": [
+    {"name": "myself", "user": {}},
+    {"name": "e2e", "user": {"username": "admin", "password": "secret"}}
+  ]
+}`
+
+func TestExecuteJSONPath(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(jsonPathInput))
+	dec.UseNumber()
+	var data any
+	if err := dec.Decode(&data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{.kind}", "List"},
+		{"{$.kind}", "List"},
+		{"{['kind']}", "List"},
+		{"kind: {.kind}", "kind: List"},
+		{"{.flag}", "true"},
+		{"{.nums}", "[0,1,2,3,4,5]"},
+		{"{.missing}", ""},
+		{"{.items[*].metadata.name}", "127.0.0.1 127.0.0.2"},
+		{"{.items[*]['metadata']['name']}", "127.0.0.1 127.0.0.2"},
+		{"{.items[0].metadata.name}", "127.0.0.1"},
+		{"{.items[-1].metadata.name}", "127.0.0.2"},
+		{"{.items[5].metadata.name}", ""},
+		{"{items[1].status.capacity.cpu}", "8"},
+		{"{.items[0].metadata}", `{"name":"127.0.0.1"}`},
+		{"{.items[0].metadata.*}", "127.0.0.1"},
+		{"{..name}", "127.0.0.1 127.0.0.2 myself e2e"},
+		{"{.nums[1:3]}", "1 2"},
+		{"{.nums[:2]}", "0 1"},
+		{"{.nums[-2:]}", "4 5"},
+		{"{.nums[4:100]}", "4 5"},
+		{"{.nums[3:1]}", ""},
+		{"{.nums[::2]}", "0 2 4"},
+		{"{.nums[1:5:3]}", "1 4"},
+		{"{.nums[?(@>3)]}", "4 5"},
+		{"{.nums[?(@<=1)]}", "0 1"},
+		{`{.users[?(@.name=="e2e")].user.password}`, "secret"},
+		{`{.users[?(@.name!='e2e')].name}`, "myself"},
+		{`{.items[?(@.status.capacity.cpu=="8")].metadata.name}`, "127.0.0.2"},
+		{"{.users[?(@.user.password)].name}", "e2e"},
+		{`{range .items[*]}[{.metadata.name}, {.status.capacity.cpu}] {end}`, "[127.0.0.1, 4] [127.0.0.2, 8] "},
+		{`{range .items[*]}{.metadata.name}{"\t"}{end}`, "127.0.0.1\t127.0.0.2\t"},
+		{`{range .users[*]}{.name}{'\n'}{end}`, `myself\ne2e\n`},
+		{`{range .items[*]}{range .metadata.*}<{@}>{end}{end}`, "<127.0.0.1><127.0.0.2>"},
+		{`{range .nums}{@},{end}`, "0,1,2,3,4,5,"},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		if err := executeJSONPath(&out, tt.template, data); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.template, err)
+			continue
+		}
+		if out.String() != tt.want {
+			t.Errorf("%s = %q, want %q", tt.template, out.String(), tt.want)
+		}
+	}
+}
+
+func TestExecuteJSONPathErrors(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{.kind", "unclosed {"},
+		{`{"text}`, "unclosed {"},
+		{"{range .items[*]}{.kind}", "{range} without {end}"},
+		{"{.kind}{end}", "{end} without {range}"},
+		{"{.nums[::0]}", "slice step must be positive"},
+		{"{.nums[::-1]}", "slice step must be positive"},
+		{"{.nums[1:2:3:4]}", "invalid slice"},
+		{"{.nums[a:b]}", "invalid slice"},
+		{"{.nums[x]}", "invalid index"},
+		{"{.nums[0}", "unclosed ["},
+		{"{.items[?(.kind=='None')]}", "filter must start with @"},
+		{"{.items[?(@.count>x)]}", "invalid filter value"},
+		{"{..}", "expected a field name after .."},
+		{"{.a b}", "unexpected"},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		err := executeJSONPath(&out, tt.template, map[string]any{})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.template, err, tt.want)
+		}
+	}
+}
@@ -1,13 +1,16 @@
 package utils
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"reflect"
+	"slices"
 	"strings"
 	"text/tabwriter"
+	gotemplate "text/template"
 
 	"github.com/vinisman/bbctl/internal/models"
 	"golang.org/x/text/cases"
@@ -25,78 +28,102 @@ func HasOption(options string, target string) bool {
 	return false
 }
 
-// PrintRepos prints a slice of Repository according to selected columns
-func PrintRepos(repos []models.ExtendedRepository, columns []string) {
+// PrintRepos prints a slice of Repository according to selected columns as a plain, csv or tsv table
+func PrintRepos(repos []models.ExtendedRepository, columns []string, format string) error {
 	if len(columns) == 0 {
 		columns = []string{"Name", "Project"} // default
 	}
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-
-	// Header
-	for i, col := range columns {
-		if i > 0 {
-			fmt.Fprint(w, "\t")
-		}
-		fmt.Fprint(w, col)
-	}
-	fmt.Fprintln(w)
 
+	var rows [][]string
 	for _, r := range repos {
+		row := make([]string, len(columns))
 		for i, col := range columns {
-			if i > 0 {
-				fmt.Fprint(w, "\t")
-			}
 			switch strings.ToLower(col) {
 			case "slug":
 				if r.RestRepository.Slug != nil {
-					fmt.Fprint(w, *r.RestRepository.Slug)
+					row[i] = *r.RestRepository.Slug
 				}
 			case "name":
 				if r.RestRepository.Name != nil {
-					fmt.Fprint(w, *r.RestRepository.Name)
+					row[i] = *r.RestRepository.Name
 				}
 			case "id":
 				if r.RestRepository.Id != nil {
-					fmt.Fprint(w, *r.RestRepository.Id)
+					row[i] = fmt.Sprint(*r.RestRepository.Id)
 				}
 			case "scmid":
 				if r.RestRepository.ScmId != nil {
-					fmt.Fprint(w, *r.RestRepository.ScmId)
+					row[i] = *r.RestRepository.ScmId
 				}
 			case "state":
 				if r.RestRepository.State != nil {
-					fmt.Fprint(w, *r.RestRepository.State)
+					row[i] = *r.RestRepository.State
 				}
 			case "forkable":
 				if r.RestRepository.Forkable != nil {
-					fmt.Fprint(w, *r.RestRepository.Forkable)
+					row[i] = fmt.Sprint(*r.RestRepository.Forkable)
 				}
 			case "hierarchical":
 				if r.RestRepository.HierarchyId != nil {
-					fmt.Fprint(w, *r.RestRepository.HierarchyId)
+					row[i] = *r.RestRepository.HierarchyId
 				}
 			case "project":
 				if r.RestRepository.Project != nil && r.RestRepository.Project.Name != nil {
-					fmt.Fprint(w, *r.RestRepository.Project.Name)
+					row[i] = *r.RestRepository.Project.Name
 				}
 			case "defaultbranch":
 				if r.RestRepository.DefaultBranch != nil {
-					fmt.Fprint(w, *r.RestRepository.DefaultBranch)
+					row[i] = *r.RestRepository.DefaultBranch
 				}
-			default:
-				fmt.Fprint(w, "")
 			}
 		}
-		fmt.Fprintln(w)
+		rows = append(rows, row)
 	}
 
-	if err := w.Flush(); err != nil {
-		log.Printf("Warning: failed to flush writer: %v", err)
+	return writeTable(format, columns, rows)
+}
+
+// IsTable reports whether the output format prints a table of columns: plain, csv or tsv
+func IsTable(format string) bool {
+	switch strings.ToLower(format) {
+	case "plain", "csv", "tsv":
+		return true
+	}
+	return false
+}
+
+// writeTable writes a header and rows as an aligned plain table, or as CSV or TSV
+// with the quoting of RFC 4180
+func writeTable(format string, header []string, rows [][]string) error {
+	switch strings.ToLower(format) {
+	case "csv", "tsv":
+		w := csv.NewWriter(os.Stdout)
+		if strings.ToLower(format) == "tsv" {
+			w.Comma = '\t'
+		}
+		if err := w.Write(header); err != nil {
+			return err
+		}
+		return w.WriteAll(rows)
+
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
 	}
 }
 
-// PrintStructured prints data in JSON, YAML, or plain table format
+// PrintStructured prints data in JSON, YAML, plain, CSV or TSV table format, or with a
+// go-template=, go-template-file= or jsonpath= template applied to the JSON document
 func PrintStructured(name string, data interface{}, format string, columns string) error {
+	// The template keeps its case, only the format name is case-insensitive
+	if kind, template, ok := strings.Cut(format, "="); ok && IsTemplate(format) {
+		return printTemplate(name, data, strings.ToLower(kind), template)
+	}
+
 	switch strings.ToLower(format) {
 	case "json":
 		out := map[string]interface{}{name: data}
@@ -110,20 +137,69 @@ func PrintStructured(name string, data interface{}, format string, columns strin
 		defer enc.Close()
 		return enc.Encode(out)
 
-	case "plain":
-		return printPlain(data, columns)
+	case "plain", "csv", "tsv":
+		return printTable(data, columns, format)
+
+	case "go-template", "go-template-file", "jsonpath":
+		return fmt.Errorf("%s output requires a template, e.g. -o %s=...", format, format)
 
 	default:
 		return fmt.Errorf("unsupported format: %s", format)
 	}
 }
 
-// printPlain renders tabular plain text output for nested structures with arrays
-func printPlain(data interface{}, columns string) error {
+// IsTemplate reports whether the output format applies a template: go-template=,
+// go-template-file= or jsonpath=
+func IsTemplate(format string) bool {
+	kind, _, ok := strings.Cut(format, "=")
+	if !ok {
+		return false
+	}
+	switch strings.ToLower(kind) {
+	case "go-template", "go-template-file", "jsonpath":
+		return true
+	}
+	return false
+}
+
+// printTemplate applies a Go template or a JSONPath template to the document printed
+// by the json format, e.g. {"repositories": [...]}, with the field names of JSON
+func printTemplate(name string, data interface{}, kind, template string) error {
+	payload, err := json.Marshal(map[string]interface{}{name: data})
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return err
+	}
+
+	switch kind {
+	case "jsonpath":
+		return executeJSONPath(os.Stdout, template, doc)
+	case "go-template-file":
+		content, err := os.ReadFile(template)
+		if err != nil {
+			return fmt.Errorf("failed to read template file: %w", err)
+		}
+		template = string(content)
+	}
+	tmpl, err := gotemplate.New(kind).Parse(template)
+	if err != nil {
+		return fmt.Errorf("invalid go template: %w", err)
+	}
+	return tmpl.Execute(os.Stdout, doc)
+}
+
+// printTable renders a plain, csv or tsv table for nested structures with arrays;
+// a column path through an array gives one row per element
+func printTable(data interface{}, columns string, format string) error {
 	val := reflect.ValueOf(data)
 
 	if val.Kind() != reflect.Slice {
-		return fmt.Errorf("plain output requires a slice, got %T", data)
+		return fmt.Errorf("%s output requires a slice, got %T", strings.ToLower(format), data)
 	}
 
 	cols := ParseColumnsToLower(columns)
@@ -131,23 +207,19 @@ func printPlain(data interface{}, columns string) error {
 		return fmt.Errorf("no columns specified")
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	titleCaser := cases.Title(language.English)
 
 	// Parse column structure to understand nesting
 	columnPaths := parseColumnPaths(cols)
 
-	// Header
+	// Header: clean name (last part of path)
+	header := make([]string, len(cols))
 	for i, col := range cols {
-		if i > 0 {
-			fmt.Fprint(w, "\t")
-		}
-		// Display clean header name (last part of path)
 		parts := strings.Split(col, ".")
-		fmt.Fprint(w, titleCaser.String(parts[len(parts)-1]))
+		header[i] = titleCaser.String(parts[len(parts)-1])
 	}
-	fmt.Fprintln(w)
 
+	var lines [][]string
 	// Process each root item
 	for i := 0; i < val.Len(); i++ {
 		rootItem := val.Index(i)
@@ -156,25 +228,23 @@ func printPlain(data interface{}, columns string) error {
 		}
 
 		// Generate all row combinations from nested arrays
-		rows := generateRows(rootItem, columnPaths, []fieldValue{})
+		rows, _, err := generateRows(rootItem, columnPaths, []fieldValue{})
+		if err != nil {
+			return err
+		}
 
-		// Print each row
 		for _, row := range rows {
+			line := make([]string, len(cols))
 			for j, col := range cols {
-				if j > 0 {
-					fmt.Fprint(w, "\t")
-				}
 				if value, exists := row[col]; exists {
-					fmt.Fprint(w, formatValue(value))
-				} else {
-					fmt.Fprint(w, "")
+					line[j] = formatValue(value, format)
 				}
 			}
-			fmt.Fprintln(w)
+			lines = append(lines, line)
 		}
 	}
 
-	return w.Flush()
+	return writeTable(format, header, lines)
 }
 
 // fieldValue represents a value for a specific column path
@@ -186,106 +256,113 @@ type fieldValue struct {
 // rowData represents a single row of data
 type rowData map[string]interface{}
 
-// parseColumnPaths analyzes columns to identify array fields
+// parseColumnPaths splits the columns into the field names of their path
 func parseColumnPaths(columns []string) []columnInfo {
 	var result []columnInfo
 
 	for _, col := range columns {
-		parts := strings.Split(col, ".")
-		info := columnInfo{
+		result = append(result, columnInfo{
 			fullPath: col,
-			parts:    parts,
-		}
-
-		// Identify if this column contains arrays
-		for i, part := range parts {
-			if i < len(parts)-1 {
-				info.arrayFields = append(info.arrayFields, part)
-			}
-		}
-
-		result = append(result, info)
+			parts:    strings.Split(col, "."),
+		})
 	}
 
 	return result
 }
 
 type columnInfo struct {
-	fullPath    string
-	parts       []string
-	arrayFields []string
+	fullPath string
+	parts    []string
 }
 
-// generateRows recursively generates all row combinations from nested arrays
-func generateRows(current reflect.Value, columnPaths []columnInfo, currentPath []fieldValue) []rowData {
+// generateRows recursively generates the rows of an item. A nested object adds its
+// columns to the rows of the current level and an array gives one row per element.
+// Only one nested field of a level may lead to an array: rows of sibling arrays are
+// unrelated, so columns spanning them are rejected. The returned flag reports
+// whether the rows come from an array.
+func generateRows(current reflect.Value, columnPaths []columnInfo, currentPath []fieldValue) ([]rowData, bool, error) {
 	if current.Kind() == reflect.Ptr && !current.IsNil() {
 		current = current.Elem()
 	}
 
-	// Find the next array field to process
-	nextArrayField := findNextArrayField(columnPaths, currentPath)
-	if nextArrayField == "" {
-		// No more arrays to process, get all field values and return single row
-		nonArrayValues := getNonArrayValues(current, columnPaths, currentPath)
-		return []rowData{createRow(nonArrayValues)}
-	}
-
 	// Get all non-array field values at current level
-	nonArrayValues := getNonArrayValues(current, columnPaths, currentPath)
-
-	// Get the array field
-	arrayValue := getFieldValueByPath(current, nextArrayField)
-
-	if arrayValue == nil {
-		return []rowData{createRow(nonArrayValues)}
-	}
-
-	arrayVal := reflect.ValueOf(arrayValue)
-	// Handle pointer to slice
-	if arrayVal.Kind() == reflect.Ptr {
-		if arrayVal.IsNil() {
-			return []rowData{createRow(nonArrayValues)}
-		}
-		arrayVal = arrayVal.Elem()
-	}
-
-	if arrayVal.Kind() != reflect.Slice {
-		return []rowData{createRow(nonArrayValues)}
-	}
-	var rows []rowData
+	rows := []rowData{createRow(getNonArrayValues(current, columnPaths, currentPath))}
+	listField := ""
 
-	// Process each item in the array
-	for i := 0; i < arrayVal.Len(); i++ {
-		arrayItem := arrayVal.Index(i)
-		if arrayItem.Kind() == reflect.Ptr && !arrayItem.IsNil() {
-			arrayItem = arrayItem.Elem()
-		}
-
-		// Create path for current array item
-		arrayPath := append(currentPath, fieldValue{
-			path:  nextArrayField,
+	for _, nestedField := range findNestedFields(columnPaths, currentPath) {
+		nestedPath := append(currentPath[:len(currentPath):len(currentPath)], fieldValue{
+			path:  nestedField,
 			value: nil, // We don't store the array itself, just process its items
 		})
 
-		// Recursively generate rows for nested structures
-		nestedRows := generateRows(arrayItem, columnPaths, arrayPath)
+		var nestedRows []rowData
+		isList := false
+		nestedVal := reflect.ValueOf(getFieldValueByPath(current, nestedField))
+		// Handle pointer to slice or object
+		if nestedVal.Kind() == reflect.Ptr {
+			if nestedVal.IsNil() {
+				kind := nestedVal.Type().Elem().Kind()
+				isList = kind == reflect.Slice || kind == reflect.Array
+			} else {
+				nestedVal = nestedVal.Elem()
+			}
+		}
+		switch nestedVal.Kind() {
+		case reflect.Struct:
+			var err error
+			nestedRows, isList, err = generateRows(nestedVal, columnPaths, nestedPath)
+			if err != nil {
+				return nil, false, err
+			}
+		case reflect.Slice, reflect.Array:
+			isList = true
+			for i := 0; i < nestedVal.Len(); i++ {
+				itemRows, _, err := generateRows(nestedVal.Index(i), columnPaths, nestedPath)
+				if err != nil {
+					return nil, false, err
+				}
+				nestedRows = append(nestedRows, itemRows...)
+			}
+		}
+		if isList {
+			if listField != "" {
+				return nil, false, fmt.Errorf("columns of %s and %s list different arrays, select columns of one of them",
+					columnPath(currentPath, listField), columnPath(currentPath, nestedField))
+			}
+			listField = nestedField
+		}
+		if len(nestedRows) == 0 {
+			// Missing or empty, the columns of the field stay empty
+			continue
+		}
 
-		// Combine with current non-array values
-		for _, nestedRow := range nestedRows {
-			row := createRow(nonArrayValues)
-			for k, v := range nestedRow {
-				row[k] = v
+		// Add the columns of the field to the rows of the current level
+		var combined []rowData
+		for _, row := range rows {
+			for _, nestedRow := range nestedRows {
+				merged := make(rowData, len(row)+len(nestedRow))
+				for k, v := range row {
+					merged[k] = v
+				}
+				for k, v := range nestedRow {
+					merged[k] = v
+				}
+				combined = append(combined, merged)
 			}
-			rows = append(rows, row)
 		}
+		rows = combined
 	}
 
-	if len(rows) == 0 {
-		// If array was empty, return row with empty array values
-		return []rowData{createRow(nonArrayValues)}
-	}
+	return rows, listField != "", nil
+}
 
-	return rows
+// columnPath joins the current path and a field to the column path of the field
+func columnPath(currentPath []fieldValue, field string) string {
+	parts := make([]string, 0, len(currentPath)+1)
+	for _, p := range currentPath {
+		parts = append(parts, p.path)
+	}
+	return strings.Join(append(parts, field), ".")
 }
 
 // getNonArrayValues gets values for columns that don't involve arrays at current level
@@ -308,47 +385,37 @@ func getNonArrayValues(current reflect.Value, columnPaths []columnInfo, currentP
 	return values
 }
 
-// isColumnAtCurrentLevel checks if a column is at the current processing level
+// isColumnAtCurrentLevel checks if a column is a field of the current processing level,
+// columns of nested fields are processed separately
 func isColumnAtCurrentLevel(colInfo columnInfo, currentPath []fieldValue) bool {
-	if len(colInfo.parts) <= len(currentPath) {
-		return false
-	}
-
-	// Check if the path up to current level matches
-	for i, pathItem := range currentPath {
-		if colInfo.parts[i] != pathItem.path {
-			return false
-		}
-	}
-
-	// Check if the next part is not an array field (we process arrays separately)
-	nextPart := colInfo.parts[len(currentPath)]
-	for _, arrayField := range colInfo.arrayFields {
-		if arrayField == nextPart {
-			return false
-		}
-	}
-
-	return true
+	return len(colInfo.parts) == len(currentPath)+1 && hasPrefix(colInfo, currentPath)
 }
 
-// findNextArrayField finds the next array field to process
-func findNextArrayField(columnPaths []columnInfo, currentPath []fieldValue) string {
+// findNestedFields returns the distinct fields below the current path, in column
+// order, that columns descend into
+func findNestedFields(columnPaths []columnInfo, currentPath []fieldValue) []string {
 	currentLevel := len(currentPath)
+	var fields []string
 
 	for _, colInfo := range columnPaths {
-		if len(colInfo.parts) > currentLevel {
-			nextPart := colInfo.parts[currentLevel]
+		if len(colInfo.parts) <= currentLevel+1 || !hasPrefix(colInfo, currentPath) {
+			continue
+		}
+		if nextPart := colInfo.parts[currentLevel]; !slices.Contains(fields, nextPart) {
+			fields = append(fields, nextPart)
+		}
+	}
+	return fields
+}
 
-			// Check if this part is an array field
-			for _, arrayField := range colInfo.arrayFields {
-				if arrayField == nextPart {
-					return nextPart
-				}
-			}
+// hasPrefix reports whether the column lies below the current path
+func hasPrefix(colInfo columnInfo, currentPath []fieldValue) bool {
+	for i, pathItem := range currentPath {
+		if colInfo.parts[i] != pathItem.path {
+			return false
 		}
 	}
-	return ""
+	return true
 }
 
 // createRow creates a row from field values
@@ -442,15 +509,28 @@ func findFieldByName(v reflect.Value, name string) reflect.Value {
 	return reflect.Value{}
 }
 
-// formatValue safely formats any value for display
-func formatValue(value interface{}) string {
+// formatValue safely formats any value for display. In csv and tsv, missing values
+// are empty and objects, and lists of them, are printed as JSON.
+func formatValue(value interface{}, format string) string {
 	if value == nil {
 		return ""
 	}
 
-	// Handle slices and arrays
 	val := reflect.ValueOf(value)
-	if val.Kind() == reflect.Slice || val.Kind() == reflect.Array {
+	isList := val.Kind() == reflect.Slice || val.Kind() == reflect.Array
+	if strings.ToLower(format) != "plain" {
+		if val.Kind() == reflect.Ptr && val.IsNil() {
+			return ""
+		}
+		if isObject(val.Type()) || (isList && isObject(val.Type().Elem())) {
+			if b, err := json.Marshal(value); err == nil {
+				return string(b)
+			}
+		}
+	}
+
+	// Handle slices and arrays
+	if isList {
 		if val.Len() == 0 {
 			return "[]"
 		}
@@ -463,3 +543,11 @@ func formatValue(value interface{}) string {
 
 	return fmt.Sprintf("%v", value)
 }
+
+// isObject reports whether values of the type are structs or maps, also through a pointer
+func isObject(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Map
+}
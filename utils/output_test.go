@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type outputHook struct {
+	Name   string
+	Events []string
+}
+
+type outputBuild struct {
+	Id int
+}
+
+type outputProject struct {
+	Key string
+}
+
+type outputRepo struct {
+	Slug     string
+	Project  *outputProject
+	Webhooks *[]outputHook
+	Builds   []outputBuild
+}
+
+func TestGenerateRows(t *testing.T) {
+	repo := outputRepo{
+		Slug:     "repo",
+		Project:  &outputProject{Key: "PRJ"},
+		Webhooks: &[]outputHook{{Name: "ci"}, {Name: "deploy"}},
+		Builds:   []outputBuild{{Id: 1}},
+	}
+	rowsOf := func(columns string) ([]rowData, error) {
+		rows, _, err := generateRows(reflect.ValueOf(repo), parseColumnPaths(ParseColumnsToLower(columns)), nil)
+		return rows, err
+	}
+
+	rows, err := rowsOf("slug,project.key,webhooks.name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want one per webhook", len(rows))
+	}
+	for i, name := range []string{"ci", "deploy"} {
+		row := rows[i]
+		if row["slug"] != "repo" || row["project.key"] != "PRJ" || row["webhooks.name"] != name {
+			t.Errorf("row %d = %v", i, row)
+		}
+	}
+
+	if _, err := rowsOf("webhooks.name,builds.id"); err == nil || !strings.Contains(err.Error(), "webhooks and builds") {
+		t.Errorf("columns of two arrays: err = %v, want a refusal", err)
+	}
+
+	repo.Webhooks = nil
+	if _, err := rowsOf("webhooks.name,builds.id"); err == nil {
+		t.Error("columns of two arrays with one of them missing were accepted")
+	}
+	rows, err = rowsOf("slug,webhooks.name")
+	if err != nil || len(rows) != 1 || rows[0]["slug"] != "repo" {
+		t.Errorf("rows without webhooks = %v, %v, want the repository alone", rows, err)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	project := &outputProject{Key: "PRJ"}
+	var missing *string
+	tests := []struct {
+		value  interface{}
+		format string
+		want   string
+	}{
+		{"text", "plain", "text"},
+		{[]string{"a", "b"}, "csv", "[a, b]"},
+		{[]string{}, "plain", "[]"},
+		{missing, "csv", ""},
+		{missing, "plain", "<nil>"},
+		{*project, "csv", `{"Key":"PRJ"}`},
+		{[]outputBuild{{Id: 1}}, "tsv", `[{"Id":1}]`},
+		{*project, "plain", "{PRJ}"},
+	}
+	for _, tt := range tests {
+		if got := formatValue(tt.value, tt.format); got != tt.want {
+			t.Errorf("formatValue(%#v, %s) = %q, want %q", tt.value, tt.format, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vinisman/bbctl/internal/config"
+	"github.com/vinisman/bbctl/internal/models"
+)
+
+// Valid permission names for projects and repositories
+var (
+	ProjectPermissions = []string{"PROJECT_READ", "PROJECT_WRITE", "PROJECT_ADMIN"}
+	RepoPermissions    = []string{"REPO_READ", "REPO_WRITE", "REPO_ADMIN"}
+)
+
+// NormalizePermissions upper-cases permission names and checks them against allowed.
+// When requirePermission is false, entries without permission are accepted (e.g. for revoke).
+func NormalizePermissions(p *models.Permissions, allowed []string, requirePermission bool) error {
+	if p == nil {
+		return nil
+	}
+	check := func(kind string, entries []models.PermissionEntry) error {
+		for i := range entries {
+			if entries[i].Name == "" {
+				return fmt.Errorf("%s permission at index %d is missing required field 'name'", kind, i)
+			}
+			perm := strings.ToUpper(strings.TrimSpace(entries[i].Permission))
+			if perm == "" && !requirePermission {
+				continue
+			}
+			valid := false
+			for _, a := range allowed {
+				if perm == a {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				return fmt.Errorf("invalid permission %q for %s %s, allowed values: %s", entries[i].Permission, kind, entries[i].Name, strings.Join(allowed, ", "))
+			}
+			entries[i].Permission = perm
+		}
+		return nil
+	}
+	if err := check("user", p.Users); err != nil {
+		return err
+	}
+	return check("group", p.Groups)
+}
+
+// PermissionTargets lists the users and groups of p on resource, a project key or
+// <projectKey>/<slug>, as "<resource> user:<name>" targets for ConfirmDelete
+func PermissionTargets(resource string, p *models.Permissions) []string {
+	if p == nil {
+		return nil
+	}
+	var targets []string
+	for _, u := range p.Users {
+		targets = append(targets, resource+" "+config.KindUser+":"+u.Name)
+	}
+	for _, g := range p.Groups {
+		targets = append(targets, resource+" "+config.KindGroup+":"+g.Name)
+	}
+	return targets
+}
+
+// DiffPermissions compares current and desired permissions. Names are compared
+// case-insensitively. grant holds entries to set (new or changed), revoke holds
+// current entries missing from desired. A nil desired list (users or groups not
+// given at all) is not managed and leaves the current entries untouched.
+func DiffPermissions(current, desired models.Permissions) (grant, revoke models.Permissions, changes []models.PermissionChange) {
+	changes = []models.PermissionChange{}
+	diff := func(kind string, cur, want []models.PermissionEntry) (g, r []models.PermissionEntry) {
+		if want == nil {
+			return nil, nil
+		}
+		curByName := make(map[string]models.PermissionEntry, len(cur))
+		for _, e := range cur {
+			curByName[strings.ToLower(e.Name)] = e
+		}
+		wanted := make(map[string]bool, len(want))
+		for _, e := range want {
+			k := strings.ToLower(e.Name)
+			wanted[k] = true
+			old, ok := curByName[k]
+			if ok && old.Permission == e.Permission {
+				continue
+			}
+			g = append(g, e)
+			changes = append(changes, models.PermissionChange{Type: kind, Name: e.Name, From: old.Permission, To: e.Permission})
+		}
+		for _, e := range cur {
+			if !wanted[strings.ToLower(e.Name)] {
+				r = append(r, e)
+				changes = append(changes, models.PermissionChange{Type: kind, Name: e.Name, From: e.Permission})
+			}
+		}
+		return g, r
+	}
+	grant.Users, revoke.Users = diff("user", current.Users, desired.Users)
+	grant.Groups, revoke.Groups = diff("group", current.Groups, desired.Groups)
+	return grant, revoke, changes
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/vinisman/bbctl/internal/models"
+)
+
+// PlanFileVersion is the current version of the saved plan file format
+const PlanFileVersion = 1
+
+// PlanChecksum returns a sha256 over the canonical JSON encoding of the plan file
+// with its Checksum field cleared
+func PlanChecksum(pf models.PlanFile) (string, error) {
+	pf.Checksum = ""
+	data, err := json.Marshal(pf)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// WritePlanFile sets the version and checksum and writes the plan file.
+// Plan files are always written as JSON: YAML does not round-trip the SDK
+// types exactly (empty maps, lower-cased keys), which would break the checksum.
+func WritePlanFile(path string, pf *models.PlanFile) error {
+	pf.Version = PlanFileVersion
+	sum, err := PlanChecksum(*pf)
+	if err != nil {
+		return err
+	}
+	pf.Checksum = sum
+
+	data, err := json.MarshalIndent(pf, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// ReadPlanFile reads a JSON plan file and verifies its version and checksum
+func ReadPlanFile(path string) (*models.PlanFile, error) {
+	cleanPath := filepath.Clean(path)
+	raw, err := os.ReadFile(cleanPath)
+	if err != nil {
+		return nil, err
+	}
+	var pf models.PlanFile
+	if err := json.Unmarshal(raw, &pf); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file %s: %w", path, err)
+	}
+	if pf.Version != PlanFileVersion {
+		return nil, fmt.Errorf("unsupported plan file version %d (expected %d)", pf.Version, PlanFileVersion)
+	}
+	sum, err := PlanChecksum(pf)
+	if err != nil {
+		return nil, err
+	}
+	if sum != pf.Checksum {
+		return nil, fmt.Errorf("plan file checksum mismatch: the file was modified after it was created")
+	}
+	return &pf, nil
+}
@@ -199,6 +199,64 @@ func ForceUpdateBySourceIDs[T any, ID comparable](source, target []models.Extend
 	return forced
 }
 
+// MatchRepoItemIDs copies ids from source items into target items that have no id
+// but share the same natural key (e.g. webhook name), so hand-written desired-state
+// files do not need to carry server-generated ids. Each source item is matched at most once.
+// Target repositories are copied; the input slice is not modified.
+func MatchRepoItemIDs[T any, ID comparable](source, target []models.ExtendedRepository, ops RepoItemOps[T, ID], key func(T) string, setID func(*T, ID)) []models.ExtendedRepository {
+	// index source ids by repo and natural key
+	srcIdx := map[string]map[string][]ID{}
+	for _, r := range source {
+		m := map[string][]ID{}
+		for _, it := range ops.GetItems(r) {
+			if id, ok := ops.GetID(it); ok {
+				k := key(it)
+				m[k] = append(m[k], id)
+			}
+		}
+		srcIdx[r.ProjectKey+"/"+r.RepositorySlug] = m
+	}
+
+	out := make([]models.ExtendedRepository, len(target))
+	for i, r := range target {
+		out[i] = r
+		items := ops.GetItems(r)
+		if items == nil {
+			continue
+		}
+		ids := srcIdx[r.ProjectKey+"/"+r.RepositorySlug]
+
+		// ids already referenced explicitly in target must not be assigned again
+		used := map[ID]struct{}{}
+		for _, it := range items {
+			if id, ok := ops.GetID(it); ok {
+				used[id] = struct{}{}
+			}
+		}
+
+		matched := make([]T, len(items))
+		copy(matched, items)
+		for j := range matched {
+			if _, ok := ops.GetID(matched[j]); ok {
+				continue
+			}
+			k := key(matched[j])
+			for len(ids[k]) > 0 {
+				id := ids[k][0]
+				ids[k] = ids[k][1:]
+				if _, taken := used[id]; taken {
+					continue
+				}
+				used[id] = struct{}{}
+				setID(&matched[j], id)
+				break
+			}
+		}
+		ops.SetItems(&out[i], matched)
+	}
+	return out
+}
+
 // BuildRollbackPlan constructs a rollback plan based on source state and diff result
 func BuildRollbackPlan[T any, ID comparable](source []models.ExtendedRepository, diff models.RepoDiff, updated, created []models.ExtendedRepository, ops RepoItemOps[T, ID]) *models.RollbackPlan {
 	// index source by repo and id
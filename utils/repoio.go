@@ -14,7 +14,7 @@ import (
 
 // WriteRepositoriesToFile writes repositories grouped by projectKey/repositorySlug to a file.
 // The payload is wrapped under key "repositories". Format is controlled by the `format` argument (json|yaml).
-// For each repository, both RequiredBuilds and Webhooks are merged across duplicates and de-duplicated by id.
+// For each repository, RequiredBuilds, Webhooks, BranchPermissions and ReviewerGroups are merged across duplicates and de-duplicated by id.
 func WriteRepositoriesToFile(path string, repos []models.ExtendedRepository, format string) error {
 	grouped := GroupRepositories(repos)
 	wrapper := map[string]interface{}{"repositories": grouped}
@@ -32,7 +32,7 @@ func WriteRepositoriesToFile(path string, repos []models.ExtendedRepository, for
 	return os.WriteFile(path, data, 0600)
 }
 
-// GroupRepositories merges items for the same repo and deduplicates both required-builds and webhooks by id.
+// GroupRepositories merges items for the same repo and deduplicates required-builds, webhooks, branch permissions and reviewer groups by id.
 func GroupRepositories(repos []models.ExtendedRepository) []models.ExtendedRepository {
 	repoMap := make(map[string]*models.ExtendedRepository)
 	order := make([]string, 0)
@@ -51,6 +51,14 @@ func GroupRepositories(repos []models.ExtendedRepository) []models.ExtendedRepos
 				emptyWH := []openapi.RestWebhook{}
 				copy.Webhooks = &emptyWH
 			}
+			if r.BranchPermissions != nil {
+				emptyBP := []openapi.RestRefRestriction{}
+				copy.BranchPermissions = &emptyBP
+			}
+			if r.ReviewerGroups != nil {
+				emptyRG := []openapi.RestReviewerGroup{}
+				copy.ReviewerGroups = &emptyRG
+			}
 			repoMap[key] = &copy
 			order = append(order, key)
 			entry = &copy
@@ -71,6 +79,22 @@ func GroupRepositories(repos []models.ExtendedRepository) []models.ExtendedRepos
 			}
 			*entry.Webhooks = append(*entry.Webhooks, (*r.Webhooks)...)
 		}
+		// merge branch permissions
+		if r.BranchPermissions != nil && len(*r.BranchPermissions) > 0 {
+			if entry.BranchPermissions == nil {
+				empty := []openapi.RestRefRestriction{}
+				entry.BranchPermissions = &empty
+			}
+			*entry.BranchPermissions = append(*entry.BranchPermissions, (*r.BranchPermissions)...)
+		}
+		// merge reviewer groups
+		if r.ReviewerGroups != nil && len(*r.ReviewerGroups) > 0 {
+			if entry.ReviewerGroups == nil {
+				empty := []openapi.RestReviewerGroup{}
+				entry.ReviewerGroups = &empty
+			}
+			*entry.ReviewerGroups = append(*entry.ReviewerGroups, (*r.ReviewerGroups)...)
+		}
 	}
 
 	// Deduplicate per repo
@@ -106,6 +130,36 @@ func GroupRepositories(repos []models.ExtendedRepository) []models.ExtendedRepos
 			}
 			*entry.Webhooks = dedup
 		}
+		// dedup branch permissions by id (int32)
+		if entry.BranchPermissions != nil && len(*entry.BranchPermissions) > 0 {
+			seen := make(map[int32]bool)
+			dedup := make([]openapi.RestRefRestriction, 0, len(*entry.BranchPermissions))
+			for _, bp := range *entry.BranchPermissions {
+				if bp.Id != nil {
+					if seen[*bp.Id] {
+						continue
+					}
+					seen[*bp.Id] = true
+				}
+				dedup = append(dedup, bp)
+			}
+			*entry.BranchPermissions = dedup
+		}
+		// dedup reviewer groups by id (int64)
+		if entry.ReviewerGroups != nil && len(*entry.ReviewerGroups) > 0 {
+			seen := make(map[int64]bool)
+			dedup := make([]openapi.RestReviewerGroup, 0, len(*entry.ReviewerGroups))
+			for _, rg := range *entry.ReviewerGroups {
+				if rg.Id != nil {
+					if seen[*rg.Id] {
+						continue
+					}
+					seen[*rg.Id] = true
+				}
+				dedup = append(dedup, rg)
+			}
+			*entry.ReviewerGroups = dedup
+		}
 	}
 
 	out := make([]models.ExtendedRepository, 0, len(order))
@@ -119,6 +173,8 @@ func GroupRepositories(repos []models.ExtendedRepository) []models.ExtendedRepos
 // repositorySlug, and with inner collections sorted by id:
 // - requiredBuilds[].id (int64) ascending, nil first
 // - webhooks[].id (int32) ascending, nil first
+// - branchPermissions[].id (int32) ascending, nil first
+// - reviewerGroups[].id (int64) ascending, nil first
 func SortRepositoriesStable(repos []models.ExtendedRepository) []models.ExtendedRepository {
 	// deep-ish copy
 	out := make([]models.ExtendedRepository, 0, len(repos))
@@ -129,6 +185,8 @@ func SortRepositoriesStable(repos []models.ExtendedRepository) []models.Extended
 			DefaultBranch:  r.DefaultBranch,
 			RestRepository: r.RestRepository,
 			Manifest:       r.Manifest,
+			Workzone:       r.Workzone,
+			Permissions:    r.Permissions,
 		}
 		if r.RequiredBuilds != nil {
 			dup := make([]openapi.RestRequiredBuildCondition, len(*r.RequiredBuilds))
@@ -170,6 +228,46 @@ func SortRepositoriesStable(repos []models.ExtendedRepository) []models.Extended
 			})
 			nr.Webhooks = &dup
 		}
+		if r.BranchPermissions != nil {
+			dup := make([]openapi.RestRefRestriction, len(*r.BranchPermissions))
+			copy(dup, *r.BranchPermissions)
+			// sort by id (nil first)
+			sort.Slice(dup, func(i, j int) bool {
+				var li, lj int64
+				if dup[i].Id != nil {
+					li = int64(*dup[i].Id)
+				} else {
+					li = -1
+				}
+				if dup[j].Id != nil {
+					lj = int64(*dup[j].Id)
+				} else {
+					lj = -1
+				}
+				return li < lj
+			})
+			nr.BranchPermissions = &dup
+		}
+		if r.ReviewerGroups != nil {
+			dup := make([]openapi.RestReviewerGroup, len(*r.ReviewerGroups))
+			copy(dup, *r.ReviewerGroups)
+			// sort by id (nil first)
+			sort.Slice(dup, func(i, j int) bool {
+				var li, lj int64
+				if dup[i].Id != nil {
+					li = *dup[i].Id
+				} else {
+					li = -1
+				}
+				if dup[j].Id != nil {
+					lj = *dup[j].Id
+				} else {
+					lj = -1
+				}
+				return li < lj
+			})
+			nr.ReviewerGroups = &dup
+		}
 		out = append(out, nr)
 	}
 	sort.Slice(out, func(i, j int) bool {